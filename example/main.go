@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"go-redis-demo/redis"
+	"go-redis-demo/redis/geo"
 
 	redisv9 "github.com/redis/go-redis/v9"
 )
@@ -230,7 +231,7 @@ func geoExample(ctx context.Context) {
 	}
 
 	//2.计算距离
-	distance, err := redis.Client.Geo.GeoDist(ctx, "cities", "北京", "上海", "km")
+	distance, err := redis.Client.Geo.GeoDist(ctx, "cities", "北京", "上海", geo.KM)
 	if err != nil {
 		log.Printf("计算距离失败: %v", err)
 		return
@@ -238,7 +239,8 @@ func geoExample(ctx context.Context) {
 	fmt.Printf("北京到上海的距离: %.2f 公里\n", distance)
 
 	//3.半径搜索
-	locations, err := redis.Client.Geo.GeoRadius(ctx, "cities", 116.397128, 39.916527, 1500, "km", true, true, true, 10)
+	locations, err := redis.Client.Geo.Query("cities").FromLonLat(116.397128, 39.916527).ByRadius(1500, geo.KM).
+		Asc().Count(10).WithCoord().WithDist().Locations(ctx)
 	if err != nil {
 		log.Printf("半径搜索失败: %v", err)
 		return
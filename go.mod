@@ -3,7 +3,13 @@ module go-redis-demo
 go 1.24
 
 require (
-	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/RoaringBitmap/roaring v1.9.4
+	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/redis/go-redis/v9 v9.11.0
+)
+
+require (
+	github.com/bits-and-blooms/bitset v1.12.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/redis/go-redis/v9 v9.11.0 // indirect
+	github.com/mschoch/smat v0.2.0 // indirect
 )
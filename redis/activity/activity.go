@@ -0,0 +1,278 @@
+// Package activity 基于位图提供用户活跃（签到）记录、连续签到与活跃用户统计
+// @Author:冯铁城 [17615007230@163.com] 2025-09-01 10:00:00
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 跟踪器由以下key组成：
+//   - {tracker}:user:{用户}:{yyyyMM}  位图，用户当月的签到记录，第d天为偏移d-1
+//   - {tracker}:day:{yyyyMMdd}        位图，当天活跃的用户，偏移为用户ID
+//   - {tracker}:new:{yyyyMMdd}        位图，当天首次活跃的用户，偏移为用户ID
+//   - {tracker}:seen                  位图，曾经活跃过的用户，不过期
+//   - {tracker}:tmp                   统计时BITOP的临时结果，在同一个事务中删除
+// 用户月度位图在当月结束UserTTL后过期，日位图在当天结束DayTTL后过期
+
+// ErrInvalidUser 用户ID超出位图偏移的范围
+var ErrInvalidUser = errors.New("activity: 用户ID必须在[0, 2^32)之间")
+
+// maxUser 用户ID上限，即Redis字符串的512MB上限
+const maxUser = 1 << 32
+
+// markScript 记录用户在某天活跃，返回用户当天此前是否已活跃
+// KEYS[1]=用户月度位图 KEYS[2]=日活位图 KEYS[3]=曾活跃位图 KEYS[4]=日新增位图
+// ARGV[1]=用户ID ARGV[2]=日期在月内的偏移 ARGV[3]=用户月度位图过期时间(毫秒时间戳) ARGV[4]=日位图过期时间(毫秒时间戳)
+var markScript = redis.NewScript(`
+local old = redis.call("SETBIT", KEYS[1], ARGV[2], 1)
+redis.call("PEXPIREAT", KEYS[1], ARGV[3])
+redis.call("SETBIT", KEYS[2], ARGV[1], 1)
+redis.call("PEXPIREAT", KEYS[2], ARGV[4])
+if redis.call("SETBIT", KEYS[3], ARGV[1], 1) == 0 then
+	redis.call("SETBIT", KEYS[4], ARGV[1], 1)
+	redis.call("PEXPIREAT", KEYS[4], ARGV[4])
+end
+return old
+`)
+
+// Options 定义了活跃跟踪器的配置选项
+type Options struct {
+	Location *time.Location // 划分日期的时区，为nil时使用time.Local
+	UserTTL  time.Duration  // 用户月度位图在当月结束后保留的时长，决定了连续签到最多能回溯多久
+	DayTTL   time.Duration  // 日活与日新增位图在当天结束后保留的时长，决定了活跃统计与留存最多能回溯多久
+}
+
+// DefaultOptions 返回一个包含推荐默认值的活跃跟踪器配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		Location: time.Local,           // 默认使用本地时区
+		UserTTL:  366 * 24 * time.Hour, // 默认保留一年的签到记录
+		DayTTL:   90 * 24 * time.Hour,  // 默认保留90天的日活位图
+	}
+}
+
+// Client 活跃跟踪客户端
+type Client struct {
+	rdb *redis.Client
+}
+
+// New 创建活跃跟踪客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb}
+}
+
+// Tracker 用户活跃跟踪器
+type Tracker struct {
+	c    *Client
+	name string
+	opts *Options
+}
+
+// Tracker 获取名为name的活跃跟踪器，opts为nil时使用默认配置
+func (c *Client) Tracker(name string, opts *Options) *Tracker {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Location == nil {
+		o := *opts
+		o.Location = time.Local
+		opts = &o
+	}
+	return &Tracker{c: c, name: name, opts: opts}
+}
+
+// Mark 记录用户在date当天活跃（签到）
+// 参数:
+//   - ctx: 上下文
+//   - user: 用户ID，作为日活位图的偏移
+//   - date: 日期，按配置的时区取当天
+//
+// 返回:
+//   - 是否为当天首次签到
+//   - 错误信息，用户ID超出范围时返回ErrInvalidUser
+func (t *Tracker) Mark(ctx context.Context, user int64, date time.Time) (bool, error) {
+	if err := validateUser(user); err != nil {
+		return false, err
+	}
+	day := t.day(date)
+	keys := []string{t.userKey(user, day), t.dayKey("day", day), t.key("seen"), t.dayKey("new", day)}
+	monthEnd := monthStart(day).AddDate(0, 1, 0)
+	args := []interface{}{
+		user,
+		day.Day() - 1,
+		monthEnd.Add(t.opts.UserTTL).UnixMilli(),
+		day.AddDate(0, 0, 1).Add(t.opts.DayTTL).UnixMilli(),
+	}
+	old, err := markScript.Run(ctx, t.c.rdb, keys, args...).Int64()
+	if err != nil {
+		return false, err
+	}
+	return old == 0, nil
+}
+
+// IsActive 判断用户在date当天是否活跃
+func (t *Tracker) IsActive(ctx context.Context, user int64, date time.Time) (bool, error) {
+	day := t.day(date)
+	bit, err := t.c.rdb.GetBit(ctx, t.userKey(user, day), int64(day.Day()-1)).Result()
+	return bit == 1, err
+}
+
+// ActiveDays 返回用户在month所在月份的活跃天数
+func (t *Tracker) ActiveDays(ctx context.Context, user int64, month time.Time) (int64, error) {
+	return t.c.rdb.BitCount(ctx, t.userKey(user, t.day(month)), nil).Result()
+}
+
+// ActiveDaysBetween 返回用户在[from, to]之间的活跃天数，按月份用BIT范围的BITCOUNT在一个管道中统计
+func (t *Tracker) ActiveDaysBetween(ctx context.Context, user int64, from, to time.Time) (int64, error) {
+	from, to = t.day(from), t.day(to)
+	if from.After(to) {
+		return 0, nil
+	}
+
+	//1.每个月统计from与to截取后的范围
+	pipe := t.c.rdb.Pipeline()
+	var cmds []*redis.IntCmd
+	for m := monthStart(from); !m.After(to); m = m.AddDate(0, 1, 0) {
+		start, end := int64(0), int64(daysIn(m)-1)
+		if m.Equal(monthStart(from)) {
+			start = int64(from.Day() - 1)
+		}
+		if m.Equal(monthStart(to)) {
+			end = int64(to.Day() - 1)
+		}
+		cmds = append(cmds, pipe.BitCount(ctx, t.userKey(user, m), &redis.BitCount{Start: start, End: end, Unit: redis.BitCountIndexBit}))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	//2.累加
+	var total int64
+	for _, cmd := range cmds {
+		total += cmd.Val()
+	}
+	return total, nil
+}
+
+// CurrentStreak 返回用户截至date的连续签到天数
+// date当天未签到时从前一天开始计算，即当天还有机会续上；逐月用BITFIELD读取签到记录，遇到未签到的一天为止
+func (t *Tracker) CurrentStreak(ctx context.Context, user int64, date time.Time) (int, error) {
+	day := t.day(date)
+	streak, first := 0, true
+	for m := monthStart(day); ; m = m.AddDate(0, -1, 0) {
+
+		//1.读取当月签到记录
+		bits, err := t.monthBits(ctx, user, m)
+		if err != nil {
+			return 0, err
+		}
+
+		//2.从date（或月末）向前数连续签到的天数
+		d := daysIn(m)
+		if first {
+			d = day.Day()
+			if bits&dayMask(d) == 0 {
+				d--
+			}
+			first = false
+		}
+		for ; d >= 1; d-- {
+			if bits&dayMask(d) == 0 {
+				return streak, nil
+			}
+			streak++
+		}
+	}
+}
+
+// LongestStreak 返回用户在[from, to]之间最长的连续签到天数，在一个管道中用BITFIELD读取各月的签到记录
+func (t *Tracker) LongestStreak(ctx context.Context, user int64, from, to time.Time) (int, error) {
+	from, to = t.day(from), t.day(to)
+	if from.After(to) {
+		return 0, nil
+	}
+
+	//1.读取各月签到记录
+	pipe := t.c.rdb.Pipeline()
+	var cmds []*redis.IntSliceCmd
+	for m := monthStart(from); !m.After(to); m = m.AddDate(0, 1, 0) {
+		cmds = append(cmds, pipe.BitField(ctx, t.userKey(user, m), "GET", "u32", 0))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	//2.逐日计算最长连续天数
+	longest, streak, i := 0, 0, 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Day() == 1 && !d.Equal(from) {
+			i++
+		}
+		if uint32(cmds[i].Val()[0])&dayMask(d.Day()) == 0 {
+			streak = 0
+			continue
+		}
+		if streak++; streak > longest {
+			longest = streak
+		}
+	}
+	return longest, nil
+}
+
+// monthBits 用BITFIELD读取用户一个月的签到记录，第d天为从高位起的第d位
+func (t *Tracker) monthBits(ctx context.Context, user int64, month time.Time) (uint32, error) {
+	res, err := t.c.rdb.BitField(ctx, t.userKey(user, month), "GET", "u32", 0).Result()
+	if err != nil {
+		return 0, err
+	}
+	return uint32(res[0]), nil
+}
+
+// dayMask 返回第d天在月度签到记录中的掩码
+func dayMask(d int) uint32 {
+	return 1 << (32 - d)
+}
+
+// day 按配置的时区返回date当天的零点
+func (t *Tracker) day(date time.Time) time.Time {
+	date = date.In(t.opts.Location)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, t.opts.Location)
+}
+
+// monthStart 返回当月1日的零点
+func monthStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1-day.Day())
+}
+
+// daysIn 返回当月的天数
+func daysIn(month time.Time) int {
+	return monthStart(month).AddDate(0, 1, -1).Day()
+}
+
+// validateUser 验证用户ID
+func validateUser(user int64) error {
+	if user < 0 || user >= maxUser {
+		return fmt.Errorf("%w: %d", ErrInvalidUser, user)
+	}
+	return nil
+}
+
+// key 返回跟踪器下的key
+func (t *Tracker) key(suffix string) string {
+	return t.name + ":" + suffix
+}
+
+// userKey 返回用户月度位图的key
+func (t *Tracker) userKey(user int64, day time.Time) string {
+	return fmt.Sprintf("%s:user:%d:%s", t.name, user, day.Format("200601"))
+}
+
+// dayKey 返回kind（day或new）日位图的key
+func (t *Tracker) dayKey(kind string, day time.Time) string {
+	return t.key(kind + ":" + day.Format("20060102"))
+}
@@ -0,0 +1,138 @@
+// Package activity 基于位图提供用户活跃（签到）记录、连续签到与活跃用户统计
+// @Author:冯铁城 [17615007230@163.com] 2025-09-01 10:00:00
+package activity
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 多日统计先用BITOP将日位图合并到{tracker}:tmp，再BITCOUNT，最后删除临时key，
+// 这些命令在一个事务中执行，因此多个客户端并发统计时不会互相覆盖临时结果
+
+// Retention 一个队列（某天的新增用户）的留存情况
+type Retention struct {
+	Cohort   time.Time // 队列日期
+	Size     int64     // 队列人数，即当天首次活跃的用户数
+	Days     []int     // 统计的天数，第Days[i]天即队列日期之后的第Days[i]天
+	Retained []int64   // 第Days[i]天仍活跃的队列用户数
+}
+
+// Rate 返回第Days[i]天的留存率，队列为空时为0
+func (r *Retention) Rate(i int) float64 {
+	if r.Size == 0 {
+		return 0
+	}
+	return float64(r.Retained[i]) / float64(r.Size)
+}
+
+// DAU 返回date当天的活跃用户数
+func (t *Tracker) DAU(ctx context.Context, date time.Time) (int64, error) {
+	return t.c.rdb.BitCount(ctx, t.dayKey("day", t.day(date)), nil).Result()
+}
+
+// WAU 返回截至date（含）最近7天的活跃用户数
+func (t *Tracker) WAU(ctx context.Context, date time.Time) (int64, error) {
+	day := t.day(date)
+	return t.ActiveUsers(ctx, day.AddDate(0, 0, -6), day)
+}
+
+// MAU 返回截至date（含）最近30天的活跃用户数
+func (t *Tracker) MAU(ctx context.Context, date time.Time) (int64, error) {
+	day := t.day(date)
+	return t.ActiveUsers(ctx, day.AddDate(0, 0, -29), day)
+}
+
+// ActiveUsers 返回[from, to]之间至少活跃过一天的用户数，用BITOP OR合并各日位图后统计
+func (t *Tracker) ActiveUsers(ctx context.Context, from, to time.Time) (int64, error) {
+	keys := t.dayKeys("day", t.day(from), t.day(to))
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if len(keys) == 1 {
+		return t.c.rdb.BitCount(ctx, keys[0], nil).Result()
+	}
+	var count *redis.IntCmd
+	_, err := t.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.BitOpOr(ctx, t.key("tmp"), keys...)
+		count = pipe.BitCount(ctx, t.key("tmp"), nil)
+		pipe.Del(ctx, t.key("tmp"))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// NewUsers 返回date当天首次活跃的用户数
+func (t *Tracker) NewUsers(ctx context.Context, date time.Time) (int64, error) {
+	return t.c.rdb.BitCount(ctx, t.dayKey("new", t.day(date)), nil).Result()
+}
+
+// Retention 计算cohort当天新增用户在之后各天的留存
+// 参数:
+//   - ctx: 上下文
+//   - cohort: 队列日期
+//   - days: 统计的天数，如1、7、30表示次日、7日、30日留存
+//
+// 返回:
+//   - 留存情况，第days[i]天的留存人数为队列位图与当天日活位图BITOP AND后的BITCOUNT
+//   - 错误信息
+func (t *Tracker) Retention(ctx context.Context, cohort time.Time, days ...int) (*Retention, error) {
+	res, err := t.Cohorts(ctx, cohort, cohort, days...)
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// Cohorts 计算[from, to]之间每天新增用户的留存，所有队列在一个事务中统计
+func (t *Tracker) Cohorts(ctx context.Context, from, to time.Time, days ...int) ([]*Retention, error) {
+	from, to = t.day(from), t.day(to)
+
+	//1.每个队列统计人数及与之后各天日活位图的交集
+	var (
+		result   []*Retention
+		sizes    []*redis.IntCmd
+		retained [][]*redis.IntCmd
+	)
+	_, err := t.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			result = append(result, &Retention{Cohort: d, Days: days, Retained: make([]int64, len(days))})
+			cohortKey := t.dayKey("new", d)
+			sizes = append(sizes, pipe.BitCount(ctx, cohortKey, nil))
+			cmds := make([]*redis.IntCmd, len(days))
+			for i, n := range days {
+				pipe.BitOpAnd(ctx, t.key("tmp"), cohortKey, t.dayKey("day", d.AddDate(0, 0, n)))
+				cmds[i] = pipe.BitCount(ctx, t.key("tmp"), nil)
+			}
+			retained = append(retained, cmds)
+		}
+		pipe.Del(ctx, t.key("tmp"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	//2.读取结果
+	for i, r := range result {
+		r.Size = sizes[i].Val()
+		for j, cmd := range retained[i] {
+			r.Retained[j] = cmd.Val()
+		}
+	}
+	return result, nil
+}
+
+// dayKeys 返回kind日位图在[from, to]之间的key
+func (t *Tracker) dayKeys(kind string, from, to time.Time) []string {
+	var keys []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		keys = append(keys, t.dayKey(kind, d))
+	}
+	return keys
+}
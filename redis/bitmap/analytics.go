@@ -0,0 +1,348 @@
+// Package bitmap 提供Redis位图操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-09-03 10:00:00
+package bitmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/redis/go-redis/v9"
+)
+
+// opChunk AndCount与OrCount每段读取的字节数
+const opChunk = 64 << 10
+
+// Range BITCOUNT与BITPOS的范围，Start与End都包含在内，负数表示从末尾倒数
+type Range struct {
+	Start int64
+	End   int64
+	unit  string
+}
+
+// ByteRange 按字节计算的范围，即BYTE模式，如ByteRange(0, -1)为整个位图
+func ByteRange(start, end int64) Range {
+	return Range{Start: start, End: end, unit: redis.BitCountIndexByte}
+}
+
+// BitRange 按位计算的范围，即BIT模式，需要Redis 7.0及以上版本
+func BitRange(start, end int64) Range {
+	return Range{Start: start, End: end, unit: redis.BitCountIndexBit}
+}
+
+// Unit 返回范围的模式，BYTE或BIT，零值为BYTE
+func (r Range) Unit() string {
+	if r.unit == "" {
+		return redis.BitCountIndexByte
+	}
+	return r.unit
+}
+
+// BitCountRange 统计范围r内值为1的位数
+func (c *Client) BitCountRange(ctx context.Context, key string, r Range) (int64, error) {
+	return c.rdb.BitCount(ctx, key, &redis.BitCount{Start: r.Start, End: r.End, Unit: r.Unit()}).Result()
+}
+
+// BitPosRange 返回范围r内第一个值为bit的位的偏移量，不存在时返回-1
+// 注意偏移量始终按位计算，与范围的模式无关
+func (c *Client) BitPosRange(ctx context.Context, key string, bit int, r Range) (int64, error) {
+	if bit != 0 && bit != 1 {
+		return 0, fmt.Errorf("bitmap: bit必须为0或1: %d", bit)
+	}
+	return c.rdb.BitPosSpan(ctx, key, int8(bit), r.Start, r.End, r.Unit()).Result()
+}
+
+// SetBits 在一个管道中将offsets处的位都设置为1，返回各位置原来的值
+func (c *Client) SetBits(ctx context.Context, key string, offsets ...int64) ([]int64, error) {
+	return c.setBits(ctx, key, 1, offsets)
+}
+
+// ClearBits 在一个管道中将offsets处的位都设置为0，返回各位置原来的值
+func (c *Client) ClearBits(ctx context.Context, key string, offsets ...int64) ([]int64, error) {
+	return c.setBits(ctx, key, 0, offsets)
+}
+
+// setBits 在一个管道中批量设置位
+func (c *Client) setBits(ctx context.Context, key string, value int, offsets []int64) ([]int64, error) {
+	if len(offsets) == 0 {
+		return nil, nil
+	}
+	cmds := make([]*redis.IntCmd, len(offsets))
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, offset := range offsets {
+			cmds[i] = pipe.SetBit(ctx, key, offset, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	old := make([]int64, len(cmds))
+	for i, cmd := range cmds {
+		old[i] = cmd.Val()
+	}
+	return old, nil
+}
+
+// Bools 将整个位图导出为[]bool，第i个元素为偏移量i的位，长度为字符串长度的8倍，key不存在时返回nil
+func (c *Client) Bools(ctx context.Context, key string) ([]bool, error) {
+	data, err := c.get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	result := make([]bool, len(data)*8)
+	eachOne(data, 0, func(offset int64) {
+		result[offset] = true
+	})
+	return result, nil
+}
+
+// Offsets 返回整个位图中值为1的位的偏移量，从小到大排列，key不存在时返回nil
+// 位图的偏移量小于2^32，因此可以用uint32表示
+func (c *Client) Offsets(ctx context.Context, key string) ([]uint32, error) {
+	data, err := c.get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var result []uint32
+	eachOne(data, 0, func(offset int64) {
+		result = append(result, uint32(offset))
+	})
+	return result, nil
+}
+
+// Roaring 将整个位图导出为roaring压缩位图，稀疏的位图占用的内存远小于[]bool，key不存在时返回空位图
+func (c *Client) Roaring(ctx context.Context, key string) (*roaring.Bitmap, error) {
+	offsets, err := c.Offsets(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	rb := roaring.New()
+	rb.AddMany(offsets)
+	return rb, nil
+}
+
+// get 读取整个位图，key不存在时返回nil
+func (c *Client) get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// AndCount 计算多个位图按位与之后值为1的位数，不写入目标key
+// 分段用管道GETRANGE读取各位图并在本地计算，可以在只读副本上执行，也不会在Redis中留下临时结果；
+// 每段在一个事务中读取，计算期间被修改的位图在不同段之间可能不一致
+func (c *Client) AndCount(ctx context.Context, keys ...string) (int64, error) {
+	return c.opCount(ctx, true, keys)
+}
+
+// OrCount 计算多个位图按位或之后值为1的位数，不写入目标key，实现方式与AndCount相同
+func (c *Client) OrCount(ctx context.Context, keys ...string) (int64, error) {
+	return c.opCount(ctx, false, keys)
+}
+
+// opCount 分段计算多个位图按位与或按位或之后值为1的位数
+func (c *Client) opCount(ctx context.Context, and bool, keys []string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	//1.读取各位图的长度，按位与只需计算到最短的位图，按位或需计算到最长的位图
+	lens := make([]*redis.IntCmd, len(keys))
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			lens[i] = pipe.StrLen(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	length := lens[0].Val()
+	for _, l := range lens[1:] {
+		if and {
+			length = min(length, l.Val())
+		} else {
+			length = max(length, l.Val())
+		}
+	}
+
+	//2.分段读取并计算
+	var count int64
+	for start := int64(0); start < length; start += opChunk {
+		end := min(start+opChunk, length) - 1
+		parts := make([]*redis.StringCmd, len(keys))
+		_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				parts[i] = pipe.GetRange(ctx, key, start, end)
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+		acc := make([]byte, end-start+1)
+		copy(acc, parts[0].Val())
+		for _, part := range parts[1:] {
+			data := part.Val()
+			for i := range acc {
+				var b byte
+				if i < len(data) {
+					b = data[i]
+				}
+				if and {
+					acc[i] &= b
+				} else {
+					acc[i] |= b
+				}
+			}
+		}
+		for _, b := range acc {
+			count += int64(bits.OnesCount8(b))
+		}
+	}
+	return count, nil
+}
+
+// eachOne 对data中每个值为1的位调用fn，偏移量从base开始计算，每个字节的最高位为偏移量最小的位
+func eachOne(data []byte, base int64, fn func(offset int64)) {
+	for i, b := range data {
+		for b != 0 {
+			j := bits.LeadingZeros8(b)
+			fn(base + int64(i)*8 + int64(j))
+			b &^= 0x80 >> j
+		}
+	}
+}
+
+// BitIterator 按偏移量从小到大遍历范围内值为1的位的迭代器
+// 每批先用BITPOS跳到下一个值为1的位，再用GETRANGE读取chunk个字节在本地解码，
+// 因此稀疏的位图不会读取大段的空白，稠密的位图也只需要少量请求
+type BitIterator struct {
+	c     *Client
+	key   string
+	r     Range
+	chunk int64
+	init  bool  // 是否已将范围换算为位偏移量
+	pos   int64 // 下一批开始的位偏移量
+	to    int64 // 最后一个位偏移量（包含）
+	buf   []int64
+	val   int64
+	done  bool
+	err   error
+}
+
+// Iterate 遍历范围r内值为1的位，每批最多读取chunk个字节
+func (c *Client) Iterate(key string, r Range, chunk int64) *BitIterator {
+	return &BitIterator{c: c, key: key, r: r, chunk: max(chunk, 1)}
+}
+
+// Next 移动到下一个值为1的位，没有更多或出错时返回false
+func (it *BitIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	//1.缓冲区为空时读取下一批
+	if len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		if it.err = it.fetch(ctx); it.err != nil || len(it.buf) == 0 {
+			return false
+		}
+	}
+
+	//2.取出缓冲区中的第一个偏移量
+	it.val, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Val 返回当前位的偏移量
+func (it *BitIterator) Val() int64 {
+	return it.val
+}
+
+// Err 返回迭代过程中的错误
+func (it *BitIterator) Err() error {
+	return it.err
+}
+
+// fetch 读取下一批值为1的位
+func (it *BitIterator) fetch(ctx context.Context) error {
+	if !it.init {
+		if err := it.resolve(ctx); err != nil {
+			return err
+		}
+		if it.done {
+			return nil
+		}
+	}
+
+	//1.跳到下一个值为1的位
+	next, err := it.c.rdb.BitPosSpan(ctx, it.key, 1, it.pos, it.to, redis.BitCountIndexBit).Result()
+	if err != nil {
+		return err
+	}
+	if next < 0 {
+		it.done = true
+		return nil
+	}
+
+	//2.从该位所在的字节开始读取一批并解码
+	first := next / 8
+	last := min(first+it.chunk-1, it.to/8)
+	data, err := it.c.rdb.GetRange(ctx, it.key, first, last).Bytes()
+	if err != nil {
+		return err
+	}
+	eachOne(data, first*8, func(offset int64) {
+		if offset >= next && offset <= it.to {
+			it.buf = append(it.buf, offset)
+		}
+	})
+
+	//3.推进到下一批
+	it.pos = (last + 1) * 8
+	if it.pos > it.to || int64(len(data)) < last-first+1 {
+		it.done = true
+	}
+	return nil
+}
+
+// resolve 将范围换算为位偏移量，负数的端点需要先读取位图的长度
+func (it *BitIterator) resolve(ctx context.Context) error {
+	it.init = true
+	start, end := it.r.Start, it.r.End
+
+	//1.换算负数端点
+	if start < 0 || end < 0 {
+		size, err := it.c.rdb.StrLen(ctx, it.key).Result()
+		if err != nil {
+			return err
+		}
+		if it.r.Unit() == redis.BitCountIndexBit {
+			size *= 8
+		}
+		if start < 0 {
+			start = max(size+start, 0)
+		}
+		if end < 0 {
+			end = size + end
+		}
+	}
+
+	//2.字节范围换算为位范围
+	if it.r.Unit() == redis.BitCountIndexByte {
+		start, end = start*8, end*8+7
+	}
+	it.pos, it.to = start, end
+	if it.pos > it.to {
+		it.done = true
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+// Package bitmap 提供Redis位图操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-09-02 10:00:00
+package bitmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrReadOnly 只读执行（BITFIELD_RO）中包含SET或INCRBY操作
+var ErrReadOnly = errors.New("bitmap: BITFIELD_RO只支持GET操作")
+
+// Type 位域的整数类型，有符号1~64位，无符号1~63位
+type Type struct {
+	signed bool
+	bits   int
+}
+
+// Int 返回bits位的有符号整数类型，如Int(8)即i8
+func Int(bits int) Type {
+	return Type{signed: true, bits: bits}
+}
+
+// Uint 返回bits位的无符号整数类型，如Uint(8)即u8
+func Uint(bits int) Type {
+	return Type{bits: bits}
+}
+
+// Signed 是否为有符号类型
+func (t Type) Signed() bool {
+	return t.signed
+}
+
+// Bits 类型的位数
+func (t Type) Bits() int {
+	return t.bits
+}
+
+// String 返回BITFIELD命令中的类型写法，如i8、u16
+func (t Type) String() string {
+	if t.signed {
+		return "i" + strconv.Itoa(t.bits)
+	}
+	return "u" + strconv.Itoa(t.bits)
+}
+
+// Min 类型能表示的最小值，类型不合法时为0
+func (t Type) Min() int64 {
+	if !t.signed || t.validate() != nil {
+		return 0
+	}
+	return -1 << (t.bits - 1)
+}
+
+// Max 类型能表示的最大值，类型不合法时为0
+func (t Type) Max() int64 {
+	if t.validate() != nil {
+		return 0
+	}
+	if t.signed {
+		return 1<<(t.bits-1) - 1
+	}
+	return 1<<t.bits - 1
+}
+
+// validate 验证类型的位数，Redis不支持u64
+func (t Type) validate() error {
+	if t.bits < 1 || t.bits > 64 || (!t.signed && t.bits > 63) {
+		return fmt.Errorf("bitmap: 不支持的位域类型%s", t)
+	}
+	return nil
+}
+
+// Offset 位域的偏移量
+type Offset struct {
+	n          int64
+	positional bool
+}
+
+// Bit 按位计算的偏移量，即从第n位开始
+func Bit(n int64) Offset {
+	return Offset{n: n}
+}
+
+// Index 按类型宽度计算的偏移量，即BITFIELD的#n写法，u8的Index(3)从第24位开始
+func Index(n int64) Offset {
+	return Offset{n: n, positional: true}
+}
+
+// String 返回BITFIELD命令中的偏移量写法
+func (o Offset) String() string {
+	if o.positional {
+		return "#" + strconv.FormatInt(o.n, 10)
+	}
+	return strconv.FormatInt(o.n, 10)
+}
+
+// Overflow INCRBY与SET的溢出策略
+type Overflow string
+
+const (
+	Wrap Overflow = "WRAP" // 回绕，默认策略
+	Sat  Overflow = "SAT"  // 饱和，结果取类型的最小值或最大值
+	Fail Overflow = "FAIL" // 失败，不执行该操作，结果的Overflowed为true
+)
+
+// Result 位域操作的结果
+type Result struct {
+	Type       Type  // 操作声明的类型
+	Value      int64 // GET为当前值，SET为旧值，INCRBY为新值
+	Overflowed bool  // FAIL策略下操作因溢出未执行
+}
+
+// Int 以有符号整数返回结果
+func (r Result) Int() int64 {
+	return r.Value
+}
+
+// Uint 以无符号整数返回结果，有符号类型的负数按类型位数取补码
+func (r Result) Uint() uint64 {
+	if r.Type.signed && r.Type.bits < 64 {
+		return uint64(r.Value) & (1<<r.Type.bits - 1)
+	}
+	return uint64(r.Value)
+}
+
+// Bool 以布尔值返回结果，非0为true，适用于1位的类型
+func (r Result) Bool() bool {
+	return r.Value != 0
+}
+
+// fieldOp 一个位域操作
+type fieldOp struct {
+	op       string // GET、SET、INCRBY或OVERFLOW
+	typ      Type
+	offset   Offset
+	value    int64
+	overflow Overflow
+}
+
+// BitFields BITFIELD命令构造器
+// 通过链式调用按顺序添加Get、Set、IncrBy与Overflow操作，最后调用Exec或ExecRO执行，参数错误在执行时返回
+type BitFields struct {
+	c   *Client
+	key string
+	ops []fieldOp
+}
+
+// BitFields 创建针对key的BITFIELD命令
+func (c *Client) BitFields(key string) *BitFields {
+	return &BitFields{c: c, key: key}
+}
+
+// Get 读取offset处类型为t的值
+func (b *BitFields) Get(t Type, offset Offset) *BitFields {
+	b.ops = append(b.ops, fieldOp{op: "GET", typ: t, offset: offset})
+	return b
+}
+
+// Set 将offset处类型为t的值设置为value，结果为旧值，value必须在类型的范围内
+func (b *BitFields) Set(t Type, offset Offset, value int64) *BitFields {
+	b.ops = append(b.ops, fieldOp{op: "SET", typ: t, offset: offset, value: value})
+	return b
+}
+
+// IncrBy 将offset处类型为t的值增加incr，incr可以为负数，结果为新值，溢出时按溢出策略处理
+func (b *BitFields) IncrBy(t Type, offset Offset, incr int64) *BitFields {
+	b.ops = append(b.ops, fieldOp{op: "INCRBY", typ: t, offset: offset, value: incr})
+	return b
+}
+
+// Overflow 设置之后的SET与INCRBY操作的溢出策略，直到下一次调用Overflow
+func (b *BitFields) Overflow(overflow Overflow) *BitFields {
+	b.ops = append(b.ops, fieldOp{op: "OVERFLOW", overflow: overflow})
+	return b
+}
+
+// Exec 执行BITFIELD命令
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - 每个Get、Set、IncrBy操作的结果，顺序与添加的顺序相同
+//   - 错误信息，类型、溢出策略或SET的值不合法时返回错误
+func (b *BitFields) Exec(ctx context.Context) ([]Result, error) {
+	return b.exec(ctx, "BITFIELD")
+}
+
+// ExecRO 以BITFIELD_RO执行，可以在只读副本上执行，只支持Get操作，否则返回ErrReadOnly
+func (b *BitFields) ExecRO(ctx context.Context) ([]Result, error) {
+	for _, op := range b.ops {
+		if op.op != "GET" {
+			return nil, fmt.Errorf("%w: %s", ErrReadOnly, op.op)
+		}
+	}
+	return b.exec(ctx, "BITFIELD_RO")
+}
+
+// exec 组装参数并执行命令，将结果按声明的类型解码
+func (b *BitFields) exec(ctx context.Context, cmd string) ([]Result, error) {
+	args, types, err := b.build(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	//1.FAIL策略下溢出的结果为nil，IntSliceCmd无法解析，因此使用Do
+	res, err := b.c.rdb.Do(ctx, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	//2.解码结果
+	results := make([]Result, len(res))
+	for i, v := range res {
+		results[i].Type = types[i]
+		if v == nil {
+			results[i].Overflowed = true
+			continue
+		}
+		n, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("bitmap: BITFIELD返回了非整数结果: %v", v)
+		}
+		results[i].Value = n
+	}
+	return results, nil
+}
+
+// build 验证操作并组装命令参数，返回参数与每个结果对应的类型
+func (b *BitFields) build(cmd string) ([]interface{}, []Type, error) {
+	args := []interface{}{cmd, b.key}
+	var types []Type
+	for _, op := range b.ops {
+
+		//1.溢出策略
+		if op.op == "OVERFLOW" {
+			if op.overflow != Wrap && op.overflow != Sat && op.overflow != Fail {
+				return nil, nil, fmt.Errorf("bitmap: 不支持的溢出策略%q", op.overflow)
+			}
+			args = append(args, op.op, string(op.overflow))
+			continue
+		}
+
+		//2.类型、偏移量与SET的值
+		if err := op.typ.validate(); err != nil {
+			return nil, nil, err
+		}
+		if op.offset.n < 0 {
+			return nil, nil, fmt.Errorf("bitmap: 偏移量不能为负数: %s", op.offset)
+		}
+		args = append(args, op.op, op.typ.String(), op.offset.String())
+		if op.op == "SET" {
+			if op.value < op.typ.Min() || op.value > op.typ.Max() {
+				return nil, nil, fmt.Errorf("bitmap: %d超出%s的范围", op.value, op.typ)
+			}
+			args = append(args, op.value)
+		} else if op.op == "INCRBY" {
+			args = append(args, op.value)
+		}
+		types = append(types, op.typ)
+	}
+	return args, types, nil
+}
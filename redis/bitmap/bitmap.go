@@ -27,7 +27,7 @@ func (c *Client) GetBit(ctx context.Context, key string, offset int64) (int64, e
 	return c.rdb.GetBit(ctx, key, offset).Result()
 }
 
-// BitCount 计算给定字符串中，被设置为1的比特位的数量
+// BitCount 计算给定字符串中，被设置为1的比特位的数量，显式指定BYTE/BIT模式的写法见BitCountRange
 func (c *Client) BitCount(ctx context.Context, key string, bitCount *redis.BitCount) (int64, error) {
 	return c.rdb.BitCount(ctx, key, bitCount).Result()
 }
@@ -56,12 +56,12 @@ func (c *Client) BitOpNot(ctx context.Context, destKey string, key string) (int6
 	return c.rdb.BitOpNot(ctx, destKey, key).Result()
 }
 
-// BitPos 返回位图中第一个值为bit的二进制位的位置
+// BitPos 返回位图中第一个值为bit的二进制位的位置，pos按字节计算，按位计算的范围见BitPosRange
 func (c *Client) BitPos(ctx context.Context, key string, bit int64, pos ...int64) (int64, error) {
 	return c.rdb.BitPos(ctx, key, bit, pos...).Result()
 }
 
-// BitField 对字符串进行任意位长度和偏移量的位域操作
+// BitField 对字符串进行任意位长度和偏移量的位域操作，参数需要手工组装，类型化的写法见BitFields
 func (c *Client) BitField(ctx context.Context, key string, args ...interface{}) ([]int64, error) {
 	return c.rdb.BitField(ctx, key, args...).Result()
 }
@@ -0,0 +1,238 @@
+// Package bloom 提供布隆过滤器、布谷鸟过滤器、Count-Min Sketch与Top-K等概率数据结构，支持Redis原生实现与RedisBloom模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-30 10:00:00
+package bloom
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 位图过滤器由以下key组成：
+//   - {key}:meta  哈希，layers为当前层数，count为当前层已添加的元素数
+//   - {key}:{i}   位图，第i层（从0开始）
+// 每层的位数与哈希函数个数由配置推导，不保存在Redis中；元素的两个32位哈希值在Go中计算，
+// 各哈希函数的位置在脚本中计算，从而在一个脚本内完成跨层的判断与写入
+
+// maxLayers 最大层数
+const maxLayers = 16
+
+// maxBits 单个位图的最大位数，即Redis字符串的512MB上限
+const maxBits = 1 << 32
+
+// fullMarker 脚本返回的过滤器已满错误
+const fullMarker = "BLOOM_FULL"
+
+// layerLua 脚本开头共用：解析层参数并读取当前状态
+// KEYS[1]=meta KEYS[2..]=各层位图
+// ARGV[1]=层数上限n ARGV[2..3n+1]=每层的容量、位数、哈希函数个数 其后为元素的h1、h2
+const layerLua = `
+local n = tonumber(ARGV[1])
+local caps, bits, ks = {}, {}, {}
+for i = 1, n do
+	caps[i] = tonumber(ARGV[3 * i - 1])
+	bits[i] = tonumber(ARGV[3 * i])
+	ks[i] = tonumber(ARGV[3 * i + 1])
+end
+local layers = tonumber(redis.call("HGET", KEYS[1], "layers") or "1")
+local count = tonumber(redis.call("HGET", KEYS[1], "count") or "0")
+local function contains(h1, h2)
+	for l = 1, layers do
+		local all = true
+		for i = 0, ks[l] - 1 do
+			if redis.call("GETBIT", KEYS[l + 1], (h1 + i * h2) % bits[l]) == 0 then
+				all = false
+				break
+			end
+		end
+		if all then
+			return true
+		end
+	end
+	return false
+end
+`
+
+// addScript 添加元素：所有层都不包含时写入当前层，当前层满时新建一层，返回每个元素是否为新添加的
+var addScript = redis.NewScript(layerLua + `
+local result = {}
+local full = false
+for p = 3 * n + 2, #ARGV, 2 do
+	local h1, h2 = tonumber(ARGV[p]), tonumber(ARGV[p + 1])
+	if contains(h1, h2) then
+		table.insert(result, 0)
+	else
+		if count >= caps[layers] then
+			if layers >= n then
+				full = true
+				break
+			end
+			layers = layers + 1
+			count = 0
+		end
+		for i = 0, ks[layers] - 1 do
+			redis.call("SETBIT", KEYS[layers + 1], (h1 + i * h2) % bits[layers], 1)
+		end
+		count = count + 1
+		table.insert(result, 1)
+	end
+end
+redis.call("HSET", KEYS[1], "layers", layers, "count", count)
+if full then
+	return redis.error_reply("` + fullMarker + `")
+end
+return result
+`)
+
+// existsScript 判断元素是否存在
+var existsScript = redis.NewScript(layerLua + `
+local result = {}
+for p = 3 * n + 2, #ARGV, 2 do
+	if contains(tonumber(ARGV[p]), tonumber(ARGV[p + 1])) then
+		table.insert(result, 1)
+	else
+		table.insert(result, 0)
+	end
+end
+return result
+`)
+
+// layer 层参数
+type layer struct {
+	capacity int64
+	bits     uint64
+	hashes   int
+}
+
+// BitmapFilter 基于Redis位图的布隆过滤器，可扩容时一层满后新建更大且误判率更低的层
+type BitmapFilter struct {
+	c    *Client
+	opts *Options
+}
+
+// Add 原子地添加元素，过滤器已满时返回ErrFilterFull，此前的元素已写入
+func (f *BitmapFilter) Add(ctx context.Context, key string, items ...string) ([]bool, error) {
+	return f.run(ctx, addScript, key, items)
+}
+
+// Exists 判断元素是否（可能）存在
+func (f *BitmapFilter) Exists(ctx context.Context, key string, items ...string) ([]bool, error) {
+	return f.run(ctx, existsScript, key, items)
+}
+
+// Info 返回过滤器的状态
+func (f *BitmapFilter) Info(ctx context.Context, key string) (*Info, error) {
+
+	//1.读取当前层数与当前层元素数
+	layers, err := f.layers()
+	if err != nil {
+		return nil, err
+	}
+	meta, err := f.c.rdb.HMGet(ctx, f.metaKey(key), "layers", "count").Result()
+	if err != nil {
+		return nil, err
+	}
+	if meta[0] == nil {
+		return &Info{}, nil
+	}
+	n, _ := strconv.ParseInt(meta[0].(string), 10, 64)
+	count, _ := strconv.ParseInt(meta[1].(string), 10, 64)
+
+	//2.此前的层都已写满
+	info := &Info{Layers: n, Items: count}
+	for i := int64(0); i < n && i < int64(len(layers)); i++ {
+		info.Capacity += layers[i].capacity
+		if i < n-1 {
+			info.Items += layers[i].capacity
+		}
+	}
+	return info, nil
+}
+
+// Delete 删除过滤器的所有层
+func (f *BitmapFilter) Delete(ctx context.Context, key string) error {
+	return f.c.rdb.Del(ctx, f.keys(key, maxLayers)...).Err()
+}
+
+// run 计算层参数与元素哈希值后执行脚本
+func (f *BitmapFilter) run(ctx context.Context, script *redis.Script, key string, items []string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	//1.层参数
+	layers, err := f.layers()
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, 0, 1+3*len(layers)+2*len(items))
+	args = append(args, len(layers))
+	for _, l := range layers {
+		args = append(args, l.capacity, l.bits, l.hashes)
+	}
+
+	//2.元素哈希值
+	for _, item := range items {
+		h1, h2 := hash(item)
+		args = append(args, h1, h2)
+	}
+
+	//3.执行脚本
+	res, err := script.Run(ctx, f.c.rdb, f.keys(key, len(layers)), args...).Int64Slice()
+	if err != nil {
+		if strings.Contains(err.Error(), fullMarker) {
+			return nil, ErrFilterFull
+		}
+		return nil, err
+	}
+	return toBools(res), nil
+}
+
+// layers 根据配置推导各层参数：第i层容量为Capacity·Expansion^i，误判率为ErrorRate·(1-r)·r^i，
+// 各层误判率之和不超过ErrorRate；不扩容时只有一层，误判率为ErrorRate；层数受maxLayers与单个位图的大小限制
+func (f *BitmapFilter) layers() ([]layer, error) {
+	if err := f.opts.validate(); err != nil {
+		return nil, err
+	}
+	var layers []layer
+	capacity := float64(f.opts.Capacity)
+	errorRate := f.opts.ErrorRate
+	if f.opts.Expansion > 0 {
+		errorRate *= 1 - tightening
+	}
+	for i := 0; i < maxLayers; i++ {
+		bits, hashes := Size(int64(capacity), errorRate)
+		if bits > maxBits {
+			break
+		}
+		layers = append(layers, layer{capacity: int64(capacity), bits: bits, hashes: hashes})
+		if f.opts.Expansion == 0 || capacity*float64(f.opts.Expansion) > math.MaxInt64/2 {
+			break
+		}
+		capacity *= float64(f.opts.Expansion)
+		errorRate *= tightening
+	}
+	if len(layers) == 0 {
+		return nil, errors.New("bloom: 容量过大，超出单个位图的大小上限")
+	}
+	return layers, nil
+}
+
+// keys 返回meta与前n层位图的key
+func (f *BitmapFilter) keys(key string, n int) []string {
+	keys := make([]string, 0, n+1)
+	keys = append(keys, f.metaKey(key))
+	for i := 0; i < n; i++ {
+		keys = append(keys, key+":"+strconv.Itoa(i))
+	}
+	return keys
+}
+
+// metaKey 返回meta哈希的key
+func (f *BitmapFilter) metaKey(key string) string {
+	return key + ":meta"
+}
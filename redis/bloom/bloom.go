@@ -0,0 +1,159 @@
+// Package bloom 提供布隆过滤器、布谷鸟过滤器、Count-Min Sketch与Top-K等概率数据结构，支持Redis原生实现与RedisBloom模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-30 10:00:00
+package bloom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrInvalidOptions 配置不合法
+	ErrInvalidOptions = errors.New("bloom: 配置不合法")
+
+	// ErrFilterFull 不扩容的过滤器已达到容量，可扩容的过滤器已达到层数上限，或布谷鸟过滤器无法为元素腾出位置
+	ErrFilterFull = errors.New("bloom: 过滤器已满")
+)
+
+// tightening 可扩容过滤器每新增一层，该层的误判率乘以该比例，使总误判率收敛于ErrorRate
+const tightening = 0.5
+
+// Filter 布隆过滤器，位图与RedisBloom两种实现的统一接口
+// 布隆过滤器只会误判存在，不会误判不存在
+type Filter interface {
+	// Add 添加元素，返回每个元素是否为新添加的，false表示元素（可能）已存在
+	Add(ctx context.Context, key string, items ...string) ([]bool, error)
+
+	// Exists 判断元素是否（可能）存在
+	Exists(ctx context.Context, key string, items ...string) ([]bool, error)
+
+	// Info 返回过滤器的状态，过滤器不存在时各项为0
+	Info(ctx context.Context, key string) (*Info, error)
+
+	// Delete 删除整个过滤器
+	Delete(ctx context.Context, key string) error
+}
+
+// Options 定义了布隆过滤器的配置选项，只在过滤器首次写入时生效，之后必须保持不变
+type Options struct {
+	Capacity  int64   // 预计元素数量，可扩容时为第一层的容量
+	ErrorRate float64 // 期望的误判率
+	Expansion int     // 扩容倍数，一层满后新建容量为上一层Expansion倍的层；为0时不扩容，满后返回ErrFilterFull
+}
+
+// DefaultOptions 返回一个包含推荐默认值的布隆过滤器配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		Capacity:  100000, // 默认第一层容纳10万个元素
+		ErrorRate: 0.01,   // 默认误判率1%
+		Expansion: 2,      // 默认每层容量翻倍
+	}
+}
+
+// validate 验证配置
+func (o *Options) validate() error {
+	if o.Capacity <= 0 || !(o.ErrorRate > 0 && o.ErrorRate < 1) || o.Expansion < 0 {
+		return fmt.Errorf("%w: 容量必须大于0，误判率必须在(0, 1)之间，扩容倍数不能为负数", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// Info 过滤器状态
+type Info struct {
+	Capacity int64 // 所有层的总容量
+	Items    int64 // 已添加的元素数量
+	Layers   int64 // 层数
+}
+
+// Size 根据预计元素数量n与误判率p计算位数m与哈希函数个数k
+// m = -n·ln(p) / (ln2)²，k = m/n·ln2，k至少为1
+func Size(capacity int64, errorRate float64) (bits uint64, hashes int) {
+	n := float64(capacity)
+	m := math.Ceil(-n * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / n * math.Ln2))
+	return uint64(m), max(k, 1)
+}
+
+// hashSeed 第二个哈希函数的种子
+const hashSeed = 0x9e3779b97f4a7c15
+
+// hash 计算元素的两个32位哈希值，第i个哈希函数为 (h1 + i·h2) mod m
+// 32位的哈希值使脚本中的运算在双精度浮点数范围内保持精确
+func hash(item string) (h1, h2 uint32) {
+	d := xxhash.NewWithSeed(hashSeed)
+	_, _ = d.WriteString(item)
+	return uint32(xxhash.Sum64String(item)), uint32(d.Sum64()) | 1
+}
+
+// toBools 将脚本返回的0/1列表转换为布尔值
+func toBools(res []int64) []bool {
+	result := make([]bool, len(res))
+	for i, v := range res {
+		result[i] = v == 1
+	}
+	return result
+}
+
+// Client 布隆过滤器客户端
+type Client struct {
+	rdb *redis.Client
+}
+
+// New 创建布隆过滤器客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb}
+}
+
+// Bitmap 返回基于位图的过滤器，opts为nil时使用默认配置
+func (c *Client) Bitmap(opts *Options) *BitmapFilter {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &BitmapFilter{c: c, opts: opts}
+}
+
+// Module 返回基于RedisBloom模块的过滤器，opts为nil时使用默认配置
+func (c *Client) Module(opts *Options) *ModuleFilter {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &ModuleFilter{c: c, opts: opts}
+}
+
+// Filter 检测服务端是否加载了RedisBloom模块，有则使用模块过滤器，否则使用位图过滤器
+func (c *Client) Filter(ctx context.Context, opts *Options) (Filter, error) {
+	ok, err := c.HasModule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return c.Module(opts), nil
+	}
+	return c.Bitmap(opts), nil
+}
+
+// HasModule 检测服务端是否支持RedisBloom命令
+func (c *Client) HasModule(ctx context.Context) (bool, error) {
+
+	//1.查询一个不存在的key，模块存在时返回not found错误，模块不存在时返回未知命令
+	err := c.rdb.Do(ctx, "BF.INFO", "bloom:probe:nonexistent").Err()
+	if err == nil {
+		return true, nil
+	}
+
+	//2.根据错误信息判断
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "unknown command") {
+		return false, nil
+	}
+	if strings.Contains(msg, "not found") {
+		return true, nil
+	}
+	return false, err
+}
@@ -0,0 +1,298 @@
+// Package bloom 提供布隆过滤器、布谷鸟过滤器、Count-Min Sketch与Top-K等概率数据结构，支持Redis原生实现与RedisBloom模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-31 10:00:00
+package bloom
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BITFIELD实现的布谷鸟过滤器保存在一个字符串中：
+//   - 偏移0处的u32为已添加的元素数
+//   - 其后每个桶4个槽位，每个槽位是一个u16指纹，0表示空槽位
+// 元素的指纹与第一个候选桶在Go中计算，第二个候选桶为 桶号 xor hash(指纹)，在脚本中计算，
+// 从而在踢出元素时只凭指纹就能找到它的另一个候选桶
+
+// cuckooBucketSize 每个桶的槽位数
+const cuckooBucketSize = 4
+
+// maxBuckets 桶数上限，桶数为2的幂，最后一个槽位 #(2+桶数·4-1) 的位偏移量必须小于2^32
+const maxBuckets = 1 << 25
+
+// cuckooLua 脚本开头共用：桶与槽位的读写
+// KEYS[1]=过滤器 ARGV[1]=桶数 其后为元素的指纹与第一个候选桶
+const cuckooLua = `
+local nb = tonumber(ARGV[1])
+local function slot(b, s)
+	return "#" .. (2 + b * 4 + s)
+end
+local function alt(b, fp)
+	return bit.band(bit.bxor(b, (fp * 0x5bd1e995) % nb), nb - 1)
+end
+local function find(b, fp)
+	local r = redis.call("BITFIELD", KEYS[1], "GET", "u16", slot(b, 0), "GET", "u16", slot(b, 1),
+		"GET", "u16", slot(b, 2), "GET", "u16", slot(b, 3))
+	for s = 1, 4 do
+		if r[s] == fp then
+			return s - 1
+		end
+	end
+	return nil
+end
+local function set(b, s, fp)
+	return redis.call("BITFIELD", KEYS[1], "SET", "u16", slot(b, s), fp)[1]
+end
+`
+
+// cuckooAddScript 添加不存在的元素：两个候选桶都满时随机踢出一个指纹放到它的另一个候选桶，
+// 踢出次数达到上限时撤销本次的所有踢出并返回过滤器已满
+// ARGV[2]=踢出次数上限
+var cuckooAddScript = redis.NewScript(cuckooLua + `
+local maxKicks = tonumber(ARGV[2])
+local function insert(fp, i1, i2)
+	for _, b in ipairs({i1, i2}) do
+		local s = find(b, 0)
+		if s then
+			set(b, s, fp)
+			return true
+		end
+	end
+	local b = i1
+	if math.random(2) == 2 then
+		b = i2
+	end
+	local undo = {}
+	for n = 1, maxKicks do
+		local s = math.random(4) - 1
+		local victim = set(b, s, fp)
+		table.insert(undo, {b, s, victim})
+		fp, b = victim, alt(b, victim)
+		local e = find(b, 0)
+		if e then
+			set(b, e, fp)
+			return true
+		end
+	end
+	for n = #undo, 1, -1 do
+		set(undo[n][1], undo[n][2], undo[n][3])
+	end
+	return false
+end
+
+local result = {}
+for p = 3, #ARGV, 2 do
+	local fp, i1 = tonumber(ARGV[p]), tonumber(ARGV[p + 1])
+	local i2 = alt(i1, fp)
+	if find(i1, fp) or find(i2, fp) then
+		table.insert(result, 0)
+	elseif insert(fp, i1, i2) then
+		redis.call("BITFIELD", KEYS[1], "INCRBY", "u32", 0, 1)
+		table.insert(result, 1)
+	else
+		return redis.error_reply("` + fullMarker + `")
+	end
+end
+return result
+`)
+
+// cuckooExistsScript 判断元素是否存在
+var cuckooExistsScript = redis.NewScript(cuckooLua + `
+local result = {}
+for p = 2, #ARGV, 2 do
+	local fp, i1 = tonumber(ARGV[p]), tonumber(ARGV[p + 1])
+	if find(i1, fp) or find(alt(i1, fp), fp) then
+		table.insert(result, 1)
+	else
+		table.insert(result, 0)
+	end
+end
+return result
+`)
+
+// cuckooRemoveScript 删除元素的一个指纹
+var cuckooRemoveScript = redis.NewScript(cuckooLua + `
+local result = {}
+for p = 2, #ARGV, 2 do
+	local fp, i1 = tonumber(ARGV[p]), tonumber(ARGV[p + 1])
+	local removed = 0
+	for _, b in ipairs({i1, alt(i1, fp)}) do
+		local s = find(b, fp)
+		if s then
+			set(b, s, 0)
+			redis.call("BITFIELD", KEYS[1], "INCRBY", "u32", 0, -1)
+			removed = 1
+			break
+		end
+	end
+	table.insert(result, removed)
+end
+return result
+`)
+
+// Cuckoo 布谷鸟过滤器，BITFIELD与RedisBloom两种实现的统一接口
+// 与布隆过滤器相比支持删除元素；只能删除确实添加过的元素，否则可能删除与其指纹相同的其他元素
+type Cuckoo interface {
+	// Add 添加不存在的元素，返回每个元素是否为新添加的，false表示元素（可能）已存在
+	Add(ctx context.Context, key string, items ...string) ([]bool, error)
+
+	// Exists 判断元素是否（可能）存在
+	Exists(ctx context.Context, key string, items ...string) ([]bool, error)
+
+	// Remove 删除元素，返回每个元素是否被删除
+	Remove(ctx context.Context, key string, items ...string) ([]bool, error)
+
+	// Info 返回过滤器的状态，过滤器不存在时各项为0
+	Info(ctx context.Context, key string) (*Info, error)
+
+	// Delete 删除整个过滤器
+	Delete(ctx context.Context, key string) error
+}
+
+// CuckooOptions 定义了布谷鸟过滤器的配置选项，只在过滤器首次写入时生效，之后必须保持不变
+type CuckooOptions struct {
+	Capacity int64 // 预计元素数量，BITFIELD实现的桶数为 Capacity/4 向上取整到2的幂
+	MaxKicks int   // 两个候选桶都满时踢出其他元素的次数上限，超过时返回ErrFilterFull
+}
+
+// DefaultCuckooOptions 返回一个包含推荐默认值的布谷鸟过滤器配置实例
+func DefaultCuckooOptions() *CuckooOptions {
+	return &CuckooOptions{
+		Capacity: 100000, // 默认容纳10万个元素
+		MaxKicks: 500,    // 默认最多踢出500次
+	}
+}
+
+// validate 验证配置
+func (o *CuckooOptions) validate() error {
+	if o.Capacity <= 0 || o.MaxKicks <= 0 {
+		return fmt.Errorf("%w: 容量与踢出次数上限必须大于0", ErrInvalidOptions)
+	}
+	if o.buckets() > maxBuckets {
+		return fmt.Errorf("%w: 容量过大，超出单个字符串的大小上限", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// buckets 返回桶数
+func (o *CuckooOptions) buckets() uint64 {
+	n := uint64(o.Capacity+cuckooBucketSize-1) / cuckooBucketSize
+	return 1 << bits.Len64(n-1)
+}
+
+// fingerprint 计算元素的16位指纹（不为0，0表示空槽位）与第一个候选桶，buckets必须是2的幂
+func fingerprint(item string, buckets uint64) (fp uint32, bucket uint64) {
+	h1, h2 := hash(item)
+	return (h2>>16)%0xffff + 1, uint64(h1) & (buckets - 1)
+}
+
+// BitFieldCuckoo 基于BITFIELD的布谷鸟过滤器，每个桶4个槽位，指纹16位，误判率约为0.012%
+// 不扩容，满后返回ErrFilterFull，装载率通常可以达到95%以上
+type BitFieldCuckoo struct {
+	c    *Client
+	opts *CuckooOptions
+}
+
+// BitFieldCuckoo 返回基于BITFIELD的布谷鸟过滤器，opts为nil时使用默认配置
+func (c *Client) BitFieldCuckoo(opts *CuckooOptions) *BitFieldCuckoo {
+	if opts == nil {
+		opts = DefaultCuckooOptions()
+	}
+	return &BitFieldCuckoo{c: c, opts: opts}
+}
+
+// ModuleCuckoo 返回基于RedisBloom模块的布谷鸟过滤器，opts为nil时使用默认配置
+func (c *Client) ModuleCuckoo(opts *CuckooOptions) *ModuleCuckoo {
+	if opts == nil {
+		opts = DefaultCuckooOptions()
+	}
+	return &ModuleCuckoo{c: c, opts: opts}
+}
+
+// Cuckoo 检测服务端是否加载了RedisBloom模块，有则使用模块实现，否则使用BITFIELD实现
+func (c *Client) Cuckoo(ctx context.Context, opts *CuckooOptions) (Cuckoo, error) {
+	ok, err := c.HasModule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return c.ModuleCuckoo(opts), nil
+	}
+	return c.BitFieldCuckoo(opts), nil
+}
+
+// Add 原子地添加元素，过滤器已满时返回ErrFilterFull，此前的元素已写入
+func (f *BitFieldCuckoo) Add(ctx context.Context, key string, items ...string) ([]bool, error) {
+	return f.run(ctx, cuckooAddScript, key, items, f.opts.MaxKicks)
+}
+
+// Exists 判断元素是否（可能）存在
+func (f *BitFieldCuckoo) Exists(ctx context.Context, key string, items ...string) ([]bool, error) {
+	return f.run(ctx, cuckooExistsScript, key, items)
+}
+
+// Remove 删除元素，返回每个元素是否被删除
+func (f *BitFieldCuckoo) Remove(ctx context.Context, key string, items ...string) ([]bool, error) {
+	return f.run(ctx, cuckooRemoveScript, key, items)
+}
+
+// Info 返回过滤器的状态
+func (f *BitFieldCuckoo) Info(ctx context.Context, key string) (*Info, error) {
+	if err := f.opts.validate(); err != nil {
+		return nil, err
+	}
+	pipe := f.c.rdb.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	count := pipe.BitField(ctx, key, "GET", "u32", 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if exists.Val() == 0 {
+		return &Info{}, nil
+	}
+	return &Info{
+		Capacity: int64(f.opts.buckets() * cuckooBucketSize),
+		Items:    count.Val()[0],
+		Layers:   1,
+	}, nil
+}
+
+// Delete 删除过滤器
+func (f *BitFieldCuckoo) Delete(ctx context.Context, key string) error {
+	return f.c.rdb.Del(ctx, key).Err()
+}
+
+// run 计算元素的指纹与候选桶后执行脚本，extra为桶数之后、元素之前的参数
+func (f *BitFieldCuckoo) run(ctx context.Context, script *redis.Script, key string, items []string, extra ...interface{}) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := f.opts.validate(); err != nil {
+		return nil, err
+	}
+
+	//1.桶数与额外参数
+	buckets := f.opts.buckets()
+	args := make([]interface{}, 0, 1+len(extra)+2*len(items))
+	args = append(args, buckets)
+	args = append(args, extra...)
+
+	//2.元素的指纹与第一个候选桶
+	for _, item := range items {
+		fp, bucket := fingerprint(item, buckets)
+		args = append(args, fp, bucket)
+	}
+
+	//3.执行脚本
+	res, err := script.Run(ctx, f.c.rdb, []string{key}, args...).Int64Slice()
+	if err != nil {
+		if strings.Contains(err.Error(), fullMarker) {
+			return nil, ErrFilterFull
+		}
+		return nil, err
+	}
+	return toBools(res), nil
+}
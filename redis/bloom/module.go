@@ -0,0 +1,327 @@
+// Package bloom 提供布隆过滤器、布谷鸟过滤器、Count-Min Sketch与Top-K等概率数据结构，支持Redis原生实现与RedisBloom模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-30 10:00:00
+package bloom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ModuleFilter 基于RedisBloom模块（BF.*命令）的布隆过滤器
+type ModuleFilter struct {
+	c    *Client
+	opts *Options
+}
+
+// Add 添加元素，过滤器不存在时按配置自动创建
+func (f *ModuleFilter) Add(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := f.opts.validate(); err != nil {
+		return nil, err
+	}
+	res, err := f.c.rdb.BFInsert(ctx, key, &redis.BFInsertOptions{
+		Capacity:   f.opts.Capacity,
+		Error:      f.opts.ErrorRate,
+		Expansion:  int64(f.opts.Expansion),
+		NonScaling: f.opts.Expansion == 0,
+	}, toArgs(items)...).Result()
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "full") {
+		return nil, ErrFilterFull
+	}
+	return res, err
+}
+
+// Exists 判断元素是否（可能）存在
+func (f *ModuleFilter) Exists(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return f.c.rdb.BFMExists(ctx, key, toArgs(items)...).Result()
+}
+
+// Info 返回过滤器的状态
+func (f *ModuleFilter) Info(ctx context.Context, key string) (*Info, error) {
+	info, err := f.c.rdb.BFInfo(ctx, key).Result()
+	if err != nil {
+		if isMissing(err) {
+			return &Info{}, nil
+		}
+		return nil, err
+	}
+	return &Info{Capacity: info.Capacity, Items: info.ItemsInserted, Layers: info.Filters}, nil
+}
+
+// Delete 删除过滤器
+func (f *ModuleFilter) Delete(ctx context.Context, key string) error {
+	return f.c.rdb.Del(ctx, key).Err()
+}
+
+// toArgs 将字符串切片转换为命令参数
+func toArgs(items []string) []interface{} {
+	args := make([]interface{}, len(items))
+	for i, item := range items {
+		args[i] = item
+	}
+	return args
+}
+
+// isMissing 判断是否为RedisBloom的key不存在错误
+func isMissing(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist")
+}
+
+// ModuleCuckoo 基于RedisBloom模块（CF.*命令）的布谷鸟过滤器
+// 与BITFIELD实现一致：每个桶4个槽位，踢出次数上限为MaxKicks，不扩容，满后返回ErrFilterFull
+type ModuleCuckoo struct {
+	c    *Client
+	opts *CuckooOptions
+}
+
+// Add 添加不存在的元素，过滤器不存在时按配置自动创建
+func (f *ModuleCuckoo) Add(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := f.opts.validate(); err != nil {
+		return nil, err
+	}
+
+	//1.过滤器不存在时先按配置创建，CF.INSERTNX自动创建时无法指定踢出次数与扩容倍数
+	insert := &redis.CFInsertOptions{NoCreate: true}
+	res, err := f.c.rdb.CFInsertNX(ctx, key, insert, toArgs(items)...).Result()
+	if err != nil && isMissing(err) {
+		if err = f.reserve(ctx, key); err != nil {
+			return nil, err
+		}
+		res, err = f.c.rdb.CFInsertNX(ctx, key, insert, toArgs(items)...).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	//2.-1表示过滤器已满
+	added := make([]bool, len(res))
+	for i, v := range res {
+		if v < 0 {
+			return nil, ErrFilterFull
+		}
+		added[i] = v == 1
+	}
+	return added, nil
+}
+
+// reserve 按配置创建不扩容的过滤器，并发创建时已存在的错误忽略
+// EXPANSION为0时CFReserveWithArgs不会发送该参数，因此直接使用Do
+func (f *ModuleCuckoo) reserve(ctx context.Context, key string) error {
+	err := f.c.rdb.Do(ctx, "CF.RESERVE", key, f.opts.Capacity, "BUCKETSIZE", cuckooBucketSize,
+		"MAXITERATIONS", f.opts.MaxKicks, "EXPANSION", 0).Err()
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "exists") {
+		return nil
+	}
+	return err
+}
+
+// Exists 判断元素是否（可能）存在
+func (f *ModuleCuckoo) Exists(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return f.c.rdb.CFMExists(ctx, key, toArgs(items)...).Result()
+}
+
+// Remove 删除元素，CF.DEL每次只能删除一个元素，因此在一个管道中逐个删除
+func (f *ModuleCuckoo) Remove(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	pipe := f.c.rdb.Pipeline()
+	cmds := make([]*redis.BoolCmd, len(items))
+	for i, item := range items {
+		cmds[i] = pipe.CFDel(ctx, key, item)
+	}
+	_, _ = pipe.Exec(ctx)
+
+	//1.过滤器不存在时视为未删除
+	removed := make([]bool, len(items))
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil && !isMissing(err) {
+			return nil, err
+		}
+		removed[i] = cmd.Val()
+	}
+	return removed, nil
+}
+
+// Info 返回过滤器的状态，容量按每层的桶数与扩容倍数累加
+func (f *ModuleCuckoo) Info(ctx context.Context, key string) (*Info, error) {
+	info, err := f.c.rdb.CFInfo(ctx, key).Result()
+	if err != nil {
+		if isMissing(err) {
+			return &Info{}, nil
+		}
+		return nil, err
+	}
+	res := &Info{Items: info.NumItemsInserted - info.NumItemsDeleted, Layers: info.NumFilters}
+	capacity := info.NumBuckets * info.BucketSize
+	for i := int64(0); i < info.NumFilters; i++ {
+		res.Capacity += capacity
+		capacity *= max(info.ExpansionRate, 1)
+	}
+	return res, nil
+}
+
+// Delete 删除过滤器
+func (f *ModuleCuckoo) Delete(ctx context.Context, key string) error {
+	return f.c.rdb.Del(ctx, key).Err()
+}
+
+// ModuleSketch 基于RedisBloom模块（CMS.*命令）的Count-Min Sketch
+type ModuleSketch struct {
+	c    *Client
+	opts *SketchOptions
+}
+
+// Incr 将每个元素的计数加1，返回增加后的估计值
+func (s *ModuleSketch) Incr(ctx context.Context, key string, items ...string) ([]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, 2*len(items))
+	for _, item := range items {
+		args = append(args, item, 1)
+	}
+	return s.incrBy(ctx, key, args)
+}
+
+// IncrBy 将元素的计数增加n，返回增加后的估计值
+func (s *ModuleSketch) IncrBy(ctx context.Context, key string, item string, n int64) (int64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("bloom: 增量必须大于0: %d", n)
+	}
+	res, err := s.incrBy(ctx, key, []interface{}{item, n})
+	if err != nil {
+		return 0, err
+	}
+	return res[0], nil
+}
+
+// incrBy 执行CMS.INCRBY，Sketch不存在时按配置创建后重试
+func (s *ModuleSketch) incrBy(ctx context.Context, key string, args []interface{}) ([]int64, error) {
+	if err := s.opts.validate(); err != nil {
+		return nil, err
+	}
+
+	//1.Sketch已存在时直接增加
+	res, err := s.c.rdb.CMSIncrBy(ctx, key, args...).Result()
+	if err == nil || !isMissing(err) {
+		return res, err
+	}
+
+	//2.创建Sketch，并发创建时忽略已存在错误
+	err = s.c.rdb.CMSInitByDim(ctx, key, s.opts.Width, s.opts.Depth).Err()
+	if err != nil && !strings.Contains(strings.ToLower(err.Error()), "exists") {
+		return nil, err
+	}
+	return s.c.rdb.CMSIncrBy(ctx, key, args...).Result()
+}
+
+// Query 返回元素的估计值
+func (s *ModuleSketch) Query(ctx context.Context, key string, items ...string) ([]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	res, err := s.c.rdb.CMSQuery(ctx, key, toArgs(items)...).Result()
+	if err != nil && isMissing(err) {
+		return make([]int64, len(items)), nil
+	}
+	return res, err
+}
+
+// Info 返回Sketch的状态
+func (s *ModuleSketch) Info(ctx context.Context, key string) (*SketchInfo, error) {
+	info, err := s.c.rdb.CMSInfo(ctx, key).Result()
+	if err != nil {
+		if isMissing(err) {
+			return &SketchInfo{}, nil
+		}
+		return nil, err
+	}
+	return &SketchInfo{Width: info.Width, Depth: info.Depth, Count: info.Count}, nil
+}
+
+// Delete 删除Sketch
+func (s *ModuleSketch) Delete(ctx context.Context, key string) error {
+	return s.c.rdb.Del(ctx, key).Err()
+}
+
+// topKDecay 模块Top-K（HeavyKeeper算法）的衰减系数，取RedisBloom的默认值
+const topKDecay = 0.9
+
+// ModuleTopK 基于RedisBloom模块（TOPK.*命令）的Top-K
+type ModuleTopK struct {
+	c    *Client
+	opts *TopKOptions
+}
+
+// Add 记录每个元素出现一次，Top-K不存在时按配置创建后重试
+func (t *ModuleTopK) Add(ctx context.Context, key string, items ...string) ([]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := t.opts.validate(); err != nil {
+		return nil, err
+	}
+
+	//1.Top-K已存在时直接添加
+	res, err := t.c.rdb.TopKAdd(ctx, key, toArgs(items)...).Result()
+	if err == nil || !isMissing(err) {
+		return res, err
+	}
+
+	//2.创建Top-K，并发创建时忽略已存在错误
+	err = t.c.rdb.TopKReserveWithOptions(ctx, key, t.opts.K, t.opts.Width, t.opts.Depth, topKDecay).Err()
+	if err != nil && !strings.Contains(strings.ToLower(err.Error()), "exists") {
+		return nil, err
+	}
+	return t.c.rdb.TopKAdd(ctx, key, toArgs(items)...).Result()
+}
+
+// Query 判断元素是否在Top-K中
+func (t *ModuleTopK) Query(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	res, err := t.c.rdb.TopKQuery(ctx, key, toArgs(items)...).Result()
+	if err != nil && isMissing(err) {
+		return make([]bool, len(items)), nil
+	}
+	return res, err
+}
+
+// List 返回Top-K中的元素及其估计次数
+func (t *ModuleTopK) List(ctx context.Context, key string) ([]TopKItem, error) {
+	counts, err := t.c.rdb.TopKListWithCount(ctx, key).Result()
+	if err != nil {
+		if isMissing(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	items := make([]TopKItem, 0, len(counts))
+	for item, count := range counts {
+		items = append(items, TopKItem{Item: item, Count: count})
+	}
+	sortTopK(items)
+	return items, nil
+}
+
+// Delete 删除Top-K
+func (t *ModuleTopK) Delete(ctx context.Context, key string) error {
+	return t.c.rdb.Del(ctx, key).Err()
+}
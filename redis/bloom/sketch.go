@@ -0,0 +1,235 @@
+// Package bloom 提供布隆过滤器、布谷鸟过滤器、Count-Min Sketch与Top-K等概率数据结构，支持Redis原生实现与RedisBloom模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-31 10:00:00
+package bloom
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BITFIELD实现的Count-Min Sketch保存在一个字符串中：
+//   - 偏移0处的i64为所有元素的计数之和
+//   - 其后为Depth行、每行Width个u32计数器，第i行的列为 (h1 + i·h2) mod Width
+// 计数器饱和而不回绕，元素的估计值为各行对应计数器的最小值，只会高估不会低估
+
+// maxCounters 计数器总数上限，使Sketch不超过Redis字符串的512MB上限
+const maxCounters = 1<<27 - 2
+
+// sketchLua 脚本开头共用：增加或读取元素在各行的计数器，返回最小值
+// KEYS[1]=Sketch ARGV[1]=Width ARGV[2]=Depth
+const sketchLua = `
+local w, d = tonumber(ARGV[1]), tonumber(ARGV[2])
+local function counters(h1, h2, n)
+	local args = {"BITFIELD", KEYS[1]}
+	if n then
+		table.insert(args, "OVERFLOW")
+		table.insert(args, "SAT")
+		table.insert(args, "INCRBY")
+		table.insert(args, "i64")
+		table.insert(args, 0)
+		table.insert(args, n)
+	end
+	for i = 0, d - 1 do
+		local offset = "#" .. (2 + i * w + (h1 + i * h2) % w)
+		if n then
+			table.insert(args, "INCRBY")
+			table.insert(args, "u32")
+			table.insert(args, offset)
+			table.insert(args, n)
+		else
+			table.insert(args, "GET")
+			table.insert(args, "u32")
+			table.insert(args, offset)
+		end
+	end
+	local r = redis.call(unpack(args))
+	local min = r[#r]
+	for j = #r - d + 1, #r do
+		if r[j] < min then
+			min = r[j]
+		end
+	end
+	return min
+end
+`
+
+// sketchIncrScript 增加元素的计数，返回增加后的估计值
+// ARGV[3..]为元素的h1、h2与增量
+var sketchIncrScript = redis.NewScript(sketchLua + `
+local result = {}
+for p = 3, #ARGV, 3 do
+	table.insert(result, counters(tonumber(ARGV[p]), tonumber(ARGV[p + 1]), tonumber(ARGV[p + 2])))
+end
+return result
+`)
+
+// sketchQueryScript 查询元素的估计值
+// ARGV[3..]为元素的h1、h2
+var sketchQueryScript = redis.NewScript(sketchLua + `
+local result = {}
+for p = 3, #ARGV, 2 do
+	table.insert(result, counters(tonumber(ARGV[p]), tonumber(ARGV[p + 1])))
+end
+return result
+`)
+
+// Sketch Count-Min Sketch，BITFIELD与RedisBloom两种实现的统一接口
+// 用固定的内存估计元素出现的次数，估计值不低于真实值，以1-e^-Depth的概率高估不超过 总数·e/Width
+type Sketch interface {
+	// Incr 将每个元素的计数加1，重复的元素计数多次，返回增加后的估计值
+	Incr(ctx context.Context, key string, items ...string) ([]int64, error)
+
+	// IncrBy 将元素的计数增加n，n必须大于0，返回增加后的估计值
+	IncrBy(ctx context.Context, key string, item string, n int64) (int64, error)
+
+	// Query 返回元素的估计值，Sketch不存在时均为0
+	Query(ctx context.Context, key string, items ...string) ([]int64, error)
+
+	// Info 返回Sketch的状态，Sketch不存在时各项为0
+	Info(ctx context.Context, key string) (*SketchInfo, error)
+
+	// Delete 删除整个Sketch
+	Delete(ctx context.Context, key string) error
+}
+
+// SketchOptions 定义了Count-Min Sketch的配置选项，只在Sketch首次写入时生效，之后必须保持不变
+type SketchOptions struct {
+	Width int64 // 每行的计数器个数，越大高估越少
+	Depth int64 // 行数，越大高估超出误差范围的概率越低
+}
+
+// DefaultSketchOptions 返回一个包含推荐默认值的Count-Min Sketch配置实例
+func DefaultSketchOptions() *SketchOptions {
+	return &SketchOptions{
+		Width: 2000, // 默认高估不超过总数的0.14%
+		Depth: 5,    // 默认超出误差范围的概率约为0.7%
+	}
+}
+
+// validate 验证配置
+func (o *SketchOptions) validate() error {
+	if o.Width <= 0 || o.Depth <= 0 {
+		return fmt.Errorf("%w: Width与Depth必须大于0", ErrInvalidOptions)
+	}
+	if o.Width > maxCounters/o.Depth {
+		return fmt.Errorf("%w: Width·Depth过大，超出单个字符串的大小上限", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// SketchInfo Count-Min Sketch状态
+type SketchInfo struct {
+	Width int64 // 每行的计数器个数
+	Depth int64 // 行数
+	Count int64 // 所有元素的计数之和
+}
+
+// BitFieldSketch 基于BITFIELD的Count-Min Sketch，单个计数器上限为2^32-1
+type BitFieldSketch struct {
+	c    *Client
+	opts *SketchOptions
+}
+
+// BitFieldSketch 返回基于BITFIELD的Count-Min Sketch，opts为nil时使用默认配置
+func (c *Client) BitFieldSketch(opts *SketchOptions) *BitFieldSketch {
+	if opts == nil {
+		opts = DefaultSketchOptions()
+	}
+	return &BitFieldSketch{c: c, opts: opts}
+}
+
+// ModuleSketch 返回基于RedisBloom模块的Count-Min Sketch，opts为nil时使用默认配置
+func (c *Client) ModuleSketch(opts *SketchOptions) *ModuleSketch {
+	if opts == nil {
+		opts = DefaultSketchOptions()
+	}
+	return &ModuleSketch{c: c, opts: opts}
+}
+
+// Sketch 检测服务端是否加载了RedisBloom模块，有则使用模块实现，否则使用BITFIELD实现
+func (c *Client) Sketch(ctx context.Context, opts *SketchOptions) (Sketch, error) {
+	ok, err := c.HasModule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return c.ModuleSketch(opts), nil
+	}
+	return c.BitFieldSketch(opts), nil
+}
+
+// Incr 将每个元素的计数加1，返回增加后的估计值
+func (s *BitFieldSketch) Incr(ctx context.Context, key string, items ...string) ([]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := s.opts.validate(); err != nil {
+		return nil, err
+	}
+	args := s.args(len(items), 3)
+	for _, item := range items {
+		h1, h2 := hash(item)
+		args = append(args, h1, h2, 1)
+	}
+	return sketchIncrScript.Run(ctx, s.c.rdb, []string{key}, args...).Int64Slice()
+}
+
+// IncrBy 将元素的计数增加n，返回增加后的估计值
+func (s *BitFieldSketch) IncrBy(ctx context.Context, key string, item string, n int64) (int64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("bloom: 增量必须大于0: %d", n)
+	}
+	if err := s.opts.validate(); err != nil {
+		return 0, err
+	}
+	h1, h2 := hash(item)
+	args := append(s.args(1, 3), h1, h2, n)
+	res, err := sketchIncrScript.Run(ctx, s.c.rdb, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+	return res[0], nil
+}
+
+// Query 返回元素的估计值
+func (s *BitFieldSketch) Query(ctx context.Context, key string, items ...string) ([]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := s.opts.validate(); err != nil {
+		return nil, err
+	}
+	args := s.args(len(items), 2)
+	for _, item := range items {
+		h1, h2 := hash(item)
+		args = append(args, h1, h2)
+	}
+	return sketchQueryScript.Run(ctx, s.c.rdb, []string{key}, args...).Int64Slice()
+}
+
+// Info 返回Sketch的状态
+func (s *BitFieldSketch) Info(ctx context.Context, key string) (*SketchInfo, error) {
+	pipe := s.c.rdb.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	count := pipe.BitField(ctx, key, "GET", "i64", 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if exists.Val() == 0 {
+		return &SketchInfo{}, nil
+	}
+	return &SketchInfo{Width: s.opts.Width, Depth: s.opts.Depth, Count: count.Val()[0]}, nil
+}
+
+// Delete 删除Sketch
+func (s *BitFieldSketch) Delete(ctx context.Context, key string) error {
+	return s.c.rdb.Del(ctx, key).Err()
+}
+
+// args 返回以Width与Depth开头的脚本参数，n个元素每个占per个参数
+func (s *BitFieldSketch) args(n, per int) []interface{} {
+	args := make([]interface{}, 0, 2+n*per)
+	return append(args, s.opts.Width, s.opts.Depth)
+}
@@ -0,0 +1,193 @@
+// Package bloom 提供布隆过滤器、布谷鸟过滤器、Count-Min Sketch与Top-K等概率数据结构，支持Redis原生实现与RedisBloom模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-31 10:00:00
+package bloom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BITFIELD实现的Top-K由以下key组成：
+//   - {key}:sketch  Count-Min Sketch，格式与BitFieldSketch相同
+//   - {key}:top     有序集合，保存当前的K个元素，分数为元素的估计次数
+// 每次添加元素时先增加它在Sketch中的计数，估计次数超过有序集合中的最小值时替换最小的元素
+
+// topKAddScript 记录元素出现一次，返回每个元素挤出的元素，没有挤出时为空字符串
+// KEYS[1]=Sketch KEYS[2]=有序集合 ARGV[3]=K ARGV[4..]为元素及其h1、h2
+var topKAddScript = redis.NewScript(sketchLua + `
+local k = tonumber(ARGV[3])
+local result = {}
+for p = 4, #ARGV, 3 do
+	local item = ARGV[p]
+	local count = counters(tonumber(ARGV[p + 1]), tonumber(ARGV[p + 2]), 1)
+	local expelled = ""
+	if redis.call("ZSCORE", KEYS[2], item) or redis.call("ZCARD", KEYS[2]) < k then
+		redis.call("ZADD", KEYS[2], count, item)
+	else
+		local min = redis.call("ZRANGE", KEYS[2], 0, 0, "WITHSCORES")
+		if count > tonumber(min[2]) then
+			redis.call("ZREM", KEYS[2], min[1])
+			redis.call("ZADD", KEYS[2], count, item)
+			expelled = min[1]
+		end
+	end
+	table.insert(result, expelled)
+end
+return result
+`)
+
+// TopK 出现次数最多的K个元素，BITFIELD与RedisBloom两种实现的统一接口
+// 次数为估计值，出现次数接近的元素可能被误判进出Top-K
+type TopK interface {
+	// Add 记录每个元素出现一次，返回每个元素挤出Top-K的元素，没有挤出时为空字符串
+	Add(ctx context.Context, key string, items ...string) ([]string, error)
+
+	// Query 判断元素是否在Top-K中
+	Query(ctx context.Context, key string, items ...string) ([]bool, error)
+
+	// List 返回Top-K中的元素及其估计次数，按次数从高到低排列
+	List(ctx context.Context, key string) ([]TopKItem, error)
+
+	// Delete 删除整个Top-K
+	Delete(ctx context.Context, key string) error
+}
+
+// TopKOptions 定义了Top-K的配置选项，只在Top-K首次写入时生效，之后必须保持不变
+type TopKOptions struct {
+	K     int64 // 保留的元素个数
+	Width int64 // 计数用的Sketch每行的计数器个数
+	Depth int64 // 计数用的Sketch的行数
+}
+
+// DefaultTopKOptions 返回一个包含推荐默认值的Top-K配置实例
+func DefaultTopKOptions() *TopKOptions {
+	return &TopKOptions{
+		K:     10,   // 默认保留10个元素
+		Width: 2000, // 默认每行2000个计数器
+		Depth: 5,    // 默认5行
+	}
+}
+
+// validate 验证配置
+func (o *TopKOptions) validate() error {
+	if o.K <= 0 {
+		return fmt.Errorf("%w: K必须大于0", ErrInvalidOptions)
+	}
+	return (&SketchOptions{Width: o.Width, Depth: o.Depth}).validate()
+}
+
+// TopKItem Top-K中的元素
+type TopKItem struct {
+	Item  string // 元素
+	Count int64  // 估计次数
+}
+
+// BitFieldTopK 基于BITFIELD与有序集合的Top-K
+type BitFieldTopK struct {
+	c    *Client
+	opts *TopKOptions
+}
+
+// BitFieldTopK 返回基于BITFIELD与有序集合的Top-K，opts为nil时使用默认配置
+func (c *Client) BitFieldTopK(opts *TopKOptions) *BitFieldTopK {
+	if opts == nil {
+		opts = DefaultTopKOptions()
+	}
+	return &BitFieldTopK{c: c, opts: opts}
+}
+
+// ModuleTopK 返回基于RedisBloom模块的Top-K，opts为nil时使用默认配置
+func (c *Client) ModuleTopK(opts *TopKOptions) *ModuleTopK {
+	if opts == nil {
+		opts = DefaultTopKOptions()
+	}
+	return &ModuleTopK{c: c, opts: opts}
+}
+
+// TopK 检测服务端是否加载了RedisBloom模块，有则使用模块实现，否则使用BITFIELD实现
+func (c *Client) TopK(ctx context.Context, opts *TopKOptions) (TopK, error) {
+	ok, err := c.HasModule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return c.ModuleTopK(opts), nil
+	}
+	return c.BitFieldTopK(opts), nil
+}
+
+// Add 原子地记录每个元素出现一次，返回每个元素挤出的元素
+func (t *BitFieldTopK) Add(ctx context.Context, key string, items ...string) ([]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if err := t.opts.validate(); err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, 0, 3+3*len(items))
+	args = append(args, t.opts.Width, t.opts.Depth, t.opts.K)
+	for _, item := range items {
+		h1, h2 := hash(item)
+		args = append(args, item, h1, h2)
+	}
+	return topKAddScript.Run(ctx, t.c.rdb, []string{t.sketchKey(key), t.topKey(key)}, args...).StringSlice()
+}
+
+// Query 判断元素是否在Top-K中
+func (t *BitFieldTopK) Query(ctx context.Context, key string, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	scores, err := t.c.rdb.ZMScore(ctx, t.topKey(key), items...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	//1.有序集合中的分数至少为1，不存在的元素分数为0
+	result := make([]bool, len(scores))
+	for i, score := range scores {
+		result[i] = score > 0
+	}
+	return result, nil
+}
+
+// List 返回Top-K中的元素及其估计次数
+func (t *BitFieldTopK) List(ctx context.Context, key string) ([]TopKItem, error) {
+	zs, err := t.c.rdb.ZRevRangeWithScores(ctx, t.topKey(key), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]TopKItem, len(zs))
+	for i, z := range zs {
+		items[i] = TopKItem{Item: z.Member.(string), Count: int64(z.Score)}
+	}
+	return items, nil
+}
+
+// Delete 删除Top-K的Sketch与有序集合
+func (t *BitFieldTopK) Delete(ctx context.Context, key string) error {
+	return t.c.rdb.Del(ctx, t.sketchKey(key), t.topKey(key)).Err()
+}
+
+// sketchKey 返回Sketch的key
+func (t *BitFieldTopK) sketchKey(key string) string {
+	return key + ":sketch"
+}
+
+// topKey 返回有序集合的key
+func (t *BitFieldTopK) topKey(key string) string {
+	return key + ":top"
+}
+
+// sortTopK 按次数从高到低、次数相同时按元素排序
+func sortTopK(items []TopKItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Item < items[j].Item
+	})
+}
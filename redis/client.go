@@ -7,32 +7,97 @@ import (
 
 	"github.com/redis/go-redis/v9"
 
+	activitypkg "go-redis-demo/redis/activity"
 	bitmappkg "go-redis-demo/redis/bitmap"
+	bloompkg "go-redis-demo/redis/bloom"
+	delayqueuepkg "go-redis-demo/redis/delayqueue"
+	feedpkg "go-redis-demo/redis/feed"
 	geopkg "go-redis-demo/redis/geo"
+	geofencepkg "go-redis-demo/redis/geofence"
+	graphpkg "go-redis-demo/redis/graph"
 	hashpkg "go-redis-demo/redis/hash"
 	hllpkg "go-redis-demo/redis/hll"
+	leaderboardpkg "go-redis-demo/redis/leaderboard"
 	listpkg "go-redis-demo/redis/list"
+	lockpkg "go-redis-demo/redis/lock"
+	queuepkg "go-redis-demo/redis/queue"
+	ratelimitpkg "go-redis-demo/redis/ratelimit"
+	semaphorepkg "go-redis-demo/redis/semaphore"
 	setpkg "go-redis-demo/redis/set"
 	stringpkg "go-redis-demo/redis/string"
+	tagindexpkg "go-redis-demo/redis/tagindex"
+	timeseriespkg "go-redis-demo/redis/timeseries"
 	zsetpkg "go-redis-demo/redis/zset"
 )
 
 // client 是统一的Redis客户端，提供所有数据类型操作的入口
 type client struct {
-	rdb    *redis.Client     // 底层go-redis客户端
-	String *stringpkg.Client // 字符串操作客户端
-	Hash   *hashpkg.Client   // 哈希操作客户端
-	List   *listpkg.Client   // 列表操作客户端
-	Set    *setpkg.Client    // 集合操作客户端
-	ZSet   *zsetpkg.Client   // 有序集合操作客户端
-	Geo    *geopkg.Client    // 地理位置操作客户端
-	Bitmap *bitmappkg.Client // 位图操作客户端
-	HLL    *hllpkg.Client    // HyperLogLog操作客户端
+	rdb         *redis.Client          // 底层go-redis客户端
+	String      *stringpkg.Client      // 字符串操作客户端
+	Hash        *hashpkg.Client        // 哈希操作客户端
+	List        *listpkg.Client        // 列表操作客户端
+	Set         *setpkg.Client         // 集合操作客户端
+	ZSet        *zsetpkg.Client        // 有序集合操作客户端
+	Geo         *geopkg.Client         // 地理位置操作客户端
+	Bitmap      *bitmappkg.Client      // 位图操作客户端
+	HLL         *hllpkg.Client         // HyperLogLog操作客户端
+	Lock        *lockpkg.Client        // 分布式锁客户端
+	Semaphore   *semaphorepkg.Client   // 分布式信号量客户端
+	RateLimit   *ratelimitpkg.Client   // 分布式限流客户端
+	Leaderboard *leaderboardpkg.Client // 排行榜客户端
+	DelayQueue  *delayqueuepkg.Client  // 延时任务队列客户端
+	Queue       *queuepkg.Client       // 可靠工作队列客户端
+	Feed        *feedpkg.Client        // 动态流客户端
+	TimeSeries  *timeseriespkg.Client  // 时间序列客户端
+	TagIndex    *tagindexpkg.Client    // 标签索引客户端
+	Graph       *graphpkg.Client       // 社交图谱客户端
+	Geofence    *geofencepkg.Client    // 电子围栏客户端
+	Bloom       *bloompkg.Client       // 布隆过滤器客户端
+	Activity    *activitypkg.Client    // 用户活跃客户端
 }
 
 // NewClient 创建一个新的Redis客户端实例
 func newClient(config *Config) (*client, error) {
 
+	//1.创建底层go-redis客户端并测试连接
+	rdb, err := newRDB(config)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.创建统一客户端，组装各个数据类型的操作客户端
+	redisClient := &client{
+		rdb:         rdb,
+		String:      stringpkg.New(rdb),
+		Hash:        hashpkg.New(rdb),
+		List:        listpkg.New(rdb),
+		Set:         setpkg.New(rdb),
+		ZSet:        zsetpkg.New(rdb),
+		Geo:         geopkg.New(rdb),
+		Bitmap:      bitmappkg.New(rdb),
+		HLL:         hllpkg.New(rdb),
+		Lock:        lockpkg.New(rdb),
+		Semaphore:   semaphorepkg.New(rdb),
+		RateLimit:   ratelimitpkg.New(rdb),
+		Leaderboard: leaderboardpkg.New(rdb),
+		DelayQueue:  delayqueuepkg.New(rdb),
+		Queue:       queuepkg.New(rdb),
+		Feed:        feedpkg.New(rdb),
+		TimeSeries:  timeseriespkg.New(rdb),
+		TagIndex:    tagindexpkg.New(rdb),
+		Graph:       graphpkg.New(rdb),
+		Geofence:    geofencepkg.New(rdb),
+		Bloom:       bloompkg.New(rdb),
+		Activity:    activitypkg.New(rdb),
+	}
+
+	//3.返回
+	return redisClient, nil
+}
+
+// newRDB 根据配置创建底层go-redis客户端，并测试连接
+func newRDB(config *Config) (*redis.Client, error) {
+
 	//1.创建底层go-redis客户端
 	rdb := redis.NewClient(&redis.Options{
 		Addr:         config.Addr,
@@ -49,24 +114,12 @@ func newClient(config *Config) (*client, error) {
 	//2.测试连接
 	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
 		return nil, err
 	}
 
-	//3.创建统一客户端，组装各个数据类型的操作客户端
-	redisClient := &client{
-		rdb:    rdb,
-		String: stringpkg.New(rdb),
-		Hash:   hashpkg.New(rdb),
-		List:   listpkg.New(rdb),
-		Set:    setpkg.New(rdb),
-		ZSet:   zsetpkg.New(rdb),
-		Geo:    geopkg.New(rdb),
-		Bitmap: bitmappkg.New(rdb),
-		HLL:    hllpkg.New(rdb),
-	}
-
-	//4.返回
-	return redisClient, nil
+	//3.返回
+	return rdb, nil
 }
 
 // Close 关闭Redis连接
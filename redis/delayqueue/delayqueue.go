@@ -0,0 +1,351 @@
+// Package delayqueue 提供基于Redis有序集合的延时任务队列封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-19 10:00:00
+package delayqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	hashpkg "go-redis-demo/redis/hash"
+	listpkg "go-redis-demo/redis/list"
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// ErrJobNotFound 任务不存在，或已被取出执行无法再取消或改期
+var ErrJobNotFound = errors.New("delayqueue: 任务不存在或已在执行中")
+
+// 每个队列由以下key组成：
+//   - {queue}:delayed    有序集合，成员为任务ID，分数为执行时间（毫秒）
+//   - {queue}:jobs       哈希，任务ID -> 任务内容
+//   - {queue}:ready      列表，已到期等待消费的任务ID
+//   - {queue}:processing 列表，已被消费者取出但尚未确认的任务ID
+//   - {queue}:reserved   哈希，任务ID -> 被取出的时间（毫秒），用于超时回收
+
+// promoteScript 将到期任务从延时有序集合原子地移动到就绪列表
+// KEYS[1]=delayed KEYS[2]=ready ARGV[1]=当前时间(毫秒) ARGV[2]=单次最多移动数量
+var promoteScript = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	redis.call("LPUSH", KEYS[2], id)
+end
+return #ids
+`)
+
+// recoverScript 回收超时未确认的任务，重新放回延时有序集合立即执行，保证至少一次投递
+// 消费者在取出任务与记录取出时间之间崩溃时没有取出时间，此时补记当前时间，下一轮超时后回收
+// KEYS[1]=processing KEYS[2]=reserved KEYS[3]=delayed ARGV[1]=当前时间(毫秒) ARGV[2]=可见性超时(毫秒)
+var recoverScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local n = 0
+for _, id in ipairs(redis.call("LRANGE", KEYS[1], 0, -1)) do
+	local at = tonumber(redis.call("HGET", KEYS[2], id))
+	if not at then
+		redis.call("HSET", KEYS[2], id, now)
+	elseif at + tonumber(ARGV[2]) < now then
+		redis.call("LREM", KEYS[1], 0, id)
+		redis.call("HDEL", KEYS[2], id)
+		redis.call("ZADD", KEYS[3], now, id)
+		n = n + 1
+	end
+end
+return n
+`)
+
+// cancelScript 取消尚未被取出的任务
+// KEYS[1]=delayed KEYS[2]=ready KEYS[3]=jobs ARGV[1]=任务ID
+var cancelScript = redis.NewScript(`
+local n = redis.call("ZREM", KEYS[1], ARGV[1]) + redis.call("LREM", KEYS[2], 0, ARGV[1])
+if n > 0 then
+	redis.call("HDEL", KEYS[3], ARGV[1])
+end
+return n
+`)
+
+// rescheduleScript 修改尚未被取出的任务的执行时间，已就绪的任务会被移回延时有序集合
+// KEYS[1]=delayed KEYS[2]=ready ARGV[1]=任务ID ARGV[2]=执行时间(毫秒)
+var rescheduleScript = redis.NewScript(`
+if redis.call("ZSCORE", KEYS[1], ARGV[1]) or redis.call("LREM", KEYS[2], 0, ARGV[1]) > 0 then
+	redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// ackScript 确认任务已处理完成，删除任务；任务已不在处理中列表时（如超时后被回收重新投递）不做任何修改
+// KEYS[1]=processing KEYS[2]=reserved KEYS[3]=jobs ARGV[1]=任务ID
+var ackScript = redis.NewScript(`
+local n = redis.call("LREM", KEYS[1], 0, ARGV[1])
+if n > 0 then
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	redis.call("HDEL", KEYS[3], ARGV[1])
+end
+return n
+`)
+
+// retryScript 任务处理失败，按指定执行时间重新放回延时有序集合
+// KEYS[1]=processing KEYS[2]=reserved KEYS[3]=delayed ARGV[1]=任务ID ARGV[2]=执行时间(毫秒)
+var retryScript = redis.NewScript(`
+local n = redis.call("LREM", KEYS[1], 0, ARGV[1])
+if n > 0 then
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	redis.call("ZADD", KEYS[3], ARGV[2], ARGV[1])
+end
+return n
+`)
+
+// Job 延时任务
+type Job struct {
+	ID      string // 任务ID
+	Queue   string // 所属队列
+	Payload string // 任务内容
+}
+
+// Client 延时任务队列客户端
+type Client struct {
+	rdb  *redis.Client
+	zset *zsetpkg.Client
+	list *listpkg.Client
+	hash *hashpkg.Client
+}
+
+// New 创建延时任务队列客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, zset: zsetpkg.New(rdb), list: listpkg.New(rdb), hash: hashpkg.New(rdb)}
+}
+
+// Schedule 提交一个在runAt执行的任务
+// 参数:
+//   - ctx: 上下文
+//   - queue: 队列名称
+//   - payload: 任务内容
+//   - runAt: 执行时间
+//
+// 返回:
+//   - 任务ID，可用于取消或改期
+//   - 错误信息
+func (c *Client) Schedule(ctx context.Context, queue, payload string, runAt time.Time) (string, error) {
+
+	//1.先保存任务内容，保证任务到期时一定能读取到
+	id := newJobID()
+	if _, err := c.hash.HSet(ctx, jobsKey(queue), id, payload); err != nil {
+		return "", err
+	}
+
+	//2.按执行时间加入延时有序集合
+	if _, err := c.zset.ZAdd(ctx, delayedKey(queue), redis.Z{Score: float64(runAt.UnixMilli()), Member: id}); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ScheduleAfter 提交一个在delay之后执行的任务
+func (c *Client) ScheduleAfter(ctx context.Context, queue, payload string, delay time.Duration) (string, error) {
+	return c.Schedule(ctx, queue, payload, time.Now().Add(delay))
+}
+
+// Cancel 取消尚未被取出的任务
+func (c *Client) Cancel(ctx context.Context, queue, id string) error {
+	keys := []string{delayedKey(queue), readyKey(queue), jobsKey(queue)}
+	n, err := cancelScript.Run(ctx, c.rdb, keys, id).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Reschedule 修改尚未被取出的任务的执行时间
+func (c *Client) Reschedule(ctx context.Context, queue, id string, runAt time.Time) error {
+	keys := []string{delayedKey(queue), readyKey(queue)}
+	n, err := rescheduleScript.Run(ctx, c.rdb, keys, id, runAt.UnixMilli()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Poll 将到期任务移动到就绪列表，并回收超时未确认的任务
+// 参数:
+//   - ctx: 上下文
+//   - queue: 队列名称
+//   - batch: 单次最多移动的任务数
+//   - visibility: 可见性超时，被取出超过该时间仍未确认的任务会被重新投递
+//
+// 返回:
+//   - 移动到就绪列表的任务数
+//   - 错误信息
+func (c *Client) Poll(ctx context.Context, queue string, batch int64, visibility time.Duration) (int64, error) {
+
+	//1.回收超时未确认的任务
+	now := time.Now().UnixMilli()
+	keys := []string{processingKey(queue), reservedKey(queue), delayedKey(queue)}
+	if err := recoverScript.Run(ctx, c.rdb, keys, now, visibility.Milliseconds()).Err(); err != nil {
+		return 0, err
+	}
+
+	//2.移动到期任务
+	return promoteScript.Run(ctx, c.rdb, []string{delayedKey(queue), readyKey(queue)}, now, batch).Int64()
+}
+
+// RunPoller 按interval周期性执行Poll，直到上下文结束
+func (c *Client) RunPoller(ctx context.Context, queue string, interval, visibility time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Poll(ctx, queue, 100, visibility); err != nil && ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reserve 阻塞地取出一个就绪任务，任务会被移动到处理中列表直到确认
+// 任务内容已不存在（如已被确认或取消）的任务ID会被丢弃，并在剩余时间内继续等待下一个任务
+// 参数:
+//   - ctx: 上下文
+//   - queue: 队列名称
+//   - timeout: 最长阻塞时间，为0时一直阻塞
+//
+// 返回:
+//   - 任务，超时无任务时返回nil
+//   - 错误信息
+func (c *Client) Reserve(ctx context.Context, queue string, timeout time.Duration) (*Job, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+
+		//1.阻塞取出任务ID并移动到处理中列表
+		wait := timeout
+		if timeout > 0 {
+			if wait = time.Until(deadline); wait <= 0 {
+				return nil, nil
+			}
+		}
+		id, err := c.list.BRPopLPush(ctx, readyKey(queue), processingKey(queue), wait)
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		//2.记录取出时间，用于超时回收
+		if _, err = c.hash.HSet(ctx, reservedKey(queue), id, time.Now().UnixMilli()); err != nil {
+			return nil, err
+		}
+
+		//3.读取任务内容
+		payload, err := c.hash.HGet(ctx, jobsKey(queue), id)
+		if err == nil {
+			return &Job{ID: id, Queue: queue, Payload: payload}, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+
+		//4.任务内容不存在，从处理中列表与取出时间中移除该ID，避免被反复回收投递
+		_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LRem(ctx, processingKey(queue), 0, id)
+			pipe.HDel(ctx, reservedKey(queue), id)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Ack 确认任务已处理完成，任务已不在处理中时（如超时后被重新投递）返回ErrJobNotFound
+func (c *Client) Ack(ctx context.Context, job *Job) error {
+	keys := []string{processingKey(job.Queue), reservedKey(job.Queue), jobsKey(job.Queue)}
+	n, err := ackScript.Run(ctx, c.rdb, keys, job.ID).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Retry 任务处理失败，在runAt重新执行
+func (c *Client) Retry(ctx context.Context, job *Job, runAt time.Time) error {
+	keys := []string{processingKey(job.Queue), reservedKey(job.Queue), delayedKey(job.Queue)}
+	n, err := retryScript.Run(ctx, c.rdb, keys, job.ID, runAt.UnixMilli()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Pending 返回尚未到期的任务数
+func (c *Client) Pending(ctx context.Context, queue string) (int64, error) {
+	return c.zset.ZCard(ctx, delayedKey(queue))
+}
+
+// Ready 返回已到期等待消费的任务数
+func (c *Client) Ready(ctx context.Context, queue string) (int64, error) {
+	return c.list.LLen(ctx, readyKey(queue))
+}
+
+// RunAt 返回尚未到期的任务的执行时间
+func (c *Client) RunAt(ctx context.Context, queue, id string) (time.Time, error) {
+	score, err := c.rdb.ZScore(ctx, delayedKey(queue), id).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, ErrJobNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(int64(score)), nil
+}
+
+// delayedKey 返回延时有序集合的key
+func delayedKey(queue string) string {
+	return queue + ":delayed"
+}
+
+// jobsKey 返回任务内容哈希的key
+func jobsKey(queue string) string {
+	return queue + ":jobs"
+}
+
+// readyKey 返回就绪列表的key
+func readyKey(queue string) string {
+	return queue + ":ready"
+}
+
+// processingKey 返回处理中列表的key
+func processingKey(queue string) string {
+	return queue + ":processing"
+}
+
+// reservedKey 返回取出时间哈希的key
+func reservedKey(queue string) string {
+	return queue + ":reserved"
+}
+
+// newJobID 生成随机的任务ID
+func newJobID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return strconv.FormatInt(time.Now().UnixMilli(), 36) + hex.EncodeToString(b)
+}
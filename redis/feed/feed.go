@@ -0,0 +1,296 @@
+// Package feed 提供基于Redis列表的定长动态流（最近N条动态）封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-21 10:00:00
+package feed
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidCap 动态流容量不合法
+var ErrInvalidCap = errors.New("feed: 容量必须大于0")
+
+// 每个动态流由以下key组成：
+//   - {feed}         列表，最新的动态在左端，元素格式为 "序号:时间(毫秒):内容"
+//   - {feed}:expiry  有序集合，成员为列表元素，分数为过期时间（毫秒），仅记录设置了过期时间的动态
+//   - {feed}:seq     字符串，自增序号，作为动态ID与分页游标
+// 序号单调递增且不随推入、裁剪改变，以序号作为游标分页时新推入的动态不会导致重复或遗漏
+
+// purgeLua 删除已过期的动态，各脚本开头共用
+// KEYS[1]=列表 KEYS[2]=过期有序集合 now=当前时间(毫秒)
+const purgeLua = `
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+for _, e in ipairs(redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", now)) do
+	redis.call("LREM", KEYS[1], 1, e)
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now)
+`
+
+// pushScript 推入动态并裁剪到容量上限，被裁剪掉的动态同时从过期有序集合中移除
+// KEYS[1]=列表 KEYS[2]=过期有序集合 KEYS[3]=序号
+// ARGV[1]=容量 ARGV[2]=动态过期时间(毫秒，0为不过期) ARGV[3]=key过期时间(毫秒，0为不过期) ARGV[4..]=动态内容
+// 返回最后一条动态的序号
+var pushScript = redis.NewScript(purgeLua + `
+local cap = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+local keyTTL = tonumber(ARGV[3])
+local seq = 0
+for i = 4, #ARGV do
+	seq = redis.call("INCR", KEYS[3])
+	local e = seq .. ":" .. now .. ":" .. ARGV[i]
+	redis.call("LPUSH", KEYS[1], e)
+	if ttl > 0 then
+		redis.call("ZADD", KEYS[2], now + ttl, e)
+	end
+end
+local removed = redis.call("LRANGE", KEYS[1], cap, -1)
+if #removed > 0 then
+	redis.call("LTRIM", KEYS[1], 0, cap - 1)
+	for i = 1, #removed, 1000 do
+		redis.call("ZREM", KEYS[2], unpack(removed, i, math.min(i + 999, #removed)))
+	end
+end
+if keyTTL > 0 then
+	for i = 1, 3 do
+		redis.call("PEXPIRE", KEYS[i], keyTTL)
+	end
+end
+return seq
+`)
+
+// pageScript 删除已过期的动态后，返回序号小于游标的最多size+1条动态，多取的一条用于判断是否还有下一页
+// KEYS[1]=列表 KEYS[2]=过期有序集合 ARGV[1]=游标(0为从最新开始) ARGV[2]=每页数量
+var pageScript = redis.NewScript(purgeLua + `
+local cursor = tonumber(ARGV[1])
+local size = tonumber(ARGV[2])
+local res = {}
+for _, e in ipairs(redis.call("LRANGE", KEYS[1], 0, -1)) do
+	local seq = tonumber(string.match(e, "^(%d+):"))
+	if cursor == 0 or seq < cursor then
+		res[#res + 1] = e
+		if #res > size then
+			break
+		end
+	end
+end
+return res
+`)
+
+// lenScript 删除已过期的动态后返回动态数
+// KEYS[1]=列表 KEYS[2]=过期有序集合
+var lenScript = redis.NewScript(purgeLua + `
+return redis.call("LLEN", KEYS[1])
+`)
+
+// Options 定义了动态流的配置选项
+type Options struct {
+	Cap         int64         // 容量上限，只保留最新的Cap条动态
+	TTL         time.Duration // 单条动态的过期时间，为0时不过期
+	KeyTTL      time.Duration // 整个动态流的过期时间，每次推入时续期，为0时不过期，用于清理不活跃用户的动态流
+	Parallelism int           // 扇出写入时的最大并发数
+}
+
+// DefaultOptions 返回一个包含推荐默认值的动态流配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		Cap:         100, // 默认保留最新100条
+		Parallelism: 16,  // 默认扇出并发16
+	}
+}
+
+// Item 动态
+type Item struct {
+	Seq     int64     // 序号，动态流内唯一且单调递增
+	Time    time.Time // 推入时间
+	Payload string    // 内容
+}
+
+// Page 一页动态
+type Page struct {
+	Items []*Item // 动态，按从新到旧排列
+	Next  int64   // 下一页的游标，为0时表示没有更多动态
+}
+
+// Client 动态流客户端
+type Client struct {
+	rdb *redis.Client
+}
+
+// New 创建动态流客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb}
+}
+
+// Feed 获取key对应的动态流，opts为nil时使用默认配置
+func (c *Client) Feed(key string, opts *Options) *Feed {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &Feed{c: c, key: key, opts: opts}
+}
+
+// FanOut 写扩散：将动态推入多个动态流（如所有粉丝的收件箱），并发数不超过opts.Parallelism
+// 参数:
+//   - ctx: 上下文
+//   - keys: 目标动态流的key
+//   - opts: 动态流配置，为nil时使用默认配置
+//   - payloads: 动态内容
+//
+// 返回:
+//   - 第一个写入失败的错误，其余动态流仍会继续写入
+func (c *Client) FanOut(ctx context.Context, keys []string, opts *Options, payloads ...string) error {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	parallelism := max(opts.Parallelism, 1)
+
+	//1.通过信号量通道限制并发数
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, parallelism)
+	)
+	for _, key := range keys {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			//2.逐个写入，记录第一个错误
+			if _, err := c.Feed(key, opts).Push(ctx, payloads...); err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		}(key)
+	}
+
+	//3.等待所有写入完成
+	wg.Wait()
+	return firstErr
+}
+
+// Feed 定长动态流
+type Feed struct {
+	c    *Client
+	key  string
+	opts *Options
+}
+
+// Key 返回动态流的key
+func (f *Feed) Key() string {
+	return f.key
+}
+
+// Push 推入动态，使用配置中的单条动态过期时间
+// 返回:
+//   - 最后一条动态的序号
+//   - 错误信息
+func (f *Feed) Push(ctx context.Context, payloads ...string) (int64, error) {
+	return f.PushWithTTL(ctx, f.opts.TTL, payloads...)
+}
+
+// PushWithTTL 推入动态并指定单条动态的过期时间，推入与裁剪在同一个脚本中原子执行
+// 参数:
+//   - ctx: 上下文
+//   - ttl: 动态过期时间，为0时不过期
+//   - payloads: 动态内容，按顺序推入，最后一条最新
+//
+// 返回:
+//   - 最后一条动态的序号
+//   - 错误信息
+func (f *Feed) PushWithTTL(ctx context.Context, ttl time.Duration, payloads ...string) (int64, error) {
+	if f.opts.Cap <= 0 {
+		return 0, ErrInvalidCap
+	}
+	if len(payloads) == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, 0, len(payloads)+3)
+	args = append(args, f.opts.Cap, ttl.Milliseconds(), f.opts.KeyTTL.Milliseconds())
+	for _, p := range payloads {
+		args = append(args, p)
+	}
+	keys := []string{f.key, f.expiryKey(), f.seqKey()}
+	return pushScript.Run(ctx, f.c.rdb, keys, args...).Int64()
+}
+
+// Page 按游标分页获取动态
+// 参数:
+//   - ctx: 上下文
+//   - cursor: 游标，首页传0，之后传上一页返回的Next
+//   - size: 每页数量
+//
+// 返回:
+//   - 一页动态
+//   - 错误信息
+func (f *Feed) Page(ctx context.Context, cursor int64, size int64) (*Page, error) {
+	if size <= 0 {
+		return &Page{}, nil
+	}
+
+	//1.取出size+1条动态
+	entries, err := pageScript.Run(ctx, f.c.rdb, []string{f.key, f.expiryKey()}, cursor, size).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	//2.解析动态，多取出的一条说明还有下一页
+	page := &Page{}
+	for i, e := range entries {
+		if int64(i) == size {
+			page.Next = page.Items[len(page.Items)-1].Seq
+			break
+		}
+		page.Items = append(page.Items, parseItem(e))
+	}
+	return page, nil
+}
+
+// Len 返回未过期的动态数
+func (f *Feed) Len(ctx context.Context) (int64, error) {
+	return lenScript.Run(ctx, f.c.rdb, []string{f.key, f.expiryKey()}).Int64()
+}
+
+// Clear 清空动态流，序号保留，已发出的游标仍然有效
+func (f *Feed) Clear(ctx context.Context) error {
+	return f.c.rdb.Del(ctx, f.key, f.expiryKey()).Err()
+}
+
+// expiryKey 返回过期有序集合的key
+func (f *Feed) expiryKey() string {
+	return f.key + ":expiry"
+}
+
+// seqKey 返回序号的key
+func (f *Feed) seqKey() string {
+	return f.key + ":seq"
+}
+
+// parseItem 解析 "序号:时间(毫秒):内容" 格式的列表元素
+func parseItem(e string) *Item {
+	parts := strings.SplitN(e, ":", 3)
+	item := &Item{Payload: e}
+	if len(parts) != 3 {
+		return item
+	}
+	item.Seq, _ = strconv.ParseInt(parts[0], 10, 64)
+	ms, _ := strconv.ParseInt(parts[1], 10, 64)
+	item.Time = time.UnixMilli(ms)
+	item.Payload = parts[2]
+	return item
+}
@@ -0,0 +1,166 @@
+// Package geo 提供Redis地理位置操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-29 10:00:00
+package geo
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// csvColumns CSV表头中各字段的下标，不存在时为-1
+type csvColumns struct {
+	name, lon, lat, wkt int
+	props               map[int]string
+}
+
+// ImportCSV 从带表头的CSV批量导入位置
+// 表头不区分大小写：名称列为name或id，坐标列为lon/lng/longitude与lat/latitude，
+// 或者一个wkt列，内容为"POINT (经度 纬度)"；其余列作为属性写入属性哈希
+// 参数:
+//   - ctx: 上下文
+//   - key: 键名
+//   - r: CSV数据
+//   - opts: 导入选项，为nil时使用默认配置，NameProperty不适用于CSV
+//
+// 返回:
+//   - 导入结果，缺少名称、坐标无法解析或超出范围的行被拒绝，不影响其余行
+//   - 错误信息，表头缺少名称列或坐标列、CSV格式错误时返回错误
+func (c *Client) ImportCSV(ctx context.Context, key string, r io.Reader, opts *ImportOptions) (*ImportResult, error) {
+
+	//1.解析表头
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	cols, err := parseCSVHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.逐行转换并按批写入
+	if opts == nil {
+		opts = DefaultImportOptions()
+	}
+	imp := c.newImporter(key, opts)
+	for i := 0; ; i++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return imp.res, err
+		}
+		p, err := cols.toPoint(record)
+		if err != nil {
+			imp.reject(i, p.loc, err)
+			continue
+		}
+		p.index = i
+		if err = imp.add(ctx, p); err != nil {
+			return imp.res, err
+		}
+	}
+
+	//3.写入剩余的位置
+	return imp.finish(ctx)
+}
+
+// parseCSVHeader 解析表头，确定名称列、坐标列与属性列
+func parseCSVHeader(header []string) (*csvColumns, error) {
+	cols := &csvColumns{name: -1, lon: -1, lat: -1, wkt: -1, props: make(map[int]string)}
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "name", "id":
+			cols.name = i
+		case "lon", "lng", "longitude":
+			cols.lon = i
+		case "lat", "latitude":
+			cols.lat = i
+		case "wkt":
+			cols.wkt = i
+		default:
+			cols.props[i] = h
+		}
+	}
+	if cols.name < 0 {
+		return nil, errors.New("geo: CSV表头缺少name列")
+	}
+	if cols.wkt < 0 && (cols.lon < 0 || cols.lat < 0) {
+		return nil, errors.New("geo: CSV表头缺少经纬度列或wkt列")
+	}
+	return cols, nil
+}
+
+// toPoint 将一行转换为位置，wkt列优先于经纬度列
+func (cols *csvColumns) toPoint(record []string) (point, error) {
+	p := point{loc: &redis.GeoLocation{}, props: make(map[string]string, len(cols.props))}
+	field := func(i int) string {
+		if i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	//1.名称
+	if p.loc.Name = field(cols.name); p.loc.Name == "" {
+		return p, fmt.Errorf("%w: 缺少名称", ErrInvalidFeature)
+	}
+
+	//2.坐标
+	var err error
+	if cols.wkt >= 0 {
+		p.loc.Longitude, p.loc.Latitude, err = parseWKTPoint(field(cols.wkt))
+	} else {
+		p.loc.Longitude, p.loc.Latitude, err = parseLonLat(field(cols.lon), field(cols.lat))
+	}
+	if err != nil {
+		return p, fmt.Errorf("%w: %s: %v", ErrInvalidFeature, p.loc.Name, err)
+	}
+
+	//3.属性，空值不写入
+	for i, name := range cols.props {
+		if v := field(i); v != "" {
+			p.props[name] = v
+		}
+	}
+	return p, nil
+}
+
+// parseWKTPoint 解析WKT格式的点，如 POINT (116.39 39.91)
+func parseWKTPoint(s string) (float64, float64, error) {
+	upper := strings.ToUpper(s)
+	if !strings.HasPrefix(upper, "POINT") {
+		return 0, 0, fmt.Errorf("不是WKT点: %q", s)
+	}
+	body := strings.TrimSpace(s[len("POINT"):])
+	if !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") {
+		return 0, 0, fmt.Errorf("不是WKT点: %q", s)
+	}
+	coords := strings.Fields(body[1 : len(body)-1])
+	if len(coords) < 2 {
+		return 0, 0, fmt.Errorf("不是WKT点: %q", s)
+	}
+	return parseLonLat(coords[0], coords[1])
+}
+
+// parseLonLat 解析经纬度字符串
+func parseLonLat(lon, lat string) (float64, float64, error) {
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("经度%q无法解析", lon)
+	}
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("纬度%q无法解析", lat)
+	}
+	return longitude, latitude, nil
+}
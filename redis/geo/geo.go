@@ -4,11 +4,20 @@ package geo
 
 import (
 	"context"
-	"errors"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Unit 距离单位
+type Unit string
+
+const (
+	M  Unit = "m"  // 米
+	KM Unit = "km" // 千米
+	MI Unit = "mi" // 英里
+	FT Unit = "ft" // 英尺
+)
+
 // Client Redis地理位置操作客户端
 type Client struct {
 	rdb *redis.Client
@@ -29,8 +38,15 @@ func New(rdb *redis.Client) *Client {
 //
 // 返回:
 //   - 新添加的位置数量
-//   - 错误信息
+//   - 错误信息，经纬度超出范围时返回ErrInvalidCoordinate
 func (c *Client) GeoAdd(ctx context.Context, key string, longitude, latitude float64, member string) (int64, error) {
+
+	// 1.验证经纬度
+	if err := ValidateCoordinate(longitude, latitude); err != nil {
+		return 0, err
+	}
+
+	// 2.添加位置
 	return c.rdb.GeoAdd(ctx, key, &redis.GeoLocation{
 		Longitude: longitude,
 		Latitude:  latitude,
@@ -38,7 +54,7 @@ func (c *Client) GeoAdd(ctx context.Context, key string, longitude, latitude flo
 	}).Result()
 }
 
-// GeoBatchAdd 批量添加地理空间位置
+// GeoBatchAdd 批量添加地理空间位置，经纬度超出范围的位置被跳过并在返回值中报告，其余位置正常添加
 // 参数:
 //   - ctx: 上下文
 //   - key: 键名
@@ -46,9 +62,27 @@ func (c *Client) GeoAdd(ctx context.Context, key string, longitude, latitude flo
 //
 // 返回:
 //   - 新添加的位置数量
+//   - 被拒绝的位置及原因，全部有效时为nil
 //   - 错误信息
-func (c *Client) GeoBatchAdd(ctx context.Context, key string, locations ...*redis.GeoLocation) (int64, error) {
-	return c.rdb.GeoAdd(ctx, key, locations...).Result()
+func (c *Client) GeoBatchAdd(ctx context.Context, key string, locations ...*redis.GeoLocation) (int64, []Rejected, error) {
+
+	// 1.筛选有效的位置
+	valid := make([]*redis.GeoLocation, 0, len(locations))
+	var rejected []Rejected
+	for i, loc := range locations {
+		if err := validateLocation(loc); err != nil {
+			rejected = append(rejected, Rejected{Index: i, Location: loc, Err: err})
+			continue
+		}
+		valid = append(valid, loc)
+	}
+	if len(valid) == 0 {
+		return 0, rejected, nil
+	}
+
+	// 2.添加有效的位置
+	n, err := c.rdb.GeoAdd(ctx, key, valid...).Result()
+	return n, rejected, err
 }
 
 // GeoPos 从key里返回所有给定位置元素的位置（经度和纬度）
@@ -70,20 +104,21 @@ func (c *Client) GeoPos(ctx context.Context, key string, members ...string) ([]*
 //   - key: 键名
 //   - member1: 第一个位置名称
 //   - member2: 第二个位置名称
-//   - unit: 距离单位，可选值："m"(米)、"km"(千米)、"mi"(英里)、"ft"(英尺)
+//   - unit: 距离单位
 //
 // 返回:
 //   - 两个位置之间的距离
-//   - 错误信息
-func (c *Client) GeoDist(ctx context.Context, key, member1, member2, unit string) (float64, error) {
+//   - 错误信息，单位无效时返回ErrInvalidUnit，任一位置不存在时返回ErrMemberNotFound
+func (c *Client) GeoDist(ctx context.Context, key, member1, member2 string, unit Unit) (float64, error) {
 
 	// 1.验证单位是否有效
-	if unit != "m" && unit != "km" && unit != "mi" && unit != "ft" {
-		return 0, errors.New("无效的距离单位，必须是m、km、mi或ft之一")
+	if err := unit.validate(); err != nil {
+		return 0, err
 	}
 
 	// 2.返回两个给定位置之间的距离
-	return c.rdb.GeoDist(ctx, key, member1, member2, unit).Result()
+	dist, err := c.rdb.GeoDist(ctx, key, member1, member2, string(unit)).Result()
+	return dist, memberErr(err)
 }
 
 // GeoHash 返回一个或多个位置元素的Geohash表示
@@ -99,73 +134,6 @@ func (c *Client) GeoHash(ctx context.Context, key string, members ...string) ([]
 	return c.rdb.GeoHash(ctx, key, members...).Result()
 }
 
-// GeoRadius 以给定的经纬度为中心，返回键包含的位置元素当中，与中心的距离不超过给定最大距离的所有位置元素
-// 参数:
-//   - ctx: 上下文
-//   - key: 键名
-//   - longitude: 中心点经度
-//   - latitude: 中心点纬度
-//   - radius: 距离
-//   - unit: 距离单位，可选值："m"(米)、"km"(千米)、"mi"(英里)、"ft"(英尺)
-//   - withCoord: 是否返回位置的经纬度
-//   - withDist: 是否返回位置与中心点的距离
-//   - withHash: 是否返回位置的Geohash表示
-//   - count: 返回的位置数量限制，0表示不限制
-//
-// 返回:
-//   - 位置信息列表
-//   - 错误信息
-func (c *Client) GeoRadius(ctx context.Context, key string, longitude, latitude float64, radius float64, unit string, withCoord, withDist, withHash bool, count int) ([]redis.GeoLocation, error) {
-
-	// 1.验证单位是否有效
-	if unit != "m" && unit != "km" && unit != "mi" && unit != "ft" {
-		return nil, errors.New("无效的距离单位，必须是m、km、mi或ft之一")
-	}
-
-	// 2.以给定的经纬度为中心，返回键包含的位置元素当中，与中心的距离不超过给定最大距离的所有位置元素
-	return c.rdb.GeoRadius(ctx, key, longitude, latitude, &redis.GeoRadiusQuery{
-		Radius:      radius,
-		Unit:        unit,
-		WithCoord:   withCoord,
-		WithDist:    withDist,
-		WithGeoHash: withHash,
-		Count:       count,
-	}).Result()
-}
-
-// GeoRadiusByMember 以给定的位置元素为中心，返回键包含的位置元素当中，与中心的距离不超过给定最大距离的所有位置元素
-// 参数:
-//   - ctx: 上下文
-//   - key: 键名
-//   - member: 中心点位置名称
-//   - radius: 距离
-//   - unit: 距离单位，可选值："m"(米)、"km"(千米)、"mi"(英里)、"ft"(英尺)
-//   - withCoord: 是否返回位置的经纬度
-//   - withDist: 是否返回位置与中心点的距离
-//   - withHash: 是否返回位置的Geohash表示
-//   - count: 返回的位置数量限制，0表示不限制
-//
-// 返回:
-//   - 位置信息列表
-//   - 错误信息
-func (c *Client) GeoRadiusByMember(ctx context.Context, key, member string, radius float64, unit string, withCoord, withDist, withHash bool, count int) ([]redis.GeoLocation, error) {
-
-	// 1.验证单位是否有效
-	if unit != "m" && unit != "km" && unit != "mi" && unit != "ft" {
-		return nil, errors.New("无效的距离单位，必须是m、km、mi或ft之一")
-	}
-
-	// 2.以给定的位置元素为中心，返回键包含的位置元素当中，与中心的距离不超过给定最大距离的所有位置元素
-	return c.rdb.GeoRadiusByMember(ctx, key, member, &redis.GeoRadiusQuery{
-		Radius:      radius,
-		Unit:        unit,
-		WithCoord:   withCoord,
-		WithDist:    withDist,
-		WithGeoHash: withHash,
-		Count:       count,
-	}).Result()
-}
-
 // GeoSearch 使用GEOSEARCH命令搜索地理位置
 // 参数:
 //   - ctx: 上下文
@@ -174,9 +142,10 @@ func (c *Client) GeoRadiusByMember(ctx context.Context, key, member string, radi
 //
 // 返回:
 //   - 位置名称列表
-//   - 错误信息
+//   - 错误信息，搜索中心的位置元素不存在时返回ErrMemberNotFound
 func (c *Client) GeoSearch(ctx context.Context, key string, q *redis.GeoSearchQuery) ([]string, error) {
-	return c.rdb.GeoSearch(ctx, key, q).Result()
+	members, err := c.rdb.GeoSearch(ctx, key, q).Result()
+	return members, memberErr(err)
 }
 
 // GeoSearchLocation 使用GEOSEARCH命令搜索地理位置，返回详细信息
@@ -187,9 +156,10 @@ func (c *Client) GeoSearch(ctx context.Context, key string, q *redis.GeoSearchQu
 //
 // 返回:
 //   - 位置详细信息列表
-//   - 错误信息
+//   - 错误信息，搜索中心的位置元素不存在时返回ErrMemberNotFound
 func (c *Client) GeoSearchLocation(ctx context.Context, key string, q *redis.GeoSearchLocationQuery) ([]redis.GeoLocation, error) {
-	return c.rdb.GeoSearchLocation(ctx, key, q).Result()
+	locations, err := c.rdb.GeoSearchLocation(ctx, key, q).Result()
+	return locations, memberErr(err)
 }
 
 // GeoSearchStore 使用GEOSEARCHSTORE命令搜索地理位置并存储结果
@@ -201,7 +171,8 @@ func (c *Client) GeoSearchLocation(ctx context.Context, key string, q *redis.Geo
 //
 // 返回:
 //   - 存储的位置数量
-//   - 错误信息
+//   - 错误信息，搜索中心的位置元素不存在时返回ErrMemberNotFound
 func (c *Client) GeoSearchStore(ctx context.Context, key, store string, q *redis.GeoSearchStoreQuery) (int64, error) {
-	return c.rdb.GeoSearchStore(ctx, key, store, q).Result()
+	n, err := c.rdb.GeoSearchStore(ctx, key, store, q).Result()
+	return n, memberErr(err)
 }
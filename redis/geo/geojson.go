@@ -0,0 +1,347 @@
+// Package geo 提供Redis地理位置操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-29 10:00:00
+package geo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidFeature 导入的数据不是有效的点：GeoJSON要素不是Point或缺少名称，CSV行缺少名称或坐标
+var ErrInvalidFeature = errors.New("geo: 不是有效的点")
+
+// 导入导出时位置的属性保存在伴随哈希中，key为PropsPrefix加位置名称，字段值均为字符串
+
+// ImportOptions 定义了批量导入的配置选项
+type ImportOptions struct {
+	ChunkSize    int    // 每批写入的位置数量，每批一次GEOADD，属性在同一批的管道中写入
+	PropsPrefix  string // 属性哈希的key前缀，为空时不写入属性
+	NameProperty string // GeoJSON中作为位置名称的属性，为空时使用要素的id
+}
+
+// DefaultImportOptions 返回一个包含推荐默认值的导入配置实例
+func DefaultImportOptions() *ImportOptions {
+	return &ImportOptions{
+		ChunkSize: 500, // 默认每批500个位置
+	}
+}
+
+// ExportOptions 定义了导出的配置选项
+type ExportOptions struct {
+	ScanCount   int64  // 每次ZSCAN的数量提示，同时也是每批GEOPOS的位置数量
+	PropsPrefix string // 属性哈希的key前缀，为空时不读取属性
+}
+
+// DefaultExportOptions 返回一个包含推荐默认值的导出配置实例
+func DefaultExportOptions() *ExportOptions {
+	return &ExportOptions{
+		ScanCount: 500, // 默认每批500个位置
+	}
+}
+
+// ImportResult 批量导入结果
+type ImportResult struct {
+	Added    int64      // 新添加的位置数量，已存在的位置被更新但不计入
+	Rejected []Rejected // 被拒绝的位置，Index为要素或数据行（不含表头）在输入中的下标
+}
+
+// feature 导入导出时使用的GeoJSON点要素
+type feature struct {
+	Type     string      `json:"type"`
+	ID       interface{} `json:"id,omitempty"`
+	Geometry *struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// point 待导入的位置及其属性
+type point struct {
+	index int
+	loc   *redis.GeoLocation
+	props map[string]string
+}
+
+// ImportGeoJSON 从GeoJSON FeatureCollection批量导入点要素
+// 参数:
+//   - ctx: 上下文
+//   - key: 键名
+//   - r: GeoJSON FeatureCollection
+//   - opts: 导入选项，为nil时使用默认配置
+//
+// 返回:
+//   - 导入结果，非Point要素、缺少名称或经纬度超出范围的要素被拒绝，不影响其余要素
+//   - 错误信息，输入不是合法的JSON时返回错误
+func (c *Client) ImportGeoJSON(ctx context.Context, key string, r io.Reader, opts *ImportOptions) (*ImportResult, error) {
+
+	//1.解析FeatureCollection
+	var fc struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, err
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("geo: 不支持的GeoJSON类型%q", fc.Type)
+	}
+
+	//2.转换为位置，无法转换的要素直接拒绝
+	if opts == nil {
+		opts = DefaultImportOptions()
+	}
+	imp := c.newImporter(key, opts)
+	for i, f := range fc.Features {
+		p, err := featureToPoint(f, opts.NameProperty)
+		if err != nil {
+			imp.reject(i, p.loc, err)
+			continue
+		}
+		p.index = i
+		if err = imp.add(ctx, p); err != nil {
+			return imp.res, err
+		}
+	}
+
+	//3.写入剩余的位置
+	return imp.finish(ctx)
+}
+
+// featureToPoint 将GeoJSON要素转换为位置，名称取nameProperty属性或要素id，其余属性转换为字符串
+func featureToPoint(f feature, nameProperty string) (point, error) {
+	p := point{loc: &redis.GeoLocation{}, props: make(map[string]string, len(f.Properties))}
+
+	//1.名称
+	id := f.ID
+	if nameProperty != "" {
+		id = f.Properties[nameProperty]
+	}
+	switch v := id.(type) {
+	case string:
+		p.loc.Name = v
+	case float64:
+		p.loc.Name = formatProp(v)
+	}
+	if p.loc.Name == "" {
+		return p, fmt.Errorf("%w: 缺少名称", ErrInvalidFeature)
+	}
+
+	//2.坐标
+	if f.Geometry == nil || f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+		return p, fmt.Errorf("%w: %s不是Point要素", ErrInvalidFeature, p.loc.Name)
+	}
+	p.loc.Longitude, p.loc.Latitude = f.Geometry.Coordinates[0], f.Geometry.Coordinates[1]
+
+	//3.属性
+	for k, v := range f.Properties {
+		if k != nameProperty {
+			p.props[k] = formatProp(v)
+		}
+	}
+	return p, nil
+}
+
+// formatProp 将属性值转换为字符串，字符串原样保留，其余类型按JSON编码
+func formatProp(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// ExportGeoJSON 将key中的所有位置导出为GeoJSON FeatureCollection
+// 使用ZSCAN分批遍历，每批在一个管道中读取GEOPOS与属性哈希，因此可以导出大key而不阻塞Redis
+// 遍历期间被修改的位置可能被遗漏或重复导出
+// 参数:
+//   - ctx: 上下文
+//   - key: 键名
+//   - w: 输出
+//   - opts: 导出选项，为nil时使用默认配置
+//
+// 返回:
+//   - 导出的位置数量
+//   - 错误信息
+func (c *Client) ExportGeoJSON(ctx context.Context, key string, w io.Writer, opts *ExportOptions) (int64, error) {
+	if opts == nil {
+		opts = DefaultExportOptions()
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(`{"type":"FeatureCollection","features":[`); err != nil {
+		return 0, err
+	}
+
+	var (
+		count  int64
+		cursor uint64
+	)
+	for {
+		//1.扫描一批成员，ZSCAN返回成员与分数交替的列表
+		pairs, next, err := c.rdb.ZScan(ctx, key, cursor, "", opts.ScanCount).Result()
+		if err != nil {
+			return count, err
+		}
+		members := make([]string, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			members = append(members, pairs[i])
+		}
+
+		//2.读取坐标与属性并写出
+		if len(members) > 0 {
+			n, err := c.exportBatch(ctx, key, members, opts, bw, count == 0)
+			count += n
+			if err != nil {
+				return count, err
+			}
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if _, err := bw.WriteString("]}"); err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// exportBatch 在一个管道中读取一批位置的坐标与属性并写出为要素，返回写出的数量
+func (c *Client) exportBatch(ctx context.Context, key string, members []string, opts *ExportOptions, w *bufio.Writer, first bool) (int64, error) {
+
+	//1.读取坐标与属性
+	pipe := c.rdb.Pipeline()
+	pos := pipe.GeoPos(ctx, key, members...)
+	props := make([]*redis.MapStringStringCmd, len(members))
+	if opts.PropsPrefix != "" {
+		for i, m := range members {
+			props[i] = pipe.HGetAll(ctx, opts.PropsPrefix+m)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	//2.写出要素，扫描期间已被删除的位置被跳过
+	var n int64
+	for i, m := range members {
+		p := pos.Val()[i]
+		if p == nil {
+			continue
+		}
+		f := map[string]interface{}{
+			"type":       "Feature",
+			"id":         m,
+			"geometry":   map[string]interface{}{"type": "Point", "coordinates": []float64{p.Longitude, p.Latitude}},
+			"properties": map[string]string{},
+		}
+		if props[i] != nil {
+			f["properties"] = props[i].Val()
+		}
+		data, err := json.Marshal(f)
+		if err != nil {
+			return n, err
+		}
+		if !first || n > 0 {
+			if err = w.WriteByte(','); err != nil {
+				return n, err
+			}
+		}
+		if _, err = w.Write(data); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// importer 按批写入位置与属性
+type importer struct {
+	c     *Client
+	key   string
+	opts  *ImportOptions
+	batch []point
+	res   *ImportResult
+}
+
+// newImporter 创建导入器
+func (c *Client) newImporter(key string, opts *ImportOptions) *importer {
+	return &importer{c: c, key: key, opts: opts, res: &ImportResult{}}
+}
+
+// finish 写入剩余的位置，并将被拒绝的位置按下标排序
+func (imp *importer) finish(ctx context.Context) (*ImportResult, error) {
+	err := imp.flush(ctx)
+	sort.Slice(imp.res.Rejected, func(i, j int) bool {
+		return imp.res.Rejected[i].Index < imp.res.Rejected[j].Index
+	})
+	return imp.res, err
+}
+
+// reject 记录被拒绝的位置
+func (imp *importer) reject(index int, loc *redis.GeoLocation, err error) {
+	imp.res.Rejected = append(imp.res.Rejected, Rejected{Index: index, Location: loc, Err: err})
+}
+
+// add 追加位置，攒满一批时写入
+func (imp *importer) add(ctx context.Context, p point) error {
+	imp.batch = append(imp.batch, p)
+	if len(imp.batch) >= max(imp.opts.ChunkSize, 1) {
+		return imp.flush(ctx)
+	}
+	return nil
+}
+
+// flush 通过GeoBatchAdd写入当前批次，再在一个管道中写入被接受的位置的属性
+func (imp *importer) flush(ctx context.Context) error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+	batch := imp.batch
+	imp.batch = nil
+
+	//1.写入位置，被拒绝的位置的下标转换为输入中的下标
+	locations := make([]*redis.GeoLocation, len(batch))
+	for i, p := range batch {
+		locations[i] = p.loc
+	}
+	added, rejected, err := imp.c.GeoBatchAdd(ctx, imp.key, locations...)
+	if err != nil {
+		return err
+	}
+	imp.res.Added += added
+	accepted := make([]bool, len(batch))
+	for i := range accepted {
+		accepted[i] = true
+	}
+	for _, r := range rejected {
+		accepted[r.Index] = false
+		imp.reject(batch[r.Index].index, r.Location, r.Err)
+	}
+
+	//2.写入属性，先删除旧属性
+	if imp.opts.PropsPrefix == "" {
+		return nil
+	}
+	_, err = imp.c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, p := range batch {
+			if !accepted[i] {
+				continue
+			}
+			key := imp.opts.PropsPrefix + p.loc.Name
+			pipe.Del(ctx, key)
+			if len(p.props) > 0 {
+				pipe.HSet(ctx, key, p.props)
+			}
+		}
+		return nil
+	})
+	return err
+}
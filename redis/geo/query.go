@@ -0,0 +1,194 @@
+// Package geo 提供Redis地理位置操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-26 10:00:00
+package geo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNoCenter 查询未指定搜索中心，或FromMember的位置元素为空
+	ErrNoCenter = errors.New("geo: 未指定搜索中心，需调用FromMember或FromLonLat")
+
+	// ErrNoShape 查询未指定搜索范围
+	ErrNoShape = errors.New("geo: 未指定搜索范围，需调用ByRadius或ByBox")
+
+	// ErrInvalidShape 搜索半径或矩形的宽高不大于0
+	ErrInvalidShape = errors.New("geo: 搜索半径或矩形的宽高必须大于0")
+
+	// ErrInvalidCount 返回数量为负数，或使用ANY时返回数量不大于0
+	ErrInvalidCount = errors.New("geo: 返回数量不合法，使用ANY时必须大于0")
+)
+
+// Query GEOSEARCH查询构造器
+// 通过链式调用指定搜索中心（FromMember/FromLonLat）、搜索范围（ByRadius/ByBox）、排序、数量与返回内容，
+// 最后调用Members、Locations、Store或StoreDist执行，参数错误在执行时返回
+type Query struct {
+	c          *Client
+	key        string
+	q          redis.GeoSearchQuery
+	from       bool // 是否已指定搜索中心
+	fromMember bool // 搜索中心是否为位置元素
+	shape      bool // 是否已指定搜索范围
+	unit       Unit
+	withCoord  bool
+	withDist   bool
+	withHash   bool
+}
+
+// Query 创建针对key的GEOSEARCH查询
+func (c *Client) Query(key string) *Query {
+	return &Query{c: c, key: key}
+}
+
+// FromMember 以已有的位置元素为搜索中心，member为空时执行返回ErrNoCenter
+func (q *Query) FromMember(member string) *Query {
+	q.q.Member, q.q.Longitude, q.q.Latitude = member, 0, 0
+	q.from, q.fromMember = true, true
+	return q
+}
+
+// FromLonLat 以给定的经纬度为搜索中心
+func (q *Query) FromLonLat(longitude, latitude float64) *Query {
+	q.q.Member, q.q.Longitude, q.q.Latitude = "", longitude, latitude
+	q.from, q.fromMember = true, false
+	return q
+}
+
+// ByRadius 在半径为radius的圆形范围内搜索
+func (q *Query) ByRadius(radius float64, unit Unit) *Query {
+	q.q.Radius, q.q.BoxWidth, q.q.BoxHeight = radius, 0, 0
+	q.unit, q.shape = unit, true
+	return q
+}
+
+// ByBox 在以搜索中心为中心、宽为width高为height的矩形范围内搜索
+func (q *Query) ByBox(width, height float64, unit Unit) *Query {
+	q.q.Radius, q.q.BoxWidth, q.q.BoxHeight = 0, width, height
+	q.unit, q.shape = unit, true
+	return q
+}
+
+// Asc 按与搜索中心的距离从近到远排序
+func (q *Query) Asc() *Query {
+	q.q.Sort = "ASC"
+	return q
+}
+
+// Desc 按与搜索中心的距离从远到近排序
+func (q *Query) Desc() *Query {
+	q.q.Sort = "DESC"
+	return q
+}
+
+// Count 最多返回count个结果，配合Asc可得到最近的count个
+func (q *Query) Count(count int) *Query {
+	q.q.Count, q.q.CountAny = count, false
+	return q
+}
+
+// CountAny 找到count个满足条件的结果后立即返回，速度更快但结果不一定是最近的
+func (q *Query) CountAny(count int) *Query {
+	q.q.Count, q.q.CountAny = count, true
+	return q
+}
+
+// WithCoord 在Locations结果中返回经纬度
+func (q *Query) WithCoord() *Query {
+	q.withCoord = true
+	return q
+}
+
+// WithDist 在Locations结果中返回与搜索中心的距离，单位与搜索范围的单位相同
+func (q *Query) WithDist() *Query {
+	q.withDist = true
+	return q
+}
+
+// WithHash 在Locations结果中返回52位整数形式的Geohash
+func (q *Query) WithHash() *Query {
+	q.withHash = true
+	return q
+}
+
+// Members 执行查询，只返回位置名称
+func (q *Query) Members(ctx context.Context) ([]string, error) {
+	sq, err := q.build()
+	if err != nil {
+		return nil, err
+	}
+	return q.c.GeoSearch(ctx, q.key, sq)
+}
+
+// Locations 执行查询，返回位置详细信息，包含的字段由WithCoord、WithDist、WithHash决定
+func (q *Query) Locations(ctx context.Context) ([]redis.GeoLocation, error) {
+	sq, err := q.build()
+	if err != nil {
+		return nil, err
+	}
+	return q.c.GeoSearchLocation(ctx, q.key, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: *sq,
+		WithCoord:      q.withCoord,
+		WithDist:       q.withDist,
+		WithHash:       q.withHash,
+	})
+}
+
+// Store 执行查询并使用GEOSEARCHSTORE将结果存入dest，dest仍是地理位置集合，返回存储的位置数量
+func (q *Query) Store(ctx context.Context, dest string) (int64, error) {
+	return q.store(ctx, dest, false)
+}
+
+// StoreDist 执行查询并将结果存入dest，dest中成员的分数为与搜索中心的距离，返回存储的位置数量
+func (q *Query) StoreDist(ctx context.Context, dest string) (int64, error) {
+	return q.store(ctx, dest, true)
+}
+
+// store 执行GEOSEARCHSTORE
+func (q *Query) store(ctx context.Context, dest string, storeDist bool) (int64, error) {
+	sq, err := q.build()
+	if err != nil {
+		return 0, err
+	}
+	return q.c.GeoSearchStore(ctx, q.key, dest, &redis.GeoSearchStoreQuery{GeoSearchQuery: *sq, StoreDist: storeDist})
+}
+
+// build 验证参数并生成GEOSEARCH参数
+func (q *Query) build() (*redis.GeoSearchQuery, error) {
+
+	//1.必须指定搜索中心与搜索范围，经纬度必须在有效范围内
+	if !q.from || (q.fromMember && q.q.Member == "") {
+		return nil, ErrNoCenter
+	}
+	if !q.shape {
+		return nil, ErrNoShape
+	}
+	if !q.fromMember {
+		if err := ValidateCoordinate(q.q.Longitude, q.q.Latitude); err != nil {
+			return nil, err
+		}
+	}
+
+	//2.验证范围与单位
+	if q.q.Radius <= 0 && (q.q.BoxWidth <= 0 || q.q.BoxHeight <= 0) {
+		return nil, ErrInvalidShape
+	}
+	if err := q.unit.validate(); err != nil {
+		return nil, err
+	}
+	if q.q.Count < 0 || (q.q.CountAny && q.q.Count == 0) {
+		return nil, ErrInvalidCount
+	}
+
+	//3.生成参数
+	sq := q.q
+	if sq.Radius > 0 {
+		sq.RadiusUnit = string(q.unit)
+	} else {
+		sq.BoxUnit = string(q.unit)
+	}
+	return &sq, nil
+}
@@ -0,0 +1,259 @@
+// Package geo 提供Redis地理位置操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-28 10:00:00
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 地理位置集合的成员没有独立的过期时间，跟踪器由以下key组成：
+//   - {tracker}:positions        地理位置集合，对象的最新位置
+//   - {tracker}:seen             有序集合，对象最后一次上报的时间（毫秒），用于淘汰不活跃的对象
+//   - {tracker}:history:{对象}   列表，对象最近的位置，格式为"毫秒:经度:纬度"，最新的在前
+// 时间均取Redis服务器时间，避免多个上报进程之间的时钟偏差
+// 淘汰脚本中历史列表的key由前缀在脚本内拼接，因此只适用于单实例或主从部署
+
+// trackScript 记录对象的位置、上报时间与历史位置，返回上报时间
+// KEYS[1]=位置集合 KEYS[2]=上报时间有序集合 KEYS[3]=历史列表
+// ARGV[1]=对象 ARGV[2]=经度 ARGV[3]=纬度 ARGV[4]=历史位置数量上限
+var trackScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call("GEOADD", KEYS[1], ARGV[2], ARGV[3], ARGV[1])
+redis.call("ZADD", KEYS[2], now, ARGV[1])
+local size = tonumber(ARGV[4])
+if size > 0 then
+	redis.call("LPUSH", KEYS[3], string.format("%d:%s:%s", now, ARGV[2], ARGV[3]))
+	redis.call("LTRIM", KEYS[3], 0, size - 1)
+end
+return now
+`)
+
+// evictScript 淘汰最多limit个超过ttl未上报的对象，返回被淘汰的对象
+// KEYS[1]=位置集合 KEYS[2]=上报时间有序集合 ARGV[1]=ttl(毫秒) ARGV[2]=历史列表key前缀 ARGV[3]=limit
+var evictScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local deadline = string.format("(%d", now - tonumber(ARGV[1]))
+local stale = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", deadline, "LIMIT", 0, ARGV[3])
+for _, member in ipairs(stale) do
+	redis.call("ZREM", KEYS[1], member)
+	redis.call("ZREM", KEYS[2], member)
+	redis.call("DEL", ARGV[2] .. member)
+end
+return stale
+`)
+
+// evictBatch 每次淘汰脚本处理的对象数，避免单个脚本阻塞Redis过久
+const evictBatch = 500
+
+// TrackerOptions 定义了移动对象跟踪器的配置选项
+type TrackerOptions struct {
+	TTL         time.Duration // 超过TTL未上报的对象视为不活跃并被淘汰，为0时不淘汰
+	HistorySize int64         // 每个对象保留的历史位置数量，为0时不保留历史
+}
+
+// DefaultTrackerOptions 返回一个包含推荐默认值的跟踪器配置实例
+func DefaultTrackerOptions() *TrackerOptions {
+	return &TrackerOptions{
+		TTL:         5 * time.Minute, // 默认5分钟未上报视为离线
+		HistorySize: 100,             // 默认保留最近100个位置
+	}
+}
+
+// Position 对象在某一时刻的位置
+type Position struct {
+	Longitude float64
+	Latitude  float64
+	Time      time.Time
+}
+
+// Tracker 移动对象跟踪器
+type Tracker struct {
+	c    *Client
+	name string
+	opts *TrackerOptions
+}
+
+// Tracker 获取名为name的跟踪器，opts为nil时使用默认配置
+func (c *Client) Tracker(name string, opts *TrackerOptions) *Tracker {
+	if opts == nil {
+		opts = DefaultTrackerOptions()
+	}
+	return &Tracker{c: c, name: name, opts: opts}
+}
+
+// Update 上报对象的最新位置
+// 参数:
+//   - ctx: 上下文
+//   - object: 对象
+//   - longitude: 经度
+//   - latitude: 纬度
+//
+// 返回:
+//   - 上报时间（Redis服务器时间）
+//   - 错误信息，经纬度超出范围时返回ErrInvalidCoordinate
+func (t *Tracker) Update(ctx context.Context, object string, longitude, latitude float64) (time.Time, error) {
+	if err := ValidateCoordinate(longitude, latitude); err != nil {
+		return time.Time{}, err
+	}
+	keys := []string{t.key("positions"), t.key("seen"), t.historyKey(object)}
+	ms, err := trackScript.Run(ctx, t.c.rdb, keys, object, longitude, latitude, t.opts.HistorySize).Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// Evict 淘汰所有超过TTL未上报的对象及其历史位置，返回被淘汰的对象
+func (t *Tracker) Evict(ctx context.Context) ([]string, error) {
+	if t.opts.TTL <= 0 {
+		return nil, nil
+	}
+	var evicted []string
+	for {
+		keys := []string{t.key("positions"), t.key("seen")}
+		stale, err := evictScript.Run(ctx, t.c.rdb, keys, t.opts.TTL.Milliseconds(), t.key("history:"), evictBatch).StringSlice()
+		if err != nil {
+			return evicted, err
+		}
+		evicted = append(evicted, stale...)
+		if len(stale) < evictBatch {
+			return evicted, nil
+		}
+	}
+}
+
+// RunEvictor 按interval周期性地淘汰不活跃的对象，直到上下文结束
+func (t *Tracker) RunEvictor(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		//1.淘汰不活跃的对象
+		if _, err := t.Evict(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		//2.等待下一轮
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Nearest 返回距离给定位置radius范围内最近的n个活跃对象，查询前先淘汰不活跃的对象
+// 参数:
+//   - ctx: 上下文
+//   - longitude: 经度
+//   - latitude: 纬度
+//   - radius: 搜索半径
+//   - unit: 距离单位
+//   - n: 返回的对象数量，不大于0时不限制
+//
+// 返回:
+//   - 按距离从近到远排列的对象，包含经纬度与距离
+//   - 错误信息
+func (t *Tracker) Nearest(ctx context.Context, longitude, latitude, radius float64, unit Unit, n int) ([]redis.GeoLocation, error) {
+	if _, err := t.Evict(ctx); err != nil {
+		return nil, err
+	}
+	q := t.c.Query(t.key("positions")).FromLonLat(longitude, latitude).ByRadius(radius, unit).Asc().WithCoord().WithDist()
+	if n > 0 {
+		q.Count(n)
+	}
+	return q.Locations(ctx)
+}
+
+// Position 返回对象的最新位置，对象不存在或已超过TTL未上报时返回ErrMemberNotFound
+func (t *Tracker) Position(ctx context.Context, object string) (*Position, error) {
+
+	//1.同时读取服务器时间、上报时间与位置
+	pipe := t.c.rdb.Pipeline()
+	now := pipe.Time(ctx)
+	seen := pipe.ZScore(ctx, t.key("seen"), object)
+	pos := pipe.GeoPos(ctx, t.key("positions"), object)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	//2.不存在或不活跃
+	if seen.Err() != nil || pos.Val()[0] == nil {
+		return nil, ErrMemberNotFound
+	}
+	at := time.UnixMilli(int64(seen.Val()))
+	if t.opts.TTL > 0 && now.Val().Sub(at) > t.opts.TTL {
+		return nil, ErrMemberNotFound
+	}
+	return &Position{Longitude: pos.Val()[0].Longitude, Latitude: pos.Val()[0].Latitude, Time: at}, nil
+}
+
+// History 返回对象最近的n个历史位置，最新的在前，n不大于0时返回全部
+func (t *Tracker) History(ctx context.Context, object string, n int64) ([]Position, error) {
+	stop := n - 1
+	if n <= 0 {
+		stop = -1
+	}
+	entries, err := t.c.rdb.LRange(ctx, t.historyKey(object), 0, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]Position, len(entries))
+	for i, e := range entries {
+		if positions[i], err = parsePosition(e); err != nil {
+			return nil, err
+		}
+	}
+	return positions, nil
+}
+
+// Active 返回活跃对象的数量，统计前先淘汰不活跃的对象
+func (t *Tracker) Active(ctx context.Context) (int64, error) {
+	if _, err := t.Evict(ctx); err != nil {
+		return 0, err
+	}
+	return t.c.rdb.ZCard(ctx, t.key("seen")).Result()
+}
+
+// Remove 删除对象的位置与历史位置
+func (t *Tracker) Remove(ctx context.Context, object string) error {
+	_, err := t.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, t.key("positions"), object)
+		pipe.ZRem(ctx, t.key("seen"), object)
+		pipe.Del(ctx, t.historyKey(object))
+		return nil
+	})
+	return err
+}
+
+// parsePosition 解析"毫秒:经度:纬度"格式的历史位置
+func parsePosition(s string) (Position, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) == 3 {
+		ms, err1 := strconv.ParseInt(parts[0], 10, 64)
+		lon, err2 := strconv.ParseFloat(parts[1], 64)
+		lat, err3 := strconv.ParseFloat(parts[2], 64)
+		if err1 == nil && err2 == nil && err3 == nil {
+			return Position{Longitude: lon, Latitude: lat, Time: time.UnixMilli(ms)}, nil
+		}
+	}
+	return Position{}, fmt.Errorf("geo: 历史位置格式错误: %q", s)
+}
+
+// historyKey 返回对象历史位置列表的key
+func (t *Tracker) historyKey(object string) string {
+	return t.key("history:" + object)
+}
+
+// key 返回跟踪器下的子key
+func (t *Tracker) key(suffix string) string {
+	return t.name + ":" + suffix
+}
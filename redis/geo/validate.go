@@ -0,0 +1,74 @@
+// Package geo 提供Redis地理位置操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-26 14:00:00
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis支持的经纬度范围，纬度受限于Web墨卡托投影，两极附近的区域无法存储
+const (
+	MaxLongitude = 180.0
+	MaxLatitude  = 85.05112878
+)
+
+var (
+	// ErrInvalidUnit 距离单位不是m、km、mi、ft之一
+	ErrInvalidUnit = errors.New("geo: 无效的距离单位，必须是m、km、mi或ft之一")
+
+	// ErrInvalidCoordinate 经纬度超出Redis支持的范围
+	ErrInvalidCoordinate = errors.New("geo: 经纬度超出范围")
+
+	// ErrMemberNotFound 作为距离计算或搜索中心的位置元素不存在
+	ErrMemberNotFound = errors.New("geo: 位置元素不存在")
+)
+
+// Rejected 批量添加时被拒绝的位置
+type Rejected struct {
+	Index    int                // 在输入中的下标
+	Location *redis.GeoLocation // 被拒绝的位置
+	Err      error              // 拒绝原因，可用errors.Is判断
+}
+
+// ValidateCoordinate 验证经纬度是否在Redis支持的范围内
+// 经度范围为[-180, 180]，纬度范围为[-85.05112878, 85.05112878]
+func ValidateCoordinate(longitude, latitude float64) error {
+	if math.IsNaN(longitude) || longitude < -MaxLongitude || longitude > MaxLongitude {
+		return fmt.Errorf("%w: 经度%v不在[-%v, %v]之间", ErrInvalidCoordinate, longitude, MaxLongitude, MaxLongitude)
+	}
+	if math.IsNaN(latitude) || latitude < -MaxLatitude || latitude > MaxLatitude {
+		return fmt.Errorf("%w: 纬度%v不在[-%v, %v]之间", ErrInvalidCoordinate, latitude, MaxLatitude, MaxLatitude)
+	}
+	return nil
+}
+
+// validateLocation 验证待添加的位置
+func validateLocation(loc *redis.GeoLocation) error {
+	if loc == nil {
+		return fmt.Errorf("%w: 位置为空", ErrInvalidCoordinate)
+	}
+	return ValidateCoordinate(loc.Longitude, loc.Latitude)
+}
+
+// validate 验证单位是否有效
+func (u Unit) validate() error {
+	switch u {
+	case M, KM, MI, FT:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidUnit, string(u))
+}
+
+// memberErr 将位置元素不存在时Redis返回的错误转换为ErrMemberNotFound
+// GEODIST在任一元素不存在时返回nil，GEOSEARCH FROMMEMBER在元素不存在时返回could not decode错误
+func memberErr(err error) error {
+	if errors.Is(err, redis.Nil) || (err != nil && strings.Contains(err.Error(), "could not decode requested zset member")) {
+		return ErrMemberNotFound
+	}
+	return err
+}
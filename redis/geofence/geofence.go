@@ -0,0 +1,385 @@
+// Package geofence 提供基于Redis地理位置的电子围栏封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-27 10:00:00
+package geofence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	geopkg "go-redis-demo/redis/geo"
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// ErrZoneNotFound 区域不存在
+var ErrZoneNotFound = errors.New("geofence: 区域不存在")
+
+// 每个围栏由以下key组成：
+//   - {fence}:zones          哈希，区域ID -> GeoJSON
+//   - {fence}:centers        地理位置集合，区域外接矩形的中心点，用于按位置粗筛区域
+//   - {fence}:extent         有序集合，区域外接矩形宽高中较大者（千米），用于确定粗筛范围
+//   - {fence}:positions      地理位置集合，对象的最新位置
+//   - {fence}:inside:{对象}  集合，对象当前所在的区域
+//   - {fence}:events         进出区域事件的发布订阅频道
+// 粗筛使用GEOSEARCH BYBOX，再在Go中按多边形精确判断
+
+// searchMargin 粗筛矩形相对外接矩形的放大比例与最小余量（千米），抵消距离计算的误差
+const (
+	searchMargin    = 0.01
+	searchMarginMin = 0.01
+)
+
+// updateScript 替换对象所在的区域并记录最新位置，返回新进入与离开的区域
+// KEYS[1]=对象所在区域集合 KEYS[2]=对象位置集合
+// ARGV[1]=对象 ARGV[2]=经度 ARGV[3]=纬度 ARGV[4..]=对象当前所在的区域
+var updateScript = redis.NewScript(`
+local now = {}
+for i = 4, #ARGV do
+	now[ARGV[i]] = true
+end
+local entered, exited = {}, {}
+for _, zone in ipairs(redis.call("SMEMBERS", KEYS[1])) do
+	if now[zone] then
+		now[zone] = nil
+	else
+		table.insert(exited, zone)
+	end
+end
+for i = 4, #ARGV do
+	if now[ARGV[i]] then
+		table.insert(entered, ARGV[i])
+	end
+end
+redis.call("DEL", KEYS[1])
+if #ARGV >= 4 then
+	redis.call("SADD", KEYS[1], unpack(ARGV, 4))
+end
+redis.call("GEOADD", KEYS[2], ARGV[2], ARGV[3], ARGV[1])
+return {entered, exited}
+`)
+
+// removeScript 删除对象的位置与所在区域，返回对象离开的区域
+// KEYS同updateScript ARGV[1]=对象
+var removeScript = redis.NewScript(`
+local zones = redis.call("SMEMBERS", KEYS[1])
+redis.call("DEL", KEYS[1])
+redis.call("ZREM", KEYS[2], ARGV[1])
+return zones
+`)
+
+// EventType 事件类型
+type EventType string
+
+const (
+	Enter EventType = "enter" // 进入区域
+	Exit  EventType = "exit"  // 离开区域
+)
+
+// Event 对象进出区域的事件
+type Event struct {
+	Type      EventType `json:"type"`
+	Object    string    `json:"object"`
+	Zone      string    `json:"zone"`
+	Longitude float64   `json:"longitude"` // 触发事件时对象的位置，对象被删除时为0
+	Latitude  float64   `json:"latitude"`
+	Time      time.Time `json:"time"`
+}
+
+// Client 电子围栏客户端
+type Client struct {
+	rdb  *redis.Client
+	geo  *geopkg.Client
+	zset *zsetpkg.Client
+}
+
+// New 创建电子围栏客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, geo: geopkg.New(rdb), zset: zsetpkg.New(rdb)}
+}
+
+// Fence 获取名为name的围栏
+func (c *Client) Fence(name string) *Fence {
+	return &Fence{c: c, name: name}
+}
+
+// Fence 电子围栏，包含若干区域与在其中移动的对象
+type Fence struct {
+	c    *Client
+	name string
+}
+
+// AddZone 添加或替换区域
+// 参数:
+//   - ctx: 上下文
+//   - id: 区域ID
+//   - geojson: 区域的GeoJSON，见ParseGeoJSON
+//
+// 返回:
+//   - 错误信息，GeoJSON不合法时返回ErrInvalidGeoJSON
+func (f *Fence) AddZone(ctx context.Context, id string, geojson []byte) error {
+
+	//1.解析并验证
+	shape, err := ParseGeoJSON(geojson)
+	if err != nil {
+		return err
+	}
+
+	//2.在同一个事务中写入GeoJSON、中心点与尺寸
+	lon, lat := shape.Bounds().Center()
+	width, height := shape.Bounds().Size()
+	_, err = f.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, f.key("zones"), id, geojson)
+		pipe.GeoAdd(ctx, f.key("centers"), &redis.GeoLocation{Name: id, Longitude: lon, Latitude: lat})
+		pipe.ZAdd(ctx, f.key("extent"), redis.Z{Member: id, Score: max(width, height)})
+		return nil
+	})
+	return err
+}
+
+// RemoveZone 删除区域，此前在该区域内的对象在下次Update时收到离开事件
+func (f *Fence) RemoveZone(ctx context.Context, id string) error {
+	_, err := f.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, f.key("zones"), id)
+		pipe.ZRem(ctx, f.key("centers"), id)
+		pipe.ZRem(ctx, f.key("extent"), id)
+		return nil
+	})
+	return err
+}
+
+// Zone 返回区域的形状，区域不存在时返回ErrZoneNotFound
+func (f *Fence) Zone(ctx context.Context, id string) (*Shape, error) {
+	data, err := f.c.rdb.HGet(ctx, f.key("zones"), id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrZoneNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseGeoJSON(data)
+}
+
+// ZonesAt 返回包含给定位置的所有区域
+// 先以位置为中心、最大区域尺寸为边长按矩形搜索区域中心点，再按多边形精确判断
+func (f *Fence) ZonesAt(ctx context.Context, longitude, latitude float64) ([]string, error) {
+
+	//1.验证经纬度，没有区域时直接返回
+	if err := geopkg.ValidateCoordinate(longitude, latitude); err != nil {
+		return nil, err
+	}
+	largest, err := f.c.zset.ZRevRangeWithScores(ctx, f.key("extent"), 0, 0)
+	if err != nil || len(largest) == 0 {
+		return nil, err
+	}
+
+	//2.粗筛：外接矩形包含该位置的区域，其中心点与该位置的经纬向距离都不超过最大尺寸的一半
+	side := largest[0].Score*(1+searchMargin) + searchMarginMin
+	candidates, err := f.c.geo.Query(f.key("centers")).FromLonLat(longitude, latitude).ByBox(side, side, geopkg.KM).Members(ctx)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	//3.精确判断
+	values, err := f.c.rdb.HMGet(ctx, f.key("zones"), candidates...).Result()
+	if err != nil {
+		return nil, err
+	}
+	var zones []string
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		shape, err := ParseGeoJSON([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		if shape.Contains(longitude, latitude) {
+			zones = append(zones, candidates[i])
+		}
+	}
+	return zones, nil
+}
+
+// Inside 返回当前位于区域内的所有对象
+// 先以区域外接矩形按矩形搜索对象位置，再按多边形精确判断
+func (f *Fence) Inside(ctx context.Context, zone string) ([]string, error) {
+
+	//1.读取区域
+	shape, err := f.Zone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.粗筛：外接矩形内的对象
+	lon, lat := shape.Bounds().Center()
+	width, height := shape.Bounds().Size()
+	locations, err := f.c.geo.Query(f.key("positions")).FromLonLat(lon, lat).
+		ByBox(width*(1+searchMargin)+searchMarginMin, height*(1+searchMargin)+searchMarginMin, geopkg.KM).
+		WithCoord().Locations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	//3.精确判断
+	var objects []string
+	for _, loc := range locations {
+		if shape.Contains(loc.Longitude, loc.Latitude) {
+			objects = append(objects, loc.Name)
+		}
+	}
+	return objects, nil
+}
+
+// Contains 判断对象的最新位置是否在区域内
+// 返回:
+//   - 是否在区域内
+//   - 错误信息，区域不存在时返回ErrZoneNotFound，对象没有位置时返回geo.ErrMemberNotFound
+func (f *Fence) Contains(ctx context.Context, zone, object string) (bool, error) {
+	shape, err := f.Zone(ctx, zone)
+	if err != nil {
+		return false, err
+	}
+	positions, err := f.c.geo.GeoPos(ctx, f.key("positions"), object)
+	if err != nil {
+		return false, err
+	}
+	if positions[0] == nil {
+		return false, geopkg.ErrMemberNotFound
+	}
+	return shape.Contains(positions[0].Longitude, positions[0].Latitude), nil
+}
+
+// Update 更新对象位置，计算对象进入与离开的区域，并将事件发布到围栏的事件频道
+// 参数:
+//   - ctx: 上下文
+//   - object: 对象
+//   - longitude: 经度
+//   - latitude: 纬度
+//
+// 返回:
+//   - 本次更新产生的事件，先进入后离开
+//   - 错误信息
+func (f *Fence) Update(ctx context.Context, object string, longitude, latitude float64) ([]Event, error) {
+
+	//1.计算对象当前所在的区域
+	zones, err := f.ZonesAt(ctx, longitude, latitude)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.原子地替换所在区域并记录位置
+	args := make([]interface{}, 0, len(zones)+3)
+	args = append(args, object, longitude, latitude)
+	for _, zone := range zones {
+		args = append(args, zone)
+	}
+	res, err := updateScript.Run(ctx, f.c.rdb, []string{f.insideKey(object), f.key("positions")}, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	//3.生成并发布事件
+	now := time.Now()
+	var events []Event
+	for i, typ := range []EventType{Enter, Exit} {
+		for _, zone := range res[i].([]interface{}) {
+			events = append(events, Event{Type: typ, Object: object, Zone: zone.(string), Longitude: longitude, Latitude: latitude, Time: now})
+		}
+	}
+	return events, f.publish(ctx, events)
+}
+
+// RemoveObject 删除对象的位置，对象所在的区域都产生离开事件
+func (f *Fence) RemoveObject(ctx context.Context, object string) ([]Event, error) {
+	zones, err := removeScript.Run(ctx, f.c.rdb, []string{f.insideKey(object), f.key("positions")}, object).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	events := make([]Event, len(zones))
+	for i, zone := range zones {
+		events[i] = Event{Type: Exit, Object: object, Zone: zone, Time: now}
+	}
+	return events, f.publish(ctx, events)
+}
+
+// Subscribe 订阅围栏的进出事件，订阅在返回前已生效
+func (f *Fence) Subscribe(ctx context.Context) (*Subscription, error) {
+
+	//1.订阅频道并等待确认
+	pubsub := f.c.rdb.Subscribe(ctx, f.key("events"))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	//2.后台解码消息，无法解码的消息被丢弃
+	s := &Subscription{pubsub: pubsub, events: make(chan Event), done: make(chan struct{})}
+	go func() {
+		defer close(s.events)
+		for msg := range pubsub.Channel() {
+			var e Event
+			if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+				continue
+			}
+			select {
+			case s.events <- e:
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Subscription 事件订阅
+type Subscription struct {
+	pubsub *redis.PubSub
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events 返回事件通道，订阅关闭后通道被关闭
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close 关闭订阅，未被读取的事件被丢弃
+func (s *Subscription) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return s.pubsub.Close()
+}
+
+// publish 在一个管道中发布所有事件
+func (f *Fence) publish(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := f.c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, e := range events {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			pipe.Publish(ctx, f.key("events"), payload)
+		}
+		return nil
+	})
+	return err
+}
+
+// insideKey 返回对象所在区域集合的key
+func (f *Fence) insideKey(object string) string {
+	return f.key("inside:" + object)
+}
+
+// key 返回围栏下的子key
+func (f *Fence) key(suffix string) string {
+	return f.name + ":" + suffix
+}
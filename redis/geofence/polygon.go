@@ -0,0 +1,186 @@
+// Package geofence 提供基于Redis地理位置的电子围栏封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-27 10:00:00
+package geofence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	geopkg "go-redis-demo/redis/geo"
+)
+
+// ErrInvalidGeoJSON GeoJSON不合法或不是多边形
+var ErrInvalidGeoJSON = errors.New("geofence: GeoJSON不合法")
+
+// kmPerDegree 地球表面一度对应的千米数，地球半径与Redis保持一致
+const kmPerDegree = 6372.797560856 * math.Pi / 180
+
+// point 经纬度坐标
+type point struct {
+	lon, lat float64
+}
+
+// Bounds 经纬度外接矩形，不支持跨越180度经线的区域
+type Bounds struct {
+	MinLon, MinLat float64
+	MaxLon, MaxLat float64
+}
+
+// Center 返回外接矩形的中心点
+func (b Bounds) Center() (longitude, latitude float64) {
+	return (b.MinLon + b.MaxLon) / 2, (b.MinLat + b.MaxLat) / 2
+}
+
+// Size 返回外接矩形的宽和高（千米），宽按最接近赤道的纬度计算，因此不小于矩形内任意纬度上的实际宽度
+func (b Bounds) Size() (width, height float64) {
+	lat := 0.0
+	if b.MinLat > 0 {
+		lat = b.MinLat
+	} else if b.MaxLat < 0 {
+		lat = b.MaxLat
+	}
+	width = (b.MaxLon - b.MinLon) * kmPerDegree * math.Cos(lat*math.Pi/180)
+	height = (b.MaxLat - b.MinLat) * kmPerDegree
+	return width, height
+}
+
+// contains 判断点是否在外接矩形内
+func (b Bounds) contains(lon, lat float64) bool {
+	return lon >= b.MinLon && lon <= b.MaxLon && lat >= b.MinLat && lat <= b.MaxLat
+}
+
+// Shape 由一个或多个多边形组成的区域，每个多边形的第一个环为外边界，其余环为洞
+type Shape struct {
+	polygons [][][]point
+	bounds   Bounds
+}
+
+// geoJSON 解析GeoJSON时使用的结构，同时兼容Geometry与Feature
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+}
+
+// ParseGeoJSON 解析GeoJSON多边形
+// 支持Polygon、MultiPolygon以及几何类型为二者之一的Feature，每个环至少4个顶点且首尾相同
+// 参数:
+//   - data: GeoJSON
+//
+// 返回:
+//   - 区域
+//   - 错误信息，格式不合法时返回ErrInvalidGeoJSON，坐标超出范围时返回geo.ErrInvalidCoordinate
+func ParseGeoJSON(data []byte) (*Shape, error) {
+
+	//1.解析外层结构，Feature取其几何对象
+	var g geoJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeoJSON, err)
+	}
+	if g.Type == "Feature" {
+		if g.Geometry == nil {
+			return nil, fmt.Errorf("%w: Feature缺少geometry", ErrInvalidGeoJSON)
+		}
+		g = *g.Geometry
+	}
+
+	//2.按类型解析坐标，Polygon视为只有一个多边形的MultiPolygon
+	var coords [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeoJSON, err)
+		}
+		coords = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeoJSON, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: 不支持的类型%q", ErrInvalidGeoJSON, g.Type)
+	}
+
+	//3.转换并验证每个环
+	return newShape(coords)
+}
+
+// newShape 验证坐标并计算外接矩形
+func newShape(coords [][][][]float64) (*Shape, error) {
+	if len(coords) == 0 {
+		return nil, fmt.Errorf("%w: 没有多边形", ErrInvalidGeoJSON)
+	}
+	s := &Shape{bounds: Bounds{MinLon: math.Inf(1), MinLat: math.Inf(1), MaxLon: math.Inf(-1), MaxLat: math.Inf(-1)}}
+	for _, polygon := range coords {
+		if len(polygon) == 0 {
+			return nil, fmt.Errorf("%w: 多边形没有环", ErrInvalidGeoJSON)
+		}
+		rings := make([][]point, len(polygon))
+		for i, ring := range polygon {
+			if len(ring) < 4 {
+				return nil, fmt.Errorf("%w: 环至少需要4个顶点", ErrInvalidGeoJSON)
+			}
+			rings[i] = make([]point, len(ring))
+			for j, pos := range ring {
+				if len(pos) < 2 {
+					return nil, fmt.Errorf("%w: 坐标至少需要经度和纬度", ErrInvalidGeoJSON)
+				}
+				if err := geopkg.ValidateCoordinate(pos[0], pos[1]); err != nil {
+					return nil, err
+				}
+				rings[i][j] = point{lon: pos[0], lat: pos[1]}
+			}
+			if rings[i][0] != rings[i][len(ring)-1] {
+				return nil, fmt.Errorf("%w: 环的首尾顶点必须相同", ErrInvalidGeoJSON)
+			}
+		}
+		for _, p := range rings[0] {
+			s.bounds.MinLon, s.bounds.MaxLon = math.Min(s.bounds.MinLon, p.lon), math.Max(s.bounds.MaxLon, p.lon)
+			s.bounds.MinLat, s.bounds.MaxLat = math.Min(s.bounds.MinLat, p.lat), math.Max(s.bounds.MaxLat, p.lat)
+		}
+		s.polygons = append(s.polygons, rings)
+	}
+	return s, nil
+}
+
+// Bounds 返回区域的外接矩形
+func (s *Shape) Bounds() Bounds {
+	return s.bounds
+}
+
+// Contains 判断点是否在区域内：在任一多边形的外边界内且不在其洞内，恰好落在边上的点结果不确定
+func (s *Shape) Contains(longitude, latitude float64) bool {
+	if !s.bounds.contains(longitude, latitude) {
+		return false
+	}
+	for _, rings := range s.polygons {
+		if !inRing(rings[0], longitude, latitude) {
+			continue
+		}
+		inHole := false
+		for _, hole := range rings[1:] {
+			if inRing(hole, longitude, latitude) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// inRing 射线法判断点是否在环内：从点向东发出射线，与环的边相交奇数次时在环内
+func inRing(ring []point, lon, lat float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.lat > lat) != (b.lat > lat) && lon < (b.lon-a.lon)*(lat-a.lat)/(b.lat-a.lat)+a.lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,255 @@
+// Package graph 提供基于Redis集合与有序集合的关注关系（社交图谱）封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-25 10:00:00
+package graph
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	setpkg "go-redis-demo/redis/set"
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// ErrSelfFollow 不能关注自己
+var ErrSelfFollow = errors.New("graph: 不能关注自己")
+
+// tmpTTL 临时结果的过期时间，异常退出未能删除时由过期兜底
+const tmpTTL = time.Minute
+
+// 每个图谱由以下key组成：
+//   - {graph}:following:{user}     集合，user关注的人，用于交集运算
+//   - {graph}:followers:{user}     集合，关注user的人
+//   - {graph}:following_at:{user}  有序集合，user关注的人，分数为关注时间（毫秒），用于按时间排序
+//   - {graph}:followers_at:{user}  有序集合，关注user的人，分数为关注时间（毫秒）
+// 集合与有序集合总是在同一个脚本中同时修改，保持一致
+
+// followScript 建立关注关系，已关注时不修改关注时间
+// KEYS[1]=following KEYS[2]=followers KEYS[3]=following_at KEYS[4]=followers_at
+// ARGV[1]=user ARGV[2]=target ARGV[3]=关注时间(毫秒)
+var followScript = redis.NewScript(`
+if redis.call("SADD", KEYS[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call("SADD", KEYS[2], ARGV[1])
+redis.call("ZADD", KEYS[3], ARGV[3], ARGV[2])
+redis.call("ZADD", KEYS[4], ARGV[3], ARGV[1])
+return 1
+`)
+
+// unfollowScript 取消关注关系
+// KEYS同followScript ARGV[1]=user ARGV[2]=target
+var unfollowScript = redis.NewScript(`
+if redis.call("SREM", KEYS[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call("SREM", KEYS[2], ARGV[1])
+redis.call("ZREM", KEYS[3], ARGV[2])
+redis.call("ZREM", KEYS[4], ARGV[1])
+return 1
+`)
+
+// Relation 关注关系
+type Relation struct {
+	User  string    // 对方
+	Since time.Time // 关注时间
+}
+
+// Counts 关注数与粉丝数
+type Counts struct {
+	Following int64 // 关注数
+	Followers int64 // 粉丝数
+}
+
+// Suggestion 推荐关注的人
+type Suggestion struct {
+	User  string  // 被推荐的人
+	Score float64 // 推荐分，互相关注的好友关注了他计2分，单向关注的人关注了他计1分
+}
+
+// Client 社交图谱客户端
+type Client struct {
+	rdb  *redis.Client
+	set  *setpkg.Client
+	zset *zsetpkg.Client
+}
+
+// New 创建社交图谱客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, set: setpkg.New(rdb), zset: zsetpkg.New(rdb)}
+}
+
+// Graph 获取名为name的社交图谱
+func (c *Client) Graph(name string) *Graph {
+	return &Graph{c: c, name: name}
+}
+
+// Graph 社交图谱
+type Graph struct {
+	c    *Client
+	name string
+}
+
+// Follow user关注target，返回是否为新建立的关注关系
+func (g *Graph) Follow(ctx context.Context, user, target string) (bool, error) {
+	if user == target {
+		return false, ErrSelfFollow
+	}
+	n, err := followScript.Run(ctx, g.c.rdb, g.relationKeys(user, target), user, target, time.Now().UnixMilli()).Int64()
+	return n == 1, err
+}
+
+// Unfollow user取消关注target，返回之前是否已关注
+func (g *Graph) Unfollow(ctx context.Context, user, target string) (bool, error) {
+	n, err := unfollowScript.Run(ctx, g.c.rdb, g.relationKeys(user, target), user, target).Int64()
+	return n == 1, err
+}
+
+// IsFollowing 判断user是否关注了target
+func (g *Graph) IsFollowing(ctx context.Context, user, target string) (bool, error) {
+	return g.c.set.SIsMember(ctx, g.key("following", user), target)
+}
+
+// Following 按关注时间从新到旧分页返回user关注的人
+func (g *Graph) Following(ctx context.Context, user string, offset, count int64) ([]Relation, error) {
+	return g.relations(ctx, g.key("following_at", user), offset, count)
+}
+
+// Followers 按关注时间从新到旧分页返回user的粉丝
+func (g *Graph) Followers(ctx context.Context, user string, offset, count int64) ([]Relation, error) {
+	return g.relations(ctx, g.key("followers_at", user), offset, count)
+}
+
+// Counts 返回user的关注数与粉丝数
+func (g *Graph) Counts(ctx context.Context, user string) (*Counts, error) {
+	pipe := g.c.rdb.Pipeline()
+	following := pipe.SCard(ctx, g.key("following", user))
+	followers := pipe.SCard(ctx, g.key("followers", user))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return &Counts{Following: following.Val(), Followers: followers.Val()}, nil
+}
+
+// Friends 返回与user互相关注的人
+func (g *Graph) Friends(ctx context.Context, user string) ([]string, error) {
+	return g.c.set.SInter(ctx, g.key("following", user), g.key("followers", user))
+}
+
+// Mutual 返回a与b共同关注的人
+func (g *Graph) Mutual(ctx context.Context, a, b string) ([]string, error) {
+	return g.c.set.SInter(ctx, g.key("following", a), g.key("following", b))
+}
+
+// Suggest 推荐user可能认识的人：统计user最近关注的sources个人各自关注了谁，
+// 通过加权ZUNIONSTORE累加推荐分，排除user自己与已关注的人后返回分数最高的limit个
+// 参数:
+//   - ctx: 上下文
+//   - user: 用户
+//   - sources: 参与统计的最近关注的人数上限，用于控制关注数很多的用户的计算量，不大于0时不限制
+//   - limit: 返回的推荐数量，不大于0时不限制
+//
+// 返回:
+//   - 按推荐分从高到低排列的推荐列表
+//   - 错误信息
+func (g *Graph) Suggest(ctx context.Context, user string, sources, limit int64) ([]Suggestion, error) {
+
+	//1.取最近关注的人作为推荐来源
+	followed, err := g.c.zset.ZRevRange(ctx, g.key("following_at", user), 0, sources-1)
+	if err != nil || len(followed) == 0 {
+		return nil, err
+	}
+
+	//2.互相关注的好友权重为2，单向关注的人权重为1
+	members := make([]interface{}, len(followed))
+	for i, f := range followed {
+		members[i] = f
+	}
+	mutual, err := g.c.set.SMIsMember(ctx, g.key("followers", user), members...)
+	if err != nil {
+		return nil, err
+	}
+	store := &redis.ZStore{Keys: make([]string, len(followed)), Weights: make([]float64, len(followed))}
+	for i, f := range followed {
+		store.Keys[i] = g.key("following", f)
+		store.Weights[i] = 1
+		if mutual[i] {
+			store.Weights[i] = 2
+		}
+	}
+
+	//3.加权求并集，集合成员的分数视为1，在同一个事务中设置过期时间
+	tmp := g.tmpKey()
+	defer g.c.rdb.Del(context.WithoutCancel(ctx), tmp)
+	_, err = g.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, tmp, store)
+		pipe.Expire(ctx, tmp, tmpTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	//4.排除自己与已关注的人
+	all, err := g.c.set.SMembers(ctx, g.key("following", user))
+	if err != nil {
+		return nil, err
+	}
+	exclude := []interface{}{user}
+	for _, f := range all {
+		exclude = append(exclude, f)
+	}
+	if _, err = g.c.zset.ZRem(ctx, tmp, exclude...); err != nil {
+		return nil, err
+	}
+
+	//5.返回分数最高的limit个
+	top, err := g.c.zset.ZRevRangeWithScores(ctx, tmp, 0, limit-1)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]Suggestion, len(top))
+	for i, z := range top {
+		suggestions[i] = Suggestion{User: z.Member.(string), Score: z.Score}
+	}
+	return suggestions, nil
+}
+
+// relations 按时间从新到旧分页读取关注关系
+func (g *Graph) relations(ctx context.Context, key string, offset, count int64) ([]Relation, error) {
+	members, err := g.c.zset.ZRevRangeWithScores(ctx, key, offset, offset+count-1)
+	if err != nil {
+		return nil, err
+	}
+	relations := make([]Relation, len(members))
+	for i, z := range members {
+		relations[i] = Relation{User: z.Member.(string), Since: time.UnixMilli(int64(z.Score))}
+	}
+	return relations, nil
+}
+
+// relationKeys 返回user关注target时涉及的4个key
+func (g *Graph) relationKeys(user, target string) []string {
+	return []string{
+		g.key("following", user),
+		g.key("followers", target),
+		g.key("following_at", user),
+		g.key("followers_at", target),
+	}
+}
+
+// tmpKey 返回随机的临时key
+func (g *Graph) tmpKey() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return g.name + ":tmp:" + hex.EncodeToString(b)
+}
+
+// key 返回图谱下用户的子key
+func (g *Graph) key(kind, user string) string {
+	return g.name + ":" + kind + ":" + user
+}
@@ -0,0 +1,478 @@
+// Package leaderboard 提供基于Redis有序集合的排行榜封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-18 10:00:00
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// SubmitMode 提交分数的方式
+type SubmitMode int
+
+const (
+	KeepMax    SubmitMode = iota // 保留最高分
+	KeepLatest                   // 保留最近一次提交的分数
+	Accumulate                   // 累加分数
+)
+
+// RankMode 并列时的排名方式
+type RankMode int
+
+const (
+	Ordinal  RankMode = iota // 顺序排名（1234），同分时先达到该分数者排在前面
+	Standard                 // 标准排名（1224），同分同名次，后续名次跳过
+	Dense                    // 密集排名（1223），同分同名次，后续名次连续
+)
+
+// Period 排行榜周期
+type Period int
+
+const (
+	AllTime Period = iota // 总榜
+	Daily                 // 日榜
+	Weekly                // 周榜（ISO周）
+	Monthly               // 月榜
+)
+
+var (
+	// ErrNonIntegerScore 开启同分按时间排序时分数必须为整数
+	ErrNonIntegerScore = errors.New("leaderboard: 开启同分按时间排序时分数必须为整数")
+
+	// ErrMemberNotFound 成员不在排行榜中
+	ErrMemberNotFound = errors.New("leaderboard: 成员不在排行榜中")
+
+	// ErrNoSource 合并时没有指定来源排行榜
+	ErrNoSource = errors.New("leaderboard: 至少需要一个参与合并的排行榜")
+
+	// ErrMergeUnsupported 保留最近分数的排行榜无法从分数中得知提交先后，不支持合并
+	ErrMergeUnsupported = errors.New("leaderboard: 保留最近分数的排行榜不支持合并")
+)
+
+// 同分按提交时间排序时，分数编码为 整数分数 + 时间小数部分，提交越早小数部分越大；
+// 小数部分以2020-01-01为起点、秒为精度，占用32位，因此分数绝对值需小于2^20才能保证时间精度
+const (
+	tieEpoch = 1577836800 // 2020-01-01 00:00:00 UTC
+	tieSpan  = 1 << 32
+)
+
+// submitScript 原子地提交分数
+// KEYS[1]=排行榜key ARGV[1]=提交方式 ARGV[2]=成员 ARGV[3]=分数 ARGV[4]=时间小数部分 ARGV[5]=过期时间戳(毫秒，0表示不过期)
+// 返回提交后的原始分数
+var submitScript = redis.NewScript(`
+local score = tonumber(ARGV[3])
+local cur = redis.call("ZSCORE", KEYS[1], ARGV[2])
+if cur then
+	cur = tonumber(cur)
+	if ARGV[4] ~= "0" then
+		cur = math.floor(cur)
+	end
+	if ARGV[1] == "max" and score <= cur then
+		return tostring(cur)
+	end
+	if ARGV[1] == "sum" then
+		score = cur + score
+	end
+end
+redis.call("ZADD", KEYS[1], score + tonumber(ARGV[4]), ARGV[2])
+if ARGV[5] ~= "0" then
+	redis.call("PEXPIREAT", KEYS[1], ARGV[5])
+end
+return tostring(score)
+`)
+
+// denseRankScript 统计分数高于指定下界的不同分数个数，用于计算密集排名
+// KEYS[1]=排行榜key ARGV[1]=分数下界 ARGV[2]=是否按时间编码
+var denseRankScript = redis.NewScript(`
+local scores = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[1], "+inf", "WITHSCORES")
+local seen = {}
+local n = 0
+for i = 2, #scores, 2 do
+	local s = tonumber(scores[i])
+	if ARGV[2] == "1" then
+		s = math.floor(s)
+	end
+	if not seen[s] then
+		seen[s] = true
+		n = n + 1
+	end
+end
+return n
+`)
+
+// normalizeScript 累加榜合并后重新编码分数：各来源原始分数求和，时间小数取最晚达到者
+// KEYS[1]=合并结果key KEYS[2..]=来源key
+var normalizeScript = redis.NewScript(`
+local members = redis.call("ZRANGE", KEYS[1], 0, -1)
+for _, m in ipairs(members) do
+	local sum = 0
+	local frac = 1
+	for i = 2, #KEYS do
+		local s = redis.call("ZSCORE", KEYS[i], m)
+		if s then
+			s = tonumber(s)
+			local raw = math.floor(s)
+			sum = sum + raw
+			frac = math.min(frac, s - raw)
+		end
+	end
+	redis.call("ZADD", KEYS[1], sum + frac, m)
+end
+return #members
+`)
+
+// Options 定义了排行榜的配置选项
+type Options struct {
+	Mode      SubmitMode     // 提交分数的方式
+	TieBreak  bool           // 同分时是否按提交时间排序（先提交者在前），开启后分数必须为整数
+	Period    Period         // 排行榜周期，非总榜时按周期自动切换key
+	Retention time.Duration  // 周期结束后排行榜的保留时长，为0时不过期，总榜忽略该配置
+	Location  *time.Location // 计算周期边界使用的时区
+}
+
+// DefaultOptions 返回一个包含推荐默认值的排行榜配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		Mode:      KeepMax,    // 默认保留最高分
+		TieBreak:  true,       // 默认同分先到者在前
+		Period:    AllTime,    // 默认总榜
+		Retention: 0,          // 默认不过期
+		Location:  time.Local, // 默认本地时区
+	}
+}
+
+// Entry 排行榜条目
+type Entry struct {
+	Member string  // 成员
+	Score  float64 // 原始分数
+	Rank   int64   // 顺序排名，从1开始
+}
+
+// Client 排行榜客户端
+type Client struct {
+	rdb  *redis.Client
+	zset *zsetpkg.Client
+}
+
+// New 创建排行榜客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, zset: zsetpkg.New(rdb)}
+}
+
+// Board 创建排行榜，opts为nil时使用默认配置
+func (c *Client) Board(name string, opts *Options) *Board {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Location == nil {
+		o := *opts
+		o.Location = time.Local
+		opts = &o
+	}
+	return &Board{c: c, name: name, opts: opts}
+}
+
+// Board 排行榜
+type Board struct {
+	c    *Client
+	name string
+	opts *Options
+	at   time.Time // 固定查看的时间点，零值表示当前周期
+}
+
+// At 返回查看t所在周期的排行榜视图，用于查询历史周期
+func (b *Board) At(t time.Time) *Board {
+	return &Board{c: b.c, name: b.name, opts: b.opts, at: t}
+}
+
+// Previous 返回上一周期的排行榜视图
+func (b *Board) Previous() *Board {
+	return b.At(b.periodStart(b.now()).Add(-time.Nanosecond))
+}
+
+// Key 返回当前周期排行榜的key
+func (b *Board) Key() string {
+	return b.keyAt(b.now())
+}
+
+// Submit 提交成员的分数
+// 参数:
+//   - ctx: 上下文
+//   - member: 成员
+//   - score: 分数，开启TieBreak时必须为整数
+//
+// 返回:
+//   - 提交后成员的分数（按提交方式处理后的结果）
+//   - 错误信息
+func (b *Board) Submit(ctx context.Context, member string, score float64) (float64, error) {
+
+	//1.校验分数
+	if b.opts.TieBreak && score != math.Trunc(score) {
+		return 0, ErrNonIntegerScore
+	}
+
+	//2.计算时间小数部分与过期时间
+	now := b.now()
+	frac := "0"
+	if b.opts.TieBreak {
+		frac = strconv.FormatFloat(tieFraction(now), 'f', -1, 64)
+	}
+	var expireAt int64
+	if b.opts.Period != AllTime && b.opts.Retention > 0 {
+		expireAt = b.periodEnd(now).Add(b.opts.Retention).UnixMilli()
+	}
+
+	//3.原子提交
+	mode := map[SubmitMode]string{KeepMax: "max", KeepLatest: "latest", Accumulate: "sum"}[b.opts.Mode]
+	v, err := submitScript.Run(ctx, b.c.rdb, []string{b.keyAt(now)}, mode, member, score, frac, expireAt).Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// Score 返回成员的原始分数
+func (b *Board) Score(ctx context.Context, member string) (float64, error) {
+	s, err := b.c.rdb.ZScore(ctx, b.Key(), member).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrMemberNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return b.decode(s), nil
+}
+
+// Rank 返回成员的排名，从1开始
+func (b *Board) Rank(ctx context.Context, member string, mode RankMode) (int64, error) {
+
+	//1.顺序排名直接使用ZREVRANK
+	key := b.Key()
+	if mode == Ordinal {
+		r, err := b.c.zset.ZRevRank(ctx, key, member)
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrMemberNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+		return r + 1, nil
+	}
+
+	//2.计算比成员分数更高的下界
+	score, err := b.Score(ctx, member)
+	if err != nil {
+		return 0, err
+	}
+	higher := zsetpkg.Excl(score)
+	if b.opts.TieBreak {
+		higher = zsetpkg.Incl(score + 1)
+	}
+
+	//3.标准排名 = 分数更高的成员数 + 1
+	var n int64
+	if mode == Standard {
+		if n, err = b.c.zset.ZCount(ctx, key, zsetpkg.Between(higher, zsetpkg.PosInf)); err != nil {
+			return 0, err
+		}
+		return n + 1, nil
+	}
+
+	//4.密集排名 = 更高的不同分数个数 + 1
+	tie := "0"
+	if b.opts.TieBreak {
+		tie = "1"
+	}
+	if n, err = denseRankScript.Run(ctx, b.c.rdb, []string{key}, higher.ScoreArg(), tie).Int64(); err != nil {
+		return 0, err
+	}
+	return n + 1, nil
+}
+
+// Top 分页查询排行榜
+// 参数:
+//   - ctx: 上下文
+//   - page: 页码，从1开始
+//   - size: 每页条数
+//
+// 返回:
+//   - 条目列表
+//   - 错误信息
+func (b *Board) Top(ctx context.Context, page, size int64) ([]Entry, error) {
+	if page < 1 || size < 1 {
+		return nil, nil
+	}
+	start := (page - 1) * size
+	return b.rangeEntries(ctx, start, start+size-1)
+}
+
+// Around 返回成员及其前后各n名的条目
+func (b *Board) Around(ctx context.Context, member string, n int64) ([]Entry, error) {
+	r, err := b.c.zset.ZRevRank(ctx, b.Key(), member)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.rangeEntries(ctx, max(r-n, 0), r+n)
+}
+
+// Count 返回排行榜的成员数
+func (b *Board) Count(ctx context.Context) (int64, error) {
+	return b.c.zset.ZCard(ctx, b.Key())
+}
+
+// Remove 从排行榜中移除成员
+func (b *Board) Remove(ctx context.Context, members ...string) (int64, error) {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return b.c.zset.ZRem(ctx, b.Key(), args...)
+}
+
+// Merge 使用ZUNIONSTORE将多个周期的排行榜合并到dest，例如将7个日榜合并为周榜
+// 保留最高分的排行榜按最大值合并，累加榜按求和合并；
+// 保留最近分数的排行榜无法从分数中得知各来源的提交先后，返回ErrMergeUnsupported
+// 参数:
+//   - ctx: 上下文
+//   - dest: 合并结果的key
+//   - ttl: 合并结果的过期时间，为0时不过期
+//   - from: 参与合并的排行榜视图
+//
+// 返回:
+//   - 合并后的成员数
+//   - 错误信息，没有来源排行榜时返回ErrNoSource
+func (b *Board) Merge(ctx context.Context, dest string, ttl time.Duration, from ...*Board) (int64, error) {
+	if b.opts.Mode == KeepLatest {
+		return 0, ErrMergeUnsupported
+	}
+	if len(from) == 0 {
+		return 0, ErrNoSource
+	}
+
+	//1.收集来源key
+	keys := make([]string, len(from))
+	for i, f := range from {
+		keys[i] = f.Key()
+	}
+
+	//2.按提交方式选择聚合方式并合并
+	aggregate := "MAX"
+	if b.opts.Mode == Accumulate {
+		aggregate = "SUM"
+	}
+	n, err := b.c.rdb.ZUnionStore(ctx, dest, &redis.ZStore{Keys: keys, Aggregate: aggregate}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	//3.累加榜求和后时间小数部分失真，需要重新编码
+	if aggregate == "SUM" && b.opts.TieBreak && n > 0 {
+		if err = normalizeScript.Run(ctx, b.c.rdb, append([]string{dest}, keys...)).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	//4.设置过期时间
+	if ttl > 0 {
+		if err = b.c.rdb.Expire(ctx, dest, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// rangeEntries 按排名区间查询条目
+func (b *Board) rangeEntries(ctx context.Context, start, stop int64) ([]Entry, error) {
+	zs, err := b.c.zset.ZRevRangeWithScores(ctx, b.Key(), start, stop)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		entries[i] = Entry{Member: member, Score: b.decode(z.Score), Rank: start + int64(i) + 1}
+	}
+	return entries, nil
+}
+
+// decode 将有序集合中的分数解码为原始分数
+func (b *Board) decode(s float64) float64 {
+	if b.opts.TieBreak {
+		return math.Floor(s)
+	}
+	return s
+}
+
+// now 返回排行榜视图对应的时间
+func (b *Board) now() time.Time {
+	if b.at.IsZero() {
+		return time.Now().In(b.opts.Location)
+	}
+	return b.at.In(b.opts.Location)
+}
+
+// keyAt 返回t所在周期的排行榜key
+func (b *Board) keyAt(t time.Time) string {
+	switch b.opts.Period {
+	case Daily:
+		return b.name + ":" + t.Format("20060102")
+	case Weekly:
+		year, week := t.ISOWeek()
+		return b.name + ":" + strconv.Itoa(year) + "W" + twoDigits(week)
+	case Monthly:
+		return b.name + ":" + t.Format("200601")
+	default:
+		return b.name
+	}
+}
+
+// periodStart 返回t所在周期的开始时间
+func (b *Board) periodStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch b.opts.Period {
+	case Weekly:
+		offset := (int(day.Weekday()) + 6) % 7 // ISO周从周一开始
+		return day.AddDate(0, 0, -offset)
+	case Monthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+// periodEnd 返回t所在周期的结束时间
+func (b *Board) periodEnd(t time.Time) time.Time {
+	start := b.periodStart(t)
+	switch b.opts.Period {
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Monthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// tieFraction 计算提交时间对应的小数部分，提交越早值越大，取值范围[0, 1)
+func tieFraction(t time.Time) float64 {
+	d := min(max(t.Unix()-tieEpoch, 0), tieSpan-1)
+	return float64(tieSpan-1-d) / tieSpan
+}
+
+// twoDigits 将数字格式化为两位字符串
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
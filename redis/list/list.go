@@ -8,6 +8,22 @@ import (
 	"time"
 )
 
+// Direction 列表的操作方向
+type Direction string
+
+const (
+	Left  Direction = "LEFT"  // 左端（头部）
+	Right Direction = "RIGHT" // 右端（尾部）
+)
+
+// InsertPosition 插入元素时相对于目标元素的位置
+type InsertPosition string
+
+const (
+	Before InsertPosition = "BEFORE" // 目标元素之前
+	After  InsertPosition = "AFTER"  // 目标元素之后
+)
+
 // Client Redis列表操作客户端
 type Client struct {
 	rdb *redis.Client
@@ -54,8 +70,8 @@ func (c *Client) LIndex(ctx context.Context, key string, index int64) (string, e
 }
 
 // LInsert 在目标元素前或后插入元素
-func (c *Client) LInsert(ctx context.Context, key, op string, pivot, value interface{}) (int64, error) {
-	return c.rdb.LInsert(ctx, key, op, pivot, value).Result()
+func (c *Client) LInsert(ctx context.Context, key string, pos InsertPosition, pivot, value interface{}) (int64, error) {
+	return c.rdb.LInsert(ctx, key, string(pos), pivot, value).Result()
 }
 
 // LRange 获取指定范围的元素
@@ -83,12 +99,56 @@ func (c *Client) LTrim(ctx context.Context, key string, start, stop int64) error
 	return c.rdb.LTrim(ctx, key, start, stop).Err()
 }
 
-// RPopLPush 右边弹出，左边推入
+// LPopCount 左端弹出count个元素
+func (c *Client) LPopCount(ctx context.Context, key string, count int) ([]string, error) {
+	return c.rdb.LPopCount(ctx, key, count).Result()
+}
+
+// RPopCount 右端弹出count个元素
+func (c *Client) RPopCount(ctx context.Context, key string, count int) ([]string, error) {
+	return c.rdb.RPopCount(ctx, key, count).Result()
+}
+
+// LMove 从source的srcDir端弹出元素，推入destination的destDir端（Redis 6.2+，替代RPopLPush）
+func (c *Client) LMove(ctx context.Context, source, destination string, srcDir, destDir Direction) (string, error) {
+	return c.rdb.LMove(ctx, source, destination, string(srcDir), string(destDir)).Result()
+}
+
+// BLMove 阻塞式LMove（Redis 6.2+，替代BRPopLPush）
+func (c *Client) BLMove(ctx context.Context, source, destination string, srcDir, destDir Direction, timeout time.Duration) (string, error) {
+	return c.rdb.BLMove(ctx, source, destination, string(srcDir), string(destDir), timeout).Result()
+}
+
+// LMPop 从第一个非空列表的dir端弹出最多count个元素（Redis 7.0+）
+// 返回:
+//   - 弹出元素所在的key
+//   - 弹出的元素
+//   - 错误信息，所有列表均为空时返回redis.Nil
+func (c *Client) LMPop(ctx context.Context, dir Direction, count int64, keys ...string) (string, []string, error) {
+	return c.rdb.LMPop(ctx, string(dir), count, keys...).Result()
+}
+
+// BLMPop 阻塞式LMPop（Redis 7.0+）
+func (c *Client) BLMPop(ctx context.Context, timeout time.Duration, dir Direction, count int64, keys ...string) (string, []string, error) {
+	return c.rdb.BLMPop(ctx, timeout, string(dir), count, keys...).Result()
+}
+
+// LPos 返回第一个匹配value的元素下标，args.Rank指定第几个匹配（负数从尾部开始），args.MaxLen限制比较的元素数
+func (c *Client) LPos(ctx context.Context, key, value string, args redis.LPosArgs) (int64, error) {
+	return c.rdb.LPos(ctx, key, value, args).Result()
+}
+
+// LPosCount 返回最多count个匹配value的元素下标，count为0时返回所有匹配
+func (c *Client) LPosCount(ctx context.Context, key, value string, count int64, args redis.LPosArgs) ([]int64, error) {
+	return c.rdb.LPosCount(ctx, key, value, count, args).Result()
+}
+
+// RPopLPush 右边弹出，左边推入（Redis 6.2起推荐使用LMove）
 func (c *Client) RPopLPush(ctx context.Context, source, destination string) (string, error) {
 	return c.rdb.RPopLPush(ctx, source, destination).Result()
 }
 
-// BRPopLPush 阻塞式右边弹出，左边推入
+// BRPopLPush 阻塞式右边弹出，左边推入（Redis 6.2起推荐使用BLMove）
 func (c *Client) BRPopLPush(ctx context.Context, source, destination string, timeout time.Duration) (string, error) {
 	return c.rdb.BRPopLPush(ctx, source, destination, timeout).Result()
 }
@@ -0,0 +1,284 @@
+// Package lock 提供基于Redis的分布式锁封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-13 10:00:00
+package lock
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	hashpkg "go-redis-demo/redis/hash"
+)
+
+// 哈希锁的模式，保存在锁哈希的mode字段中
+const (
+	modeRead  = "read"
+	modeWrite = "write"
+)
+
+// ownerPrefix 锁哈希中持有者字段的前缀，使任意持有者标识（包括"mode"）都不会与mode字段冲突
+// 脚本中以相同的字面量拼接持有者字段
+const ownerPrefix = "o:"
+
+// hashAcquireScript 获取哈希锁
+// 锁哈希中mode字段记录当前模式，其余字段为"o:持有者"及其重入次数；
+// 等待队列为list，元素为"模式:持有者"，等待者的超时截止时间保存在另一个哈希中，超时的等待者会被清理；
+// 排在自己之前的等待者中存在冲突者（写锁与任意锁冲突）时不允许插队，以保证公平
+// KEYS[1]=锁哈希 KEYS[2]=等待队列 KEYS[3]=等待者截止时间哈希
+// ARGV[1]=持有者 ARGV[2]=锁TTL(毫秒) ARGV[3]=获取失败时是否入队 ARGV[4]=等待者超时(毫秒) ARGV[5]=模式
+var hashAcquireScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local entry = ARGV[5] .. ":" .. ARGV[1]
+local field = "o:" .. ARGV[1]
+
+for _, e in ipairs(redis.call("LRANGE", KEYS[2], 0, -1)) do
+	local deadline = tonumber(redis.call("HGET", KEYS[3], e))
+	if (not deadline) or deadline < now then
+		redis.call("LREM", KEYS[2], 0, e)
+		redis.call("HDEL", KEYS[3], e)
+	end
+end
+
+local held = redis.call("HEXISTS", KEYS[1], field) == 1
+local mode = redis.call("HGET", KEYS[1], "mode")
+local can = false
+if not mode then
+	can = true
+elseif held then
+	can = mode == ARGV[5] or mode == "write"
+elseif mode == "read" and ARGV[5] == "read" then
+	can = true
+end
+
+if can and not held then
+	for _, e in ipairs(redis.call("LRANGE", KEYS[2], 0, -1)) do
+		if e == entry then
+			break
+		end
+		if ARGV[5] == "write" or string.sub(e, 1, 6) == "write:" then
+			can = false
+			break
+		end
+	end
+end
+
+if can then
+	if not mode then
+		redis.call("HSET", KEYS[1], "mode", ARGV[5])
+	end
+	redis.call("HINCRBY", KEYS[1], field, 1)
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	redis.call("LREM", KEYS[2], 0, entry)
+	redis.call("HDEL", KEYS[3], entry)
+	return 1
+end
+
+if ARGV[3] == "1" then
+	if redis.call("HEXISTS", KEYS[3], entry) == 0 then
+		redis.call("RPUSH", KEYS[2], entry)
+	end
+	redis.call("HSET", KEYS[3], entry, now + tonumber(ARGV[4]))
+	redis.call("PEXPIRE", KEYS[2], ARGV[4] * 2)
+	redis.call("PEXPIRE", KEYS[3], ARGV[4] * 2)
+end
+return 0
+`)
+
+// hashReleaseScript 释放一次哈希锁，重入次数归零时删除持有者，无持有者时删除锁
+// KEYS[1]=锁哈希 ARGV[1]=持有者 ARGV[2]=锁TTL(毫秒)
+// 返回剩余重入次数，未持有锁时返回-1
+var hashReleaseScript = redis.NewScript(`
+local field = "o:" .. ARGV[1]
+if redis.call("HEXISTS", KEYS[1], field) == 0 then
+	return -1
+end
+local n = redis.call("HINCRBY", KEYS[1], field, -1)
+if n > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return n
+end
+redis.call("HDEL", KEYS[1], field)
+if redis.call("HLEN", KEYS[1]) <= 1 then
+	redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// hashRefreshScript 持有者重置哈希锁的过期时间
+// KEYS[1]=锁哈希 ARGV[1]=持有者 ARGV[2]=锁TTL(毫秒)
+var hashRefreshScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], "o:" .. ARGV[1]) == 1 then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// hashCancelScript 等待者放弃等待，从等待队列中移除
+// KEYS[1]=等待队列 KEYS[2]=等待者截止时间哈希 ARGV[1]=队列元素
+var hashCancelScript = redis.NewScript(`
+redis.call("LREM", KEYS[1], 0, ARGV[1])
+return redis.call("HDEL", KEYS[2], ARGV[1])
+`)
+
+// NewReentrantLock 创建一把可重入锁，同一owner可多次获取，释放相同次数后锁才真正释放
+// owner为空时随机生成，opts为nil时使用默认配置
+func (c *Client) NewReentrantLock(key, owner string, opts *Options) *HashLock {
+	return c.newHashLock(key, owner, modeWrite, opts)
+}
+
+// NewRWLock 创建一把读写锁，允许多个读者或一个写者同时持有
+// owner为空时随机生成，opts为nil时使用默认配置
+func (c *Client) NewRWLock(key, owner string, opts *Options) *RWLock {
+	if owner == "" {
+		owner = newToken()
+	}
+	return &RWLock{
+		read:  c.newHashLock(key, owner, modeRead, opts),
+		write: c.newHashLock(key, owner, modeWrite, opts),
+	}
+}
+
+// newHashLock 创建指定模式的哈希锁
+func (c *Client) newHashLock(key, owner, mode string, opts *Options) *HashLock {
+	if owner == "" {
+		owner = newToken()
+	}
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &HashLock{rdb: c.rdb, hash: c.hash, key: key, owner: owner, mode: mode, opts: opts}
+}
+
+// RWLock 基于哈希的公平读写锁
+type RWLock struct {
+	read  *HashLock
+	write *HashLock
+}
+
+// ReadLock 返回读锁
+func (l *RWLock) ReadLock() *HashLock {
+	return l.read
+}
+
+// WriteLock 返回写锁
+func (l *RWLock) WriteLock() *HashLock {
+	return l.write
+}
+
+// HashLock 基于哈希的可重入锁，持有者标识（加ownerPrefix前缀）及其重入次数保存在哈希字段中
+// 阻塞获取时按等待队列的顺序公平获取
+type HashLock struct {
+	rdb   *redis.Client
+	hash  *hashpkg.Client
+	key   string
+	owner string
+	mode  string
+	opts  *Options
+}
+
+// TryLock 尝试获取一次锁，有其他等待者排在前面时同样视为失败
+func (l *HashLock) TryLock(ctx context.Context) (bool, error) {
+	return l.acquire(ctx, false)
+}
+
+// Lock 获取锁，失败时进入等待队列并按配置重试，放弃时从等待队列中移除
+func (l *HashLock) Lock(ctx context.Context) error {
+
+	//1.重试获取锁，每次失败都会刷新自己在等待队列中的截止时间
+	err := retry(ctx, l.opts, func(ctx context.Context) (bool, error) {
+		return l.acquire(ctx, true)
+	})
+	if err == nil {
+		return nil
+	}
+
+	//2.获取失败，从等待队列中移除
+	cancelCtx := context.WithoutCancel(ctx)
+	_ = hashCancelScript.Run(cancelCtx, l.rdb, []string{l.queueKey(), l.waitersKey()}, l.entry()).Err()
+	return err
+}
+
+// Unlock 释放一次锁，重入次数归零时锁才真正释放
+func (l *HashLock) Unlock(ctx context.Context) error {
+	n, err := hashReleaseScript.Run(ctx, l.rdb, []string{l.key}, l.owner, l.opts.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Refresh 将锁的有效期重置为TTL
+func (l *HashLock) Refresh(ctx context.Context) error {
+	n, err := hashRefreshScript.Run(ctx, l.rdb, []string{l.key}, l.owner, l.opts.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// HoldCount 返回当前持有者的重入次数，未持有时返回0
+func (l *HashLock) HoldCount(ctx context.Context) (int64, error) {
+	v, err := l.hash.HGet(ctx, l.key, ownerPrefix+l.owner)
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// Key 返回锁的key
+func (l *HashLock) Key() string {
+	return l.key
+}
+
+// Token 返回当前持有者的唯一标识
+func (l *HashLock) Token() string {
+	return l.owner
+}
+
+// acquire 执行获取脚本，enqueue为true时获取失败会进入等待队列
+func (l *HashLock) acquire(ctx context.Context, enqueue bool) (bool, error) {
+	flag := "0"
+	if enqueue {
+		flag = "1"
+	}
+	keys := []string{l.key, l.queueKey(), l.waitersKey()}
+	n, err := hashAcquireScript.Run(ctx, l.rdb, keys, l.owner, l.opts.TTL.Milliseconds(), flag, l.waitTimeout().Milliseconds(), l.mode).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// waitTimeout 返回等待者的超时时间，等待者超过该时间未重试则视为已放弃
+// 重试间隔最多叠加50%的抖动，取4倍重试间隔可以保证正常等待者不会被误清理
+func (l *HashLock) waitTimeout() time.Duration {
+	return 4*l.opts.RetryDelay + 100*time.Millisecond
+}
+
+// entry 返回自己在等待队列中的元素
+func (l *HashLock) entry() string {
+	return l.mode + ":" + l.owner
+}
+
+// queueKey 返回等待队列的key
+func (l *HashLock) queueKey() string {
+	return l.key + ":queue"
+}
+
+// waitersKey 返回等待者截止时间哈希的key
+func (l *HashLock) waitersKey() string {
+	return l.key + ":waiters"
+}
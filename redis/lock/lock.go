@@ -0,0 +1,203 @@
+// Package lock 提供基于Redis的分布式锁封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-12 10:00:00
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	hashpkg "go-redis-demo/redis/hash"
+)
+
+var (
+	// ErrNotAcquired 在重试次数耗尽后仍未获取到锁
+	ErrNotAcquired = errors.New("lock: 未能获取到锁")
+
+	// ErrLockNotHeld 锁已过期或被其他持有者占用，无法释放或续期
+	ErrLockNotHeld = errors.New("lock: 当前并未持有该锁")
+
+	// ErrInvalidTTL Redlock的TTL不大于时钟漂移补偿，加锁后不可能有剩余有效期
+	ErrInvalidTTL = errors.New("lock: TTL过小，扣除时钟漂移后没有有效期")
+)
+
+// unlockScript 校验token后删除锁，避免误删其他持有者的锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// refreshScript 校验token后重置锁的过期时间
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Lock 分布式锁接口，单实例锁与Redlock均实现该接口，调用方可通过配置切换
+type Lock interface {
+	// TryLock 尝试获取一次锁，不进行重试
+	TryLock(ctx context.Context) (bool, error)
+	// Lock 获取锁，失败时按配置重试，重试耗尽返回ErrNotAcquired
+	Lock(ctx context.Context) error
+	// Unlock 释放锁
+	Unlock(ctx context.Context) error
+	// Refresh 将锁的有效期重置为TTL
+	Refresh(ctx context.Context) error
+	// Key 返回锁的key
+	Key() string
+	// Token 返回当前持有者的唯一标识
+	Token() string
+}
+
+// Factory 锁工厂接口，根据key创建锁
+type Factory interface {
+	NewLock(key string, opts *Options) Lock
+}
+
+// Options 定义了分布式锁的配置选项
+type Options struct {
+	TTL         time.Duration // 锁的有效期
+	RetryCount  int           // 获取失败时的最大重试次数
+	RetryDelay  time.Duration // 每次重试前的等待时间（会叠加随机抖动）
+	DriftFactor float64       // 时钟漂移系数，仅Redlock使用
+}
+
+// DefaultOptions 返回一个包含推荐默认值的锁配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		TTL:         10 * time.Second,       // 默认10秒有效期
+		RetryCount:  32,                     // 默认重试32次
+		RetryDelay:  100 * time.Millisecond, // 默认重试间隔100毫秒
+		DriftFactor: 0.01,                   // Redlock推荐的时钟漂移系数
+	}
+}
+
+// Client 单实例分布式锁客户端
+type Client struct {
+	rdb  *redis.Client
+	hash *hashpkg.Client
+}
+
+// New 创建单实例分布式锁客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, hash: hashpkg.New(rdb)}
+}
+
+// NewLock 创建一把单实例互斥锁，opts为nil时使用默认配置
+func (c *Client) NewLock(key string, opts *Options) Lock {
+	return c.NewMutex(key, opts)
+}
+
+// NewMutex 创建一把单实例互斥锁，opts为nil时使用默认配置
+func (c *Client) NewMutex(key string, opts *Options) *Mutex {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &Mutex{rdb: c.rdb, key: key, token: newToken(), opts: opts}
+}
+
+// Mutex 基于 SET NX PX 的单实例互斥锁
+type Mutex struct {
+	rdb   *redis.Client
+	key   string
+	token string
+	opts  *Options
+}
+
+// TryLock 尝试获取一次锁
+func (m *Mutex) TryLock(ctx context.Context) (bool, error) {
+	return m.rdb.SetNX(ctx, m.key, m.token, m.opts.TTL).Result()
+}
+
+// Lock 获取锁，失败时按配置重试
+func (m *Mutex) Lock(ctx context.Context) error {
+	return retry(ctx, m.opts, m.TryLock)
+}
+
+// Unlock 释放锁
+func (m *Mutex) Unlock(ctx context.Context) error {
+	n, err := unlockScript.Run(ctx, m.rdb, []string{m.key}, m.token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Refresh 将锁的有效期重置为TTL
+func (m *Mutex) Refresh(ctx context.Context) error {
+	n, err := refreshScript.Run(ctx, m.rdb, []string{m.key}, m.token, m.opts.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Key 返回锁的key
+func (m *Mutex) Key() string {
+	return m.key
+}
+
+// Token 返回当前持有者的唯一标识
+func (m *Mutex) Token() string {
+	return m.token
+}
+
+// retry 按配置反复调用try，直到成功、出错、重试耗尽或上下文结束
+func retry(ctx context.Context, opts *Options, try func(ctx context.Context) (bool, error)) error {
+	for i := 0; i <= opts.RetryCount; i++ {
+
+		//1.非首次尝试前等待一段带抖动的时间
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(jitter(opts.RetryDelay)):
+			}
+		}
+
+		//2.尝试获取锁
+		ok, err := try(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return ErrNotAcquired
+}
+
+// jitter 在基础等待时间上叠加[0, d/2)的随机抖动，避免多个竞争者同时重试
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	var n uint64
+	for _, v := range b {
+		n = n<<8 | uint64(v)
+	}
+	return d + time.Duration(n%uint64(d/2+1))
+}
+
+// newToken 生成随机的锁持有者标识
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
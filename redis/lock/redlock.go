@@ -0,0 +1,213 @@
+// Package lock 提供基于Redis的分布式锁封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-12 10:00:00
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// minOpTimeout 单个实例操作超时时间的下限，TTL很小时TTL/10可能为0，导致所有操作立即超时
+const minOpTimeout = time.Millisecond
+
+// Redlock 基于多个相互独立Redis实例的分布式锁客户端（Redlock算法）
+type Redlock struct {
+	rdbs []*redis.Client
+}
+
+// NewRedlock 创建Redlock客户端，rdbs应指向相互独立的Redis实例
+func NewRedlock(rdbs ...*redis.Client) *Redlock {
+	return &Redlock{rdbs: rdbs}
+}
+
+// NewLock 创建一把Redlock互斥锁，opts为nil时使用默认配置
+func (r *Redlock) NewLock(key string, opts *Options) Lock {
+	return r.NewMutex(key, opts)
+}
+
+// NewMutex 创建一把Redlock互斥锁，opts为nil时使用默认配置
+func (r *Redlock) NewMutex(key string, opts *Options) *RedMutex {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &RedMutex{rdbs: r.rdbs, key: key, token: newToken(), opts: opts}
+}
+
+// Quorum 返回获取锁所需的最少实例数（N/2+1）
+func (r *Redlock) Quorum() int {
+	return len(r.rdbs)/2 + 1
+}
+
+// Close 关闭所有实例的连接
+func (r *Redlock) Close() error {
+	var firstErr error
+	for _, rdb := range r.rdbs {
+		if err := rdb.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// RedMutex Redlock互斥锁
+type RedMutex struct {
+	rdbs  []*redis.Client
+	key   string
+	token string
+	opts  *Options
+
+	mu      sync.Mutex
+	validAt time.Time // 锁的有效截止时间
+}
+
+// TryLock 尝试在多数实例上获取一次锁
+// 只有在不少于N/2+1个实例上加锁成功，且扣除耗时与时钟漂移后仍有剩余有效期时才视为成功，
+// 否则会释放所有实例上已加的锁；TTL不大于时钟漂移补偿时直接返回ErrInvalidTTL
+func (m *RedMutex) TryLock(ctx context.Context) (bool, error) {
+	if m.opts.TTL <= m.drift() {
+		return false, ErrInvalidTTL
+	}
+
+	//1.记录开始时间，并在所有实例上尝试加锁
+	start := time.Now()
+	n, err := m.acquireAll(ctx, func(ctx context.Context, rdb *redis.Client) (bool, error) {
+		return rdb.SetNX(ctx, m.key, m.token, m.opts.TTL).Result()
+	})
+
+	//2.计算剩余有效期 = TTL - 耗时 - 时钟漂移
+	validity := m.opts.TTL - time.Since(start) - m.drift()
+
+	//3.满足多数派且仍有有效期则加锁成功
+	if n >= m.quorum() && validity > 0 {
+		m.setValidUntil(start.Add(m.opts.TTL - m.drift()))
+		return true, nil
+	}
+
+	//4.加锁失败，释放所有实例上的锁
+	_, _ = m.releaseAll(ctx)
+	if n < m.quorum() && err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// Lock 获取锁，失败时按配置重试
+func (m *RedMutex) Lock(ctx context.Context) error {
+	return retry(ctx, m.opts, m.TryLock)
+}
+
+// Unlock 释放所有实例上的锁，未在多数实例上释放成功时返回ErrLockNotHeld
+func (m *RedMutex) Unlock(ctx context.Context) error {
+	n, err := m.releaseAll(ctx)
+	m.setValidUntil(time.Time{})
+	if n >= m.quorum() {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ErrLockNotHeld
+}
+
+// Refresh 在所有实例上将锁的有效期重置为TTL，未在多数实例上续期成功时返回ErrLockNotHeld
+func (m *RedMutex) Refresh(ctx context.Context) error {
+	if m.opts.TTL <= m.drift() {
+		return ErrInvalidTTL
+	}
+
+	//1.在所有实例上续期
+	start := time.Now()
+	n, err := m.acquireAll(ctx, func(ctx context.Context, rdb *redis.Client) (bool, error) {
+		v, err := refreshScript.Run(ctx, rdb, []string{m.key}, m.token, m.opts.TTL.Milliseconds()).Int64()
+		return v == 1, err
+	})
+
+	//2.满足多数派且仍有有效期则续期成功
+	if n >= m.quorum() && m.opts.TTL-time.Since(start)-m.drift() > 0 {
+		m.setValidUntil(start.Add(m.opts.TTL - m.drift()))
+		return nil
+	}
+	if n < m.quorum() && err != nil {
+		return err
+	}
+	return ErrLockNotHeld
+}
+
+// Validity 返回锁的剩余有效时间，未持有锁或已过期时返回0
+func (m *RedMutex) Validity() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d := time.Until(m.validAt); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Key 返回锁的key
+func (m *RedMutex) Key() string {
+	return m.key
+}
+
+// Token 返回当前持有者的唯一标识
+func (m *RedMutex) Token() string {
+	return m.token
+}
+
+// acquireAll 并发地在所有实例上执行op，返回成功的实例数和遇到的第一个错误
+// 每个实例的操作超时时间远小于TTL，避免单个故障实例拖慢整体加锁
+func (m *RedMutex) acquireAll(ctx context.Context, op func(ctx context.Context, rdb *redis.Client) (bool, error)) (int, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		n        int
+		firstErr error
+	)
+	timeout := max(m.opts.TTL/10, minOpTimeout)
+	for _, rdb := range m.rdbs {
+		wg.Add(1)
+		go func(rdb *redis.Client) {
+			defer wg.Done()
+			opCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			ok, err := op(opCtx, rdb)
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				n++
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}(rdb)
+	}
+	wg.Wait()
+	return n, firstErr
+}
+
+// releaseAll 在所有实例上释放锁，返回成功释放的实例数
+func (m *RedMutex) releaseAll(ctx context.Context) (int, error) {
+	return m.acquireAll(ctx, func(ctx context.Context, rdb *redis.Client) (bool, error) {
+		v, err := unlockScript.Run(ctx, rdb, []string{m.key}, m.token).Int64()
+		return v == 1, err
+	})
+}
+
+// quorum 返回多数派数量
+func (m *RedMutex) quorum() int {
+	return len(m.rdbs)/2 + 1
+}
+
+// drift 返回时钟漂移补偿 = TTL * DriftFactor + 2ms
+func (m *RedMutex) drift() time.Duration {
+	return time.Duration(float64(m.opts.TTL)*m.opts.DriftFactor) + 2*time.Millisecond
+}
+
+// setValidUntil 设置锁的有效截止时间
+func (m *RedMutex) setValidUntil(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validAt = t
+}
@@ -0,0 +1,507 @@
+// Package queue 提供基于Redis列表的可靠工作队列封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-20 10:00:00
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	hashpkg "go-redis-demo/redis/hash"
+	listpkg "go-redis-demo/redis/list"
+	setpkg "go-redis-demo/redis/set"
+	stringpkg "go-redis-demo/redis/string"
+)
+
+var (
+	// ErrInvalidPriority 优先级超出范围
+	ErrInvalidPriority = errors.New("queue: 优先级超出范围")
+
+	// ErrMessageNotHeld 消息不在当前消费者的处理中列表，可能已被确认或已被回收
+	ErrMessageNotHeld = errors.New("queue: 消息不在当前消费者的处理中列表")
+)
+
+// 每个队列由以下key组成：
+//   - {queue}:pending:{p}       列表，优先级为p的待消费消息ID，p越小优先级越高
+//   - {queue}:signal            列表，生产消息时写入的唤醒信号，供阻塞的消费者BLPOP等待
+//   - {queue}:processing:{id}   列表，消费者id已取出但尚未确认的消息ID
+//   - {queue}:heartbeat:{id}    字符串，消费者id的心跳，过期即视为消费者已死亡
+//   - {queue}:consumers         集合，已注册的消费者id
+//   - {queue}:bodies            哈希，消息ID -> 消息内容
+//   - {queue}:attempts          哈希，消息ID -> 已失败次数
+//   - {queue}:dead              列表，超过最大重试次数的消息ID（死信）
+// 消息ID以优先级为前缀，重新入队时据此放回原优先级的列表
+
+// reserveScript 按优先级从高到低，将第一个可用的消息原子地移动到处理中列表
+// KEYS[1]=处理中列表 KEYS[2..]=按优先级排列的待消费列表
+var reserveScript = redis.NewScript(`
+for i = 2, #KEYS do
+	local id = redis.call("RPOPLPUSH", KEYS[i], KEYS[1])
+	if id then
+		return id
+	end
+end
+return false
+`)
+
+// ackScript 确认消息，删除消息内容与重试次数
+// KEYS[1]=处理中列表 KEYS[2]=消息内容哈希 KEYS[3]=重试次数哈希 ARGV[1]=消息ID
+var ackScript = redis.NewScript(`
+local n = redis.call("LREM", KEYS[1], -1, ARGV[1])
+if n > 0 then
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	redis.call("HDEL", KEYS[3], ARGV[1])
+end
+return n
+`)
+
+// nackScript 消息处理失败，失败次数加一后重新入队，超过最大重试次数时移入死信列表
+// KEYS[1]=源列表 KEYS[2]=重试次数哈希 KEYS[3]=待消费列表 KEYS[4]=死信列表 KEYS[5]=唤醒信号列表
+// ARGV[1]=最大重试次数 ARGV[2]=消息ID
+// 返回0表示源列表中不存在该消息，1表示已重新入队，2表示已移入死信列表
+var nackScript = redis.NewScript(`
+if redis.call("LREM", KEYS[1], -1, ARGV[2]) == 0 then
+	return 0
+end
+if redis.call("HINCRBY", KEYS[2], ARGV[2], 1) > tonumber(ARGV[1]) then
+	redis.call("LPUSH", KEYS[4], ARGV[2])
+	return 2
+end
+redis.call("LPUSH", KEYS[3], ARGV[2])
+redis.call("LPUSH", KEYS[5], 1)
+redis.call("LTRIM", KEYS[5], 0, 1023)
+return 1
+`)
+
+// requeueDeadScript 将死信列表末尾的消息原子地重置失败次数并重新入队
+// 消息所在的待消费列表取决于消息ID中的优先级，因此由调用方先读取末尾的消息ID，脚本中确认仍是该消息后再移动
+// KEYS[1]=死信列表 KEYS[2]=重试次数哈希 KEYS[3]=待消费列表 KEYS[4]=唤醒信号列表 ARGV[1]=消息ID
+// 返回0表示末尾的消息已不是该消息，1表示已重新入队
+var requeueDeadScript = redis.NewScript(`
+if redis.call("LINDEX", KEYS[1], -1) ~= ARGV[1] then
+	return 0
+end
+redis.call("RPOP", KEYS[1])
+redis.call("HDEL", KEYS[2], ARGV[1])
+redis.call("LPUSH", KEYS[3], ARGV[1])
+redis.call("LPUSH", KEYS[4], 1)
+redis.call("LTRIM", KEYS[4], 0, 1023)
+return 1
+`)
+
+// Options 定义了工作队列的配置选项
+type Options struct {
+	Priorities int           // 优先级数量，优先级取值为[0, Priorities)，0最高
+	MaxRetries int           // 最大重试次数，失败次数超过该值的消息移入死信列表
+	Visibility time.Duration // 可见性超时，消费者超过该时间没有心跳即视为死亡，其消息会被回收
+}
+
+// DefaultOptions 返回一个包含推荐默认值的工作队列配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		Priorities: 1,                // 默认不区分优先级
+		MaxRetries: 3,                // 默认最多重试3次
+		Visibility: 30 * time.Second, // 默认30秒可见性超时
+	}
+}
+
+// Message 队列消息
+type Message struct {
+	ID       string // 消息ID
+	Body     string // 消息内容
+	Priority int    // 优先级
+	Attempts int64  // 已失败次数
+}
+
+// Stats 队列深度指标
+type Stats struct {
+	Pending    []int64 // 各优先级待消费的消息数
+	Processing int64   // 所有消费者处理中的消息数
+	Dead       int64   // 死信消息数
+	Consumers  int64   // 已注册的消费者数
+}
+
+// Client 工作队列客户端
+type Client struct {
+	rdb    *redis.Client
+	list   *listpkg.Client
+	hash   *hashpkg.Client
+	set    *setpkg.Client
+	string *stringpkg.Client
+}
+
+// New 创建工作队列客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{
+		rdb:    rdb,
+		list:   listpkg.New(rdb),
+		hash:   hashpkg.New(rdb),
+		set:    setpkg.New(rdb),
+		string: stringpkg.New(rdb),
+	}
+}
+
+// Queue 创建工作队列，opts为nil时使用默认配置
+func (c *Client) Queue(name string, opts *Options) *Queue {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Priorities < 1 {
+		o := *opts
+		o.Priorities = 1
+		opts = &o
+	}
+	return &Queue{c: c, name: name, opts: opts}
+}
+
+// Queue 可靠工作队列
+type Queue struct {
+	c    *Client
+	name string
+	opts *Options
+}
+
+// Produce 生产一条消息
+// 参数:
+//   - ctx: 上下文
+//   - body: 消息内容
+//   - priority: 优先级，取值为[0, Priorities)，0最高
+//
+// 返回:
+//   - 消息ID
+//   - 错误信息
+func (q *Queue) Produce(ctx context.Context, body string, priority int) (string, error) {
+
+	//1.校验优先级
+	if priority < 0 || priority >= q.opts.Priorities {
+		return "", ErrInvalidPriority
+	}
+
+	//2.保存消息内容、写入待消费列表并发出唤醒信号
+	id := strconv.Itoa(priority) + "-" + newID()
+	_, err := q.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, q.key("bodies"), id, body)
+		pipe.LPush(ctx, q.pendingKey(priority), id)
+		pipe.LPush(ctx, q.key("signal"), 1)
+		pipe.LTrim(ctx, q.key("signal"), 0, 1023)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Consumer 创建消费者，id在队列内唯一，同一id重启后可以继续处理遗留的消息
+func (q *Queue) Consumer(id string) *Consumer {
+	return &Consumer{q: q, id: id}
+}
+
+// Recover 回收已死亡消费者的处理中消息，重新入队并计一次失败
+// 返回:
+//   - 回收的消息数
+//   - 错误信息
+func (q *Queue) Recover(ctx context.Context) (int64, error) {
+
+	//1.获取所有已注册的消费者
+	consumers, err := q.c.set.SMembers(ctx, q.key("consumers"))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, id := range consumers {
+		//2.心跳仍有效的消费者跳过
+		alive, err := q.c.string.Exists(ctx, q.heartbeatKey(id))
+		if err != nil {
+			return total, err
+		}
+		if alive > 0 {
+			continue
+		}
+
+		//3.回收其处理中的消息并注销
+		processing := q.processingKey(id)
+		ids, err := q.c.list.LRange(ctx, processing, 0, -1)
+		if err != nil {
+			return total, err
+		}
+		for _, mid := range ids {
+			if _, err = q.nack(ctx, processing, mid); err != nil {
+				return total, err
+			}
+		}
+		if _, err = q.c.set.SRem(ctx, q.key("consumers"), id); err != nil {
+			return total, err
+		}
+		total += int64(len(ids))
+	}
+	return total, nil
+}
+
+// Stats 返回队列深度指标
+func (q *Queue) Stats(ctx context.Context) (*Stats, error) {
+
+	//1.获取所有已注册的消费者
+	consumers, err := q.c.set.SMembers(ctx, q.key("consumers"))
+	if err != nil {
+		return nil, err
+	}
+
+	//2.批量查询各列表长度
+	pipe := q.c.rdb.Pipeline()
+	pending := make([]*redis.IntCmd, q.opts.Priorities)
+	for p := range pending {
+		pending[p] = pipe.LLen(ctx, q.pendingKey(p))
+	}
+	processing := make([]*redis.IntCmd, len(consumers))
+	for i, id := range consumers {
+		processing[i] = pipe.LLen(ctx, q.processingKey(id))
+	}
+	dead := pipe.LLen(ctx, q.key("dead"))
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	//3.汇总
+	stats := &Stats{Pending: make([]int64, q.opts.Priorities), Dead: dead.Val(), Consumers: int64(len(consumers))}
+	for p, cmd := range pending {
+		stats.Pending[p] = cmd.Val()
+	}
+	for _, cmd := range processing {
+		stats.Processing += cmd.Val()
+	}
+	return stats, nil
+}
+
+// DeadLetters 返回死信消息
+func (q *Queue) DeadLetters(ctx context.Context, start, stop int64) ([]*Message, error) {
+	ids, err := q.c.list.LRange(ctx, q.key("dead"), start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return q.load(ctx, ids)
+}
+
+// RequeueDead 将所有死信消息重置失败次数后重新入队，返回重新入队的消息数
+// 每条消息在一个脚本中从死信列表移动到待消费列表，中途出错或取消不会丢失消息
+func (q *Queue) RequeueDead(ctx context.Context) (int64, error) {
+	var n int64
+	for {
+		//1.读取死信列表末尾的消息ID
+		id, err := q.c.list.LIndex(ctx, q.key("dead"), -1)
+		if errors.Is(err, redis.Nil) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+
+		//2.原子地移动到原优先级的待消费列表，末尾已被其他调用方移走时重新读取
+		keys := []string{q.key("dead"), q.key("attempts"), q.pendingKey(priorityOf(id)), q.key("signal")}
+		moved, err := requeueDeadScript.Run(ctx, q.c.rdb, keys, id).Int64()
+		if err != nil {
+			return n, err
+		}
+		n += moved
+	}
+}
+
+// nack 将源列表中的消息重新入队或移入死信列表，返回消息是否存在于源列表中
+func (q *Queue) nack(ctx context.Context, source, id string) (bool, error) {
+	keys := []string{source, q.key("attempts"), q.pendingKey(priorityOf(id)), q.key("dead"), q.key("signal")}
+	n, err := nackScript.Run(ctx, q.c.rdb, keys, q.opts.MaxRetries, id).Int64()
+	return n > 0, err
+}
+
+// load 批量读取消息内容与失败次数
+func (q *Queue) load(ctx context.Context, ids []string) ([]*Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	bodies, err := q.c.hash.HMGet(ctx, q.key("bodies"), ids...)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := q.c.hash.HMGet(ctx, q.key("attempts"), ids...)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]*Message, len(ids))
+	for i, id := range ids {
+		m := &Message{ID: id, Priority: priorityOf(id)}
+		if s, ok := bodies[i].(string); ok {
+			m.Body = s
+		}
+		if s, ok := attempts[i].(string); ok {
+			m.Attempts, _ = strconv.ParseInt(s, 10, 64)
+		}
+		messages[i] = m
+	}
+	return messages, nil
+}
+
+// pendingKeys 返回按优先级从高到低排列的待消费列表key
+func (q *Queue) pendingKeys() []string {
+	keys := make([]string, q.opts.Priorities)
+	for p := range keys {
+		keys[p] = q.pendingKey(p)
+	}
+	return keys
+}
+
+// pendingKey 返回优先级p的待消费列表key
+func (q *Queue) pendingKey(p int) string {
+	return q.key("pending:" + strconv.Itoa(p))
+}
+
+// processingKey 返回消费者的处理中列表key
+func (q *Queue) processingKey(consumer string) string {
+	return q.key("processing:" + consumer)
+}
+
+// heartbeatKey 返回消费者的心跳key
+func (q *Queue) heartbeatKey(consumer string) string {
+	return q.key("heartbeat:" + consumer)
+}
+
+// key 返回队列下的子key
+func (q *Queue) key(suffix string) string {
+	return q.name + ":" + suffix
+}
+
+// Consumer 工作队列消费者
+type Consumer struct {
+	q  *Queue
+	id string
+}
+
+// Heartbeat 刷新心跳并注册消费者，处理耗时较长的消息时需在可见性超时内定期调用
+func (c *Consumer) Heartbeat(ctx context.Context) error {
+	q := c.q
+	_, err := q.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, q.heartbeatKey(c.id), time.Now().UnixMilli(), q.opts.Visibility)
+		pipe.SAdd(ctx, q.key("consumers"), c.id)
+		return nil
+	})
+	return err
+}
+
+// Reserve 按优先级取出一条消息并移动到自己的处理中列表，没有消息时最多阻塞timeout
+// 参数:
+//   - ctx: 上下文
+//   - timeout: 最长阻塞时间
+//
+// 返回:
+//   - 消息，超时无消息时返回nil
+//   - 错误信息
+func (c *Consumer) Reserve(ctx context.Context, timeout time.Duration) (*Message, error) {
+	q := c.q
+	deadline := time.Now().Add(timeout)
+
+	//1.刷新心跳，保证取出的消息不会被立即回收
+	if err := c.Heartbeat(ctx); err != nil {
+		return nil, err
+	}
+
+	keys := append([]string{q.processingKey(c.id)}, q.pendingKeys()...)
+	for {
+		//2.按优先级原子地移动一条消息到处理中列表
+		id, err := reserveScript.Run(ctx, q.c.rdb, keys).Text()
+		if err == nil {
+			messages, err := q.load(ctx, []string{id})
+			if err != nil {
+				return nil, err
+			}
+			return messages[0], nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+
+		//3.没有消息时阻塞等待唤醒信号
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, nil
+		}
+		_, err = q.c.list.BLPop(ctx, wait, q.key("signal"))
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+	}
+}
+
+// Ack 确认消息已处理完成
+func (c *Consumer) Ack(ctx context.Context, m *Message) error {
+	q := c.q
+	keys := []string{q.processingKey(c.id), q.key("bodies"), q.key("attempts")}
+	n, err := ackScript.Run(ctx, q.c.rdb, keys, m.ID).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrMessageNotHeld
+	}
+	return nil
+}
+
+// Nack 消息处理失败，重新入队；失败次数超过最大重试次数时移入死信列表
+func (c *Consumer) Nack(ctx context.Context, m *Message) error {
+	ok, err := c.q.nack(ctx, c.q.processingKey(c.id), m.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMessageNotHeld
+	}
+	return nil
+}
+
+// Close 注销消费者，将处理中的消息放回待消费列表（不计失败次数）
+func (c *Consumer) Close(ctx context.Context) error {
+	q := c.q
+	processing := q.processingKey(c.id)
+	ids, err := q.c.list.LRange(ctx, processing, 0, -1)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		//1.先放回待消费列表的消费端，再从处理中列表移除，崩溃时最多重复投递
+		if _, err = q.c.list.RPush(ctx, q.pendingKey(priorityOf(id)), id); err != nil {
+			return err
+		}
+		if _, err = q.c.list.LRem(ctx, processing, 1, id); err != nil {
+			return err
+		}
+	}
+
+	//2.删除心跳并注销
+	if _, err = q.c.string.Del(ctx, q.heartbeatKey(c.id)); err != nil {
+		return err
+	}
+	_, err = q.c.set.SRem(ctx, q.key("consumers"), c.id)
+	return err
+}
+
+// priorityOf 从消息ID中解析优先级
+func priorityOf(id string) int {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return 0
+	}
+	p, _ := strconv.Atoi(id[:i])
+	return p
+}
+
+// newID 生成随机的消息ID
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
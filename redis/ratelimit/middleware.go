@@ -0,0 +1,70 @@
+// Package ratelimit 提供基于Redis的分布式限流封装，所有算法均以Lua脚本原子执行
+// @Author:冯铁城 [17615007230@163.com] 2025-08-15 10:00:00
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyFunc 根据请求生成限流key
+type KeyFunc func(r *http.Request) string
+
+// Middleware 返回HTTP限流中间件
+// 每个响应都会设置 RateLimit-Limit、RateLimit-Remaining、RateLimit-Reset 头，
+// 请求被拒绝时额外设置 Retry-After 并返回 429 Too Many Requests；
+// Redis不可用时放行请求，避免限流组件故障导致整体服务不可用
+// 参数:
+//   - alg: 限流算法
+//   - limit: 限流规则
+//   - keyFunc: 生成限流key的函数，为nil时按客户端IP限流
+//
+// 返回:
+//   - HTTP中间件
+func (c *Client) Middleware(alg Algorithm, limit Limit, keyFunc KeyFunc) func(http.Handler) http.Handler {
+	if keyFunc == nil {
+		keyFunc = ClientIP
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			//1.执行限流判断，Redis异常时放行
+			res, err := c.Allow(r.Context(), keyFunc(r), alg, limit)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			//2.设置限流响应头
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+			h.Set("RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+			h.Set("RateLimit-Reset", ceilSeconds(res.ResetAfter))
+
+			//3.被拒绝时返回429
+			if !res.Allowed {
+				h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP 以客户端IP作为限流key
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ratelimit:" + r.RemoteAddr
+	}
+	return "ratelimit:" + host
+}
+
+// ceilSeconds 将时长向上取整为秒数字符串，响应头中的时间均以秒为单位
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,299 @@
+// Package ratelimit 提供基于Redis的分布式限流封装，所有算法均以Lua脚本原子执行
+// @Author:冯铁城 [17615007230@163.com] 2025-08-15 10:00:00
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Algorithm 限流算法
+type Algorithm int
+
+const (
+	FixedWindow   Algorithm = iota // 固定窗口计数
+	SlidingLog                     // 滑动日志，使用有序集合记录每次请求的时间
+	SlidingWindow                  // 滑动窗口计数，按当前窗口与上一窗口的计数加权估算
+	TokenBucket                    // 令牌桶
+	GCRA                           // 通用信元速率算法（Generic Cell Rate Algorithm）
+)
+
+var (
+	// ErrInvalidLimit 限流规则不合法
+	ErrInvalidLimit = errors.New("ratelimit: 速率必须大于0，周期不能小于1毫秒")
+
+	// ErrInvalidCount 请求次数不合法
+	ErrInvalidCount = errors.New("ratelimit: 请求次数必须大于0")
+
+	// ErrUnknownAlgorithm 不支持的限流算法
+	ErrUnknownAlgorithm = errors.New("ratelimit: 不支持的限流算法")
+)
+
+// Limit 限流规则：每Period内最多Rate次请求
+type Limit struct {
+	Rate   int64         // 每个周期内允许的请求数
+	Period time.Duration // 周期
+	Burst  int64         // 突发容量，仅令牌桶与GCRA使用，为0时等于Rate
+}
+
+// PerSecond 每秒最多n次请求
+func PerSecond(n int64) Limit {
+	return Limit{Rate: n, Period: time.Second}
+}
+
+// PerMinute 每分钟最多n次请求
+func PerMinute(n int64) Limit {
+	return Limit{Rate: n, Period: time.Minute}
+}
+
+// PerHour 每小时最多n次请求
+func PerHour(n int64) Limit {
+	return Limit{Rate: n, Period: time.Hour}
+}
+
+// burst 返回突发容量
+func (l Limit) burst() int64 {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Result 限流结果
+type Result struct {
+	Limit      int64         // 配额上限
+	Allowed    bool          // 是否允许本次请求
+	Remaining  int64         // 剩余可用配额
+	RetryAfter time.Duration // 被拒绝时距下次允许请求的时间，允许时为0
+	ResetAfter time.Duration // 距配额完全恢复的时间
+}
+
+// 所有脚本的返回值均为 {是否允许, 剩余配额, 重试等待(毫秒), 完全恢复等待(毫秒)}
+// 时间统一使用Redis服务器时间，避免多个客户端之间的时钟偏差
+
+// fixedWindowScript 固定窗口：首次请求时开启窗口并设置过期时间，窗口内计数超过上限则拒绝
+// KEYS[1]=计数key ARGV[1]=上限 ARGV[2]=周期(毫秒) ARGV[3]=本次请求数
+var fixedWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local cur = tonumber(redis.call("GET", KEYS[1]) or "0")
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	ttl = period
+end
+if cur + n > limit then
+	return {0, limit - cur, ttl, ttl}
+end
+cur = redis.call("INCRBY", KEYS[1], n)
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], period)
+end
+return {1, limit - cur, 0, ttl}
+`)
+
+// slidingLogScript 滑动日志：有序集合中以请求时间为分数记录窗口内的每次请求
+// KEYS[1]=日志key ARGV[1]=上限 ARGV[2]=周期(毫秒) ARGV[3]=本次请求数 ARGV[4]=请求唯一标识
+var slidingLogScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - period)
+local cnt = redis.call("ZCARD", KEYS[1])
+local reset = 0
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+if #oldest > 0 then
+	reset = tonumber(oldest[2]) + period - now
+end
+if cnt + n > limit then
+	local retry = period
+	local idx = cnt + n - limit - 1
+	if n <= limit and idx < cnt then
+		local e = redis.call("ZRANGE", KEYS[1], idx, idx, "WITHSCORES")
+		retry = tonumber(e[2]) + period - now
+	end
+	return {0, limit - cnt, retry, reset}
+end
+for i = 1, n do
+	redis.call("ZADD", KEYS[1], now, ARGV[4] .. ":" .. i)
+end
+redis.call("PEXPIRE", KEYS[1], period)
+if cnt == 0 then
+	reset = period
+end
+return {1, limit - cnt - n, 0, reset}
+`)
+
+// slidingWindowScript 滑动窗口计数：哈希中按窗口编号保存计数，
+// 估算值 = 上一窗口计数 * 上一窗口在滑动窗口内的占比 + 当前窗口计数，
+// 被拒绝时的重试等待按估算值随时间线性下降推算，当前窗口已无余量时推算到下一窗口
+// KEYS[1]=计数哈希 ARGV[1]=上限 ARGV[2]=周期(毫秒) ARGV[3]=本次请求数
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local w = math.floor(now / period)
+local elapsed = now - w * period
+local cur = tonumber(redis.call("HGET", KEYS[1], tostring(w)) or "0")
+local prev = tonumber(redis.call("HGET", KEYS[1], tostring(w - 1)) or "0")
+local est = prev * (period - elapsed) / period + cur
+if est + n > limit then
+	local retry = period - elapsed
+	local room = limit - cur - n
+	if prev > 0 and room >= 0 then
+		retry = math.ceil(period - room * period / prev - elapsed)
+	elseif cur > 0 and limit - n >= 0 then
+		retry = math.ceil(period - elapsed + period - (limit - n) * period / cur)
+	end
+	return {0, math.max(0, math.floor(limit - est)), retry, period * 2 - elapsed}
+end
+redis.call("HINCRBY", KEYS[1], tostring(w), n)
+for _, f in ipairs(redis.call("HKEYS", KEYS[1])) do
+	if tonumber(f) < w - 1 then
+		redis.call("HDEL", KEYS[1], f)
+	end
+end
+redis.call("PEXPIRE", KEYS[1], period * 2)
+return {1, math.max(0, math.floor(limit - est - n)), 0, period * 2 - elapsed}
+`)
+
+// tokenBucketScript 令牌桶：哈希中保存剩余令牌数与上次补充时间，按流逝时间补充令牌
+// KEYS[1]=令牌桶哈希 ARGV[1]=容量 ARGV[2]=每毫秒补充的令牌数 ARGV[3]=本次请求数
+var tokenBucketScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+else
+	retry = math.ceil((n - tokens) / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", string.format("%.6f", tokens), "ts", string.format("%.3f", now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate))
+return {allowed, math.floor(tokens), retry, math.ceil((burst - tokens) / rate)}
+`)
+
+// gcraScript GCRA：保存理论到达时间（TAT），请求到达时间不早于 TAT - 容忍度 时允许
+// KEYS[1]=TAT key ARGV[1]=容量 ARGV[2]=发射间隔(毫秒) ARGV[3]=本次请求数
+var gcraScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local tolerance = interval * burst
+local tat = tonumber(redis.call("GET", KEYS[1]) or "0")
+tat = math.max(tat, now)
+local newTat = tat + interval * n
+local diff = now - (newTat - tolerance)
+if diff < 0 then
+	local remaining = math.max(0, math.floor((now - (tat - tolerance)) / interval))
+	return {0, remaining, math.ceil(-diff), math.ceil(tat - now)}
+end
+local ttl = math.ceil(newTat - now)
+redis.call("SET", KEYS[1], string.format("%.3f", newTat), "PX", ttl)
+return {1, math.floor(diff / interval), 0, ttl}
+`)
+
+// Client 分布式限流客户端
+type Client struct {
+	rdb *redis.Client
+}
+
+// New 创建分布式限流客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb}
+}
+
+// Allow 判断key的一次请求是否被允许
+// 参数:
+//   - ctx: 上下文
+//   - key: 限流key，通常由业务前缀与用户标识、IP等组成
+//   - alg: 限流算法
+//   - limit: 限流规则
+//
+// 返回:
+//   - 限流结果
+//   - 错误信息
+func (c *Client) Allow(ctx context.Context, key string, alg Algorithm, limit Limit) (*Result, error) {
+	return c.AllowN(ctx, key, alg, limit, 1)
+}
+
+// AllowN 判断key的n次请求是否被允许，被拒绝时不消耗配额
+func (c *Client) AllowN(ctx context.Context, key string, alg Algorithm, limit Limit, n int64) (*Result, error) {
+
+	//1.校验限流规则与请求次数
+	if limit.Rate <= 0 || limit.Period < time.Millisecond {
+		return nil, ErrInvalidLimit
+	}
+	if n <= 0 {
+		return nil, ErrInvalidCount
+	}
+
+	//2.按算法执行对应脚本
+	period := limit.Period.Milliseconds()
+	var cmd *redis.Cmd
+	switch alg {
+	case FixedWindow:
+		cmd = fixedWindowScript.Run(ctx, c.rdb, []string{key}, limit.Rate, period, n)
+	case SlidingLog:
+		cmd = slidingLogScript.Run(ctx, c.rdb, []string{key}, limit.Rate, period, n, newRequestID())
+	case SlidingWindow:
+		cmd = slidingWindowScript.Run(ctx, c.rdb, []string{key}, limit.Rate, period, n)
+	case TokenBucket:
+		rate := float64(limit.Rate) / float64(period)
+		cmd = tokenBucketScript.Run(ctx, c.rdb, []string{key}, limit.burst(), rate, n)
+	case GCRA:
+		interval := float64(period) / float64(limit.Rate)
+		cmd = gcraScript.Run(ctx, c.rdb, []string{key}, limit.burst(), interval, n)
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+
+	//3.解析脚本返回值
+	res, err := cmd.Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	total := limit.Rate
+	if alg == TokenBucket || alg == GCRA {
+		total = limit.burst()
+	}
+	return &Result{
+		Limit:      total,
+		Allowed:    res[0] == 1,
+		Remaining:  max(res[1], 0),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
+
+// Reset 清除key的限流状态
+func (c *Client) Reset(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, key).Err()
+}
+
+// newRequestID 生成随机的请求标识，用于滑动日志中区分同一毫秒内的多次请求
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
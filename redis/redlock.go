@@ -0,0 +1,55 @@
+// Package redis 提供了Redis客户端的统一封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-12 10:00:00
+package redis
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+
+	lockpkg "go-redis-demo/redis/lock"
+)
+
+var (
+	// ErrNoInstances 创建Redlock时未传入任何实例配置
+	ErrNoInstances = errors.New("redis: Redlock至少需要一个实例配置")
+
+	// ErrNotInitialized 全局客户端尚未通过InitClient初始化
+	ErrNotInitialized = errors.New("redis: 全局客户端未初始化，请先调用InitClient")
+)
+
+// NewRedlock 根据多个相互独立的Redis实例配置创建Redlock客户端
+// 使用完毕后需调用Close关闭所有实例的连接，未传入配置时返回ErrNoInstances
+func NewRedlock(configs ...*Config) (*lockpkg.Redlock, error) {
+	if len(configs) == 0 {
+		return nil, ErrNoInstances
+	}
+
+	//1.逐个创建底层客户端，任一实例连接失败则关闭已创建的连接
+	rdbs := make([]*redis.Client, 0, len(configs))
+	for _, config := range configs {
+		rdb, err := newRDB(config)
+		if err != nil {
+			for _, created := range rdbs {
+				_ = created.Close()
+			}
+			return nil, err
+		}
+		rdbs = append(rdbs, rdb)
+	}
+
+	//2.返回
+	return lockpkg.NewRedlock(rdbs...), nil
+}
+
+// NewLockFactory 根据配置创建锁工厂，调用方可通过配置在单实例锁与Redlock之间切换
+// 未传入配置时使用全局客户端上的单实例锁，全局客户端未初始化时返回ErrNotInitialized；传入配置时按这些实例创建Redlock
+func NewLockFactory(configs ...*Config) (lockpkg.Factory, error) {
+	if len(configs) == 0 {
+		if Client == nil {
+			return nil, ErrNotInitialized
+		}
+		return Client.Lock, nil
+	}
+	return NewRedlock(configs...)
+}
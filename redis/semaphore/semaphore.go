@@ -0,0 +1,243 @@
+// Package semaphore 提供基于Redis有序集合的分布式信号量封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-14 10:00:00
+package semaphore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+var (
+	// ErrInvalidLimit 许可数量必须大于0
+	ErrInvalidLimit = errors.New("semaphore: 许可数量必须大于0")
+
+	// ErrPermitNotHeld 许可已过期或已释放
+	ErrPermitNotHeld = errors.New("semaphore: 当前并未持有该许可")
+)
+
+// acquireScript 获取许可
+// 持有者以过期时间为分数保存在有序集合中，获取前先清理已过期的持有者（崩溃的持有者会自动释放许可），
+// 剩余持有者数量小于许可数量时加入自己；不按排名判断，因为不同持有者的TTL可能不同
+// KEYS[1]=信号量key ARGV[1]=持有者 ARGV[2]=许可数量 ARGV[3]=TTL(毫秒)
+// 返回 {1, 0} 表示获取成功，{0, n} 表示获取失败且最早的许可将在n毫秒后过期
+var acquireScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("ZADD", KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+	if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[3]) then
+		redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	end
+	return {1, 0}
+end
+local first = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+if #first == 0 then
+	return {0, 0}
+end
+return {0, tonumber(first[2]) - now}
+`)
+
+// releaseScript 释放许可，并通知等待者
+// KEYS[1]=信号量key ARGV[1]=持有者 ARGV[2]=通知频道
+var releaseScript = redis.NewScript(`
+local n = redis.call("ZREM", KEYS[1], ARGV[1])
+if n == 1 then
+	redis.call("PUBLISH", ARGV[2], ARGV[1])
+end
+return n
+`)
+
+// refreshScript 持有者续期许可
+// KEYS[1]=信号量key ARGV[1]=持有者 ARGV[2]=TTL(毫秒)
+var refreshScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if (not score) or tonumber(score) <= now then
+	return 0
+end
+redis.call("ZADD", KEYS[1], "XX", now + tonumber(ARGV[2]), ARGV[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// Client 分布式信号量客户端
+type Client struct {
+	rdb  *redis.Client
+	zset *zsetpkg.Client
+}
+
+// New 创建分布式信号量客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, zset: zsetpkg.New(rdb)}
+}
+
+// Permit 已获取的许可
+type Permit struct {
+	c      *Client
+	name   string
+	holder string
+	ttl    time.Duration
+}
+
+// TryAcquire 尝试获取一个许可，不阻塞
+// 参数:
+//   - ctx: 上下文
+//   - name: 信号量名称
+//   - limit: 许可总数
+//   - ttl: 许可有效期，持有者崩溃后许可最迟在ttl后被自动回收
+//
+// 返回:
+//   - 获取成功时返回许可，否则为nil
+//   - 错误信息
+func (c *Client) TryAcquire(ctx context.Context, name string, limit int64, ttl time.Duration) (*Permit, error) {
+	p, _, err := c.tryAcquire(ctx, name, limit, ttl)
+	return p, err
+}
+
+// Acquire 获取一个许可，许可不足时阻塞等待
+// 等待期间订阅释放通知，有许可释放时立即重试；持有者崩溃不会发出通知，
+// 因此最迟在最早的许可过期时也会重试
+// 参数:
+//   - ctx: 上下文，取消时停止等待并返回ctx.Err()
+//   - name: 信号量名称
+//   - limit: 许可总数
+//   - ttl: 许可有效期，持有者崩溃后许可最迟在ttl后被自动回收
+//
+// 返回:
+//   - 许可
+//   - 错误信息
+func (c *Client) Acquire(ctx context.Context, name string, limit int64, ttl time.Duration) (*Permit, error) {
+
+	//1.先订阅释放通知，避免在尝试与订阅之间错过通知
+	pubsub := c.rdb.Subscribe(ctx, channel(name))
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return nil, err
+	}
+	notify := pubsub.Channel()
+
+	for {
+		//2.尝试获取许可
+		p, wait, err := c.tryAcquire(ctx, name, limit, ttl)
+		if err != nil || p != nil {
+			return p, err
+		}
+
+		//3.等待释放通知、最早的许可过期或上下文结束
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-notify:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Count 返回当前有效的许可数量
+func (c *Client) Count(ctx context.Context, name string) (int64, error) {
+
+	//1.清理已过期的持有者
+	now, err := c.rdb.Time(ctx).Result()
+	if err != nil {
+		return 0, err
+	}
+	if _, err = c.zset.ZRemRangeByScore(ctx, name, zsetpkg.Between(zsetpkg.NegInf, zsetpkg.Incl(float64(now.UnixMilli())))); err != nil {
+		return 0, err
+	}
+
+	//2.返回剩余持有者数量
+	return c.zset.ZCard(ctx, name)
+}
+
+// Release 释放许可，并通知等待者
+func (p *Permit) Release(ctx context.Context) error {
+	n, err := releaseScript.Run(ctx, p.c.rdb, []string{p.name}, p.holder, channel(p.name)).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPermitNotHeld
+	}
+	return nil
+}
+
+// Refresh 将许可的有效期重置为ttl
+func (p *Permit) Refresh(ctx context.Context) error {
+	n, err := refreshScript.Run(ctx, p.c.rdb, []string{p.name}, p.holder, p.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPermitNotHeld
+	}
+	return nil
+}
+
+// Rank 返回许可在所有持有者中按过期时间的排名（0表示最早过期）
+func (p *Permit) Rank(ctx context.Context) (int64, error) {
+	rank, err := p.c.zset.ZRank(ctx, p.name, p.holder)
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrPermitNotHeld
+	}
+	return rank, err
+}
+
+// Holder 返回许可持有者的唯一标识
+func (p *Permit) Holder() string {
+	return p.holder
+}
+
+// tryAcquire 执行获取脚本，失败时返回距最早的许可过期的等待时间
+func (c *Client) tryAcquire(ctx context.Context, name string, limit int64, ttl time.Duration) (*Permit, time.Duration, error) {
+
+	//1.校验许可数量
+	if limit <= 0 {
+		return nil, 0, ErrInvalidLimit
+	}
+
+	//2.执行获取脚本
+	holder := newHolder()
+	res, err := acquireScript.Run(ctx, c.rdb, []string{name}, holder, limit, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	//3.获取失败，返回等待时间
+	if res[0] != 1 {
+		wait := time.Duration(res[1]) * time.Millisecond
+		if wait <= 0 {
+			wait = time.Millisecond
+		}
+		return nil, wait, nil
+	}
+
+	//4.获取成功
+	return &Permit{c: c, name: name, holder: holder, ttl: ttl}, 0, nil
+}
+
+// channel 返回信号量的释放通知频道
+func channel(name string) string {
+	return name + ":released"
+}
+
+// newHolder 生成随机的持有者标识
+func newHolder() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
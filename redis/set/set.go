@@ -22,16 +22,14 @@ func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) (
 	return c.rdb.SAdd(ctx, key, members...).Result()
 }
 
-// SPop 随机移除并返回集合key中若干随机元素
-func (c *Client) SPop(ctx context.Context, key string, count ...int64) ([]string, error) {
-	if len(count) > 0 {
-		return c.rdb.SPopN(ctx, key, count[0]).Result()
-	}
-	result, err := c.rdb.SPop(ctx, key).Result()
-	if err != nil {
-		return nil, err
-	}
-	return []string{result}, nil
+// SPop 随机移除并返回集合key中的一个元素，集合为空或不存在时返回redis.Nil
+func (c *Client) SPop(ctx context.Context, key string) (string, error) {
+	return c.rdb.SPop(ctx, key).Result()
+}
+
+// SPopN 随机移除并返回集合key中最多count个元素，集合为空或不存在时返回空切片
+func (c *Client) SPopN(ctx context.Context, key string, count int64) ([]string, error) {
+	return c.rdb.SPopN(ctx, key, count).Result()
 }
 
 // SRem 在集合key中移除指定元素，并返回成功移除元素个数
@@ -49,21 +47,25 @@ func (c *Client) SIsMember(ctx context.Context, key string, member interface{})
 	return c.rdb.SIsMember(ctx, key, member).Result()
 }
 
+// SMIsMember 批量判断元素是否存在于集合key中（Redis 6.2+），结果与members一一对应
+func (c *Client) SMIsMember(ctx context.Context, key string, members ...interface{}) ([]bool, error) {
+	return c.rdb.SMIsMember(ctx, key, members...).Result()
+}
+
 // SMembers 返回集合key的所有元素
 func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
 	return c.rdb.SMembers(ctx, key).Result()
 }
 
-// SRandMember 随机返回集合key中的一个元素，或随机返回集合key中的count的元素
-func (c *Client) SRandMember(ctx context.Context, key string, count ...int64) ([]string, error) {
-	if len(count) > 0 {
-		return c.rdb.SRandMemberN(ctx, key, count[0]).Result()
-	}
-	result, err := c.rdb.SRandMember(ctx, key).Result()
-	if err != nil {
-		return nil, err
-	}
-	return []string{result}, nil
+// SRandMember 随机返回集合key中的一个元素，集合为空或不存在时返回redis.Nil
+func (c *Client) SRandMember(ctx context.Context, key string) (string, error) {
+	return c.rdb.SRandMember(ctx, key).Result()
+}
+
+// SRandMemberN 随机返回集合key中的多个元素，集合为空或不存在时返回空切片
+// count为正数时返回最多count个互不相同的元素，为负数时返回恰好-count个元素且可能重复
+func (c *Client) SRandMemberN(ctx context.Context, key string, count int64) ([]string, error) {
+	return c.rdb.SRandMemberN(ctx, key, count).Result()
 }
 
 // SMove 将指定元素member从集合source中移动到集合destination中
@@ -81,6 +83,11 @@ func (c *Client) SInterStore(ctx context.Context, destination string, keys ...st
 	return c.rdb.SInterStore(ctx, destination, keys...).Result()
 }
 
+// SInterCard 返回所有指定集合交集的元素数（Redis 7.0+），limit大于0时数到limit即停止
+func (c *Client) SInterCard(ctx context.Context, limit int64, keys ...string) (int64, error) {
+	return c.rdb.SInterCard(ctx, limit, keys...).Result()
+}
+
 // SUnion 返回所有指定集合中元素的并集
 func (c *Client) SUnion(ctx context.Context, keys ...string) ([]string, error) {
 	return c.rdb.SUnion(ctx, keys...).Result()
@@ -100,3 +107,24 @@ func (c *Client) SDiff(ctx context.Context, keys ...string) ([]string, error) {
 func (c *Client) SDiffStore(ctx context.Context, destination string, keys ...string) (int64, error) {
 	return c.rdb.SDiffStore(ctx, destination, keys...).Result()
 }
+
+// SScan 增量遍历集合key中的元素
+// 参数:
+//   - ctx: 上下文
+//   - key: 集合
+//   - cursor: 游标，首次传0，之后传上一次返回的游标
+//   - match: 元素匹配模式，为空时不过滤
+//   - count: 每次遍历的元素数提示
+//
+// 返回:
+//   - 本次遍历到的元素，同一元素可能被返回多次
+//   - 下一次遍历的游标，为0时表示遍历结束
+//   - 错误信息
+func (c *Client) SScan(ctx context.Context, key string, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	return c.rdb.SScan(ctx, key, cursor, match, count).Result()
+}
+
+// SScanIterator 返回遍历集合key中所有元素的迭代器
+func (c *Client) SScanIterator(ctx context.Context, key string, match string, count int64) *redis.ScanIterator {
+	return c.rdb.SScan(ctx, key, 0, match, count).Iterator()
+}
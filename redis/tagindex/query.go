@@ -0,0 +1,216 @@
+// Package tagindex 提供基于Redis集合的标签倒排索引与布尔查询封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-24 10:00:00
+package tagindex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidQuery 查询表达式不合法
+var ErrInvalidQuery = errors.New("tagindex: 查询表达式不合法")
+
+// 查询表达式语法（关键字不区分大小写，相邻的两个条件之间省略AND时视为AND）：
+//
+//	expr    = and { "OR" and }
+//	and     = unary { ["AND"] unary }
+//	unary   = "NOT" unary | primary
+//	primary = "(" expr ")" | 标签
+//
+// 例如 (red OR blue) AND size:m AND NOT sold
+
+// op 表达式节点类型
+type op int
+
+const (
+	opTag op = iota // 标签
+	opAnd           // 交集
+	opOr            // 并集
+	opNot           // 取反
+)
+
+// Expr 解析后的查询表达式
+type Expr struct {
+	op       op
+	tag      string
+	children []*Expr
+}
+
+// Parse 解析查询表达式
+func Parse(query string) (*Expr, error) {
+	p := &parser{tokens: tokenize(query)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("%w: 表达式为空", ErrInvalidQuery)
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: 多余的 %q", ErrInvalidQuery, p.tokens[p.pos])
+	}
+	return e, nil
+}
+
+// Tags 返回表达式中出现的所有标签
+func (e *Expr) Tags() []string {
+	if e.op == opTag {
+		return []string{e.tag}
+	}
+	var tags []string
+	for _, c := range e.children {
+		tags = append(tags, c.Tags()...)
+	}
+	return tags
+}
+
+// String 返回表达式的规范形式，主要用于调试
+func (e *Expr) String() string {
+	switch e.op {
+	case opTag:
+		return e.tag
+	case opNot:
+		return "NOT " + e.children[0].String()
+	}
+	sep := " AND "
+	if e.op == opOr {
+		sep = " OR "
+	}
+	parts := make([]string, len(e.children))
+	for i, c := range e.children {
+		parts[i] = c.String()
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+// parser 递归下降解析器
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// peek 返回当前词，已到末尾时返回空字符串
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// keyword 判断当前词是否为指定关键字
+func (p *parser) keyword(k string) bool {
+	return strings.EqualFold(p.peek(), k)
+}
+
+// parseOr 解析 and { "OR" and }
+func (p *parser) parseOr() (*Expr, error) {
+	e, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Expr{e}
+	for p.keyword("OR") {
+		p.pos++
+		if e, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		children = append(children, e)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &Expr{op: opOr, children: children}, nil
+}
+
+// parseAnd 解析 unary { ["AND"] unary }
+func (p *parser) parseAnd() (*Expr, error) {
+	e, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Expr{e}
+	for {
+		if p.keyword("AND") {
+			p.pos++
+		} else if t := p.peek(); t == "" || t == ")" || p.keyword("OR") {
+			break
+		}
+		if e, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		children = append(children, e)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &Expr{op: opAnd, children: children}, nil
+}
+
+// parseUnary 解析 "NOT" unary | primary
+func (p *parser) parseUnary() (*Expr, error) {
+	if p.keyword("NOT") {
+		p.pos++
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if e.op == opNot {
+			return e.children[0], nil
+		}
+		return &Expr{op: opNot, children: []*Expr{e}}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary 解析 "(" expr ")" | 标签
+func (p *parser) parsePrimary() (*Expr, error) {
+	t := p.peek()
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("%w: 表达式不完整", ErrInvalidQuery)
+	case t == "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("%w: 缺少右括号", ErrInvalidQuery)
+		}
+		p.pos++
+		return e, nil
+	case t == ")" || p.keyword("AND") || p.keyword("OR"):
+		return nil, fmt.Errorf("%w: 意外的 %q", ErrInvalidQuery, t)
+	default:
+		p.pos++
+		return &Expr{op: opTag, tag: t}, nil
+	}
+}
+
+// tokenize 按空白与括号切分表达式
+func tokenize(query string) []string {
+	var (
+		tokens []string
+		cur    strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range query {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
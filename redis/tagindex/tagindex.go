@@ -0,0 +1,318 @@
+// Package tagindex 提供基于Redis集合的标签倒排索引与布尔查询封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-24 10:00:00
+package tagindex
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	setpkg "go-redis-demo/redis/set"
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// 每个索引由以下key组成：
+//   - {index}:tag:{tag}  集合，带有该标签的对象ID
+//   - {index}:doc:{id}   集合，对象的所有标签，用于删除与重建索引
+//   - {index}:all        集合，所有已索引的对象ID，NOT查询以此为全集
+//   - {index}:tmp:{rand} 查询过程中的临时结果，查询结束后删除，并设置过期时间防止进程崩溃时残留
+// 脚本中标签集合的key由前缀在脚本内拼接，因此只适用于单实例或主从部署
+
+// reindexScript 删除对象原有的标签，再写入新的标签
+// KEYS[1]=对象标签集合 KEYS[2]=全集 ARGV[1]=标签集合key前缀 ARGV[2]=对象ID ARGV[3..]=新标签
+var reindexScript = redis.NewScript(`
+for _, tag in ipairs(redis.call("SMEMBERS", KEYS[1])) do
+	redis.call("SREM", ARGV[1] .. tag, ARGV[2])
+end
+redis.call("DEL", KEYS[1])
+if #ARGV < 3 then
+	return redis.call("SREM", KEYS[2], ARGV[2])
+end
+for i = 3, #ARGV do
+	redis.call("SADD", ARGV[1] .. ARGV[i], ARGV[2])
+	redis.call("SADD", KEYS[1], ARGV[i])
+end
+return redis.call("SADD", KEYS[2], ARGV[2])
+`)
+
+// tmpTTL 临时结果的过期时间
+const tmpTTL = time.Minute
+
+// SearchOptions 分页查询的选项
+type SearchOptions struct {
+	SortBy string // 排序依据的有序集合，成员为对象ID；为空时按对象ID字典序排序，不在其中的对象不会出现在结果中
+	Desc   bool   // 是否倒序
+	Offset int64  // 跳过的结果数
+	Count  int64  // 返回的最大结果数，为0时不限制
+}
+
+// Result 分页查询结果
+type Result struct {
+	IDs   []string // 当前页的对象ID
+	Total int64    // 满足条件的对象总数
+}
+
+// Client 标签索引客户端
+type Client struct {
+	rdb  *redis.Client
+	set  *setpkg.Client
+	zset *zsetpkg.Client
+}
+
+// New 创建标签索引客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, set: setpkg.New(rdb), zset: zsetpkg.New(rdb)}
+}
+
+// Index 获取名为name的索引
+func (c *Client) Index(name string) *Index {
+	return &Index{c: c, name: name}
+}
+
+// Index 标签倒排索引
+type Index struct {
+	c    *Client
+	name string
+}
+
+// Add 为对象追加标签
+func (ix *Index) Add(ctx context.Context, id string, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := ix.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		members := make([]interface{}, len(tags))
+		for i, tag := range tags {
+			pipe.SAdd(ctx, ix.tagKey(tag), id)
+			members[i] = tag
+		}
+		pipe.SAdd(ctx, ix.docKey(id), members...)
+		pipe.SAdd(ctx, ix.key("all"), id)
+		return nil
+	})
+	return err
+}
+
+// Reindex 原子地将对象的标签替换为tags，tags为空时等同于Remove
+func (ix *Index) Reindex(ctx context.Context, id string, tags ...string) error {
+	args := make([]interface{}, 0, len(tags)+2)
+	args = append(args, ix.key("tag:"), id)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	return reindexScript.Run(ctx, ix.c.rdb, []string{ix.docKey(id), ix.key("all")}, args...).Err()
+}
+
+// Remove 原子地从索引中删除对象
+func (ix *Index) Remove(ctx context.Context, id string) error {
+	return ix.Reindex(ctx, id)
+}
+
+// Tags 返回对象的所有标签
+func (ix *Index) Tags(ctx context.Context, id string) ([]string, error) {
+	return ix.c.set.SMembers(ctx, ix.docKey(id))
+}
+
+// Query 返回满足查询表达式的所有对象ID，顺序不确定
+func (ix *Index) Query(ctx context.Context, query string) ([]string, error) {
+	var ids []string
+	err := ix.run(ctx, query, func(key string) (err error) {
+		ids, err = ix.c.set.SMembers(ctx, key)
+		return err
+	})
+	return ids, err
+}
+
+// Count 返回满足查询表达式的对象数
+func (ix *Index) Count(ctx context.Context, query string) (int64, error) {
+	var n int64
+	err := ix.run(ctx, query, func(key string) (err error) {
+		n, err = ix.c.set.SCard(ctx, key)
+		return err
+	})
+	return n, err
+}
+
+// Search 分页返回满足查询表达式的对象ID
+// 参数:
+//   - ctx: 上下文
+//   - query: 查询表达式，如 (red OR blue) AND size:m AND NOT sold
+//   - opts: 排序与分页选项
+//
+// 返回:
+//   - 查询结果
+//   - 错误信息，表达式不合法时返回ErrInvalidQuery
+func (ix *Index) Search(ctx context.Context, query string, opts SearchOptions) (*Result, error) {
+	res := &Result{}
+	err := ix.run(ctx, query, func(key string) error {
+		if opts.SortBy == "" {
+			return ix.sortByID(ctx, key, opts, res)
+		}
+		return ix.sortByScore(ctx, key, opts, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// sortByID 将结果集合转为分数均为0的有序集合后按对象ID字典序分页
+func (ix *Index) sortByID(ctx context.Context, key string, opts SearchOptions, res *Result) error {
+	combine := zsetpkg.Combine{Keys: []string{key}, Weights: []float64{0}}
+	return ix.page(ctx, combine, zsetpkg.ByLex, opts, res)
+}
+
+// sortByScore 与排序有序集合求交集后按分数分页，结果集合的权重为0，因此分数即排序有序集合中的分数
+func (ix *Index) sortByScore(ctx context.Context, key string, opts SearchOptions, res *Result) error {
+	combine := zsetpkg.Combine{Keys: []string{key, opts.SortBy}, Weights: []float64{0, 1}}
+	return ix.page(ctx, combine, zsetpkg.ByScore, opts, res)
+}
+
+// page 求交集得到带分数的临时有序集合，再按by指定的区间类型分页
+func (ix *Index) page(ctx context.Context, combine zsetpkg.Combine, by zsetpkg.RangeBy, opts SearchOptions, res *Result) error {
+
+	//1.求交集得到带分数的结果
+	sorted := ix.tmpKey()
+	defer ix.c.rdb.Del(context.WithoutCancel(ctx), sorted)
+	total, err := ix.c.zset.ZInterStore(ctx, sorted, combine)
+	if err != nil {
+		return err
+	}
+
+	//2.分页，Count不大于0时返回Offset之后的全部结果
+	res.Total = total
+	res.IDs, err = ix.c.zset.ZRangeArgs(ctx, zsetpkg.RangeArgs{
+		Key: sorted, By: by, Range: zsetpkg.All, Rev: opts.Desc, Offset: opts.Offset, Count: opts.Count,
+	})
+	return err
+}
+
+// run 解析并计算查询表达式，将结果集合的key交给fn处理，结束后删除所有临时key
+func (ix *Index) run(ctx context.Context, query string, fn func(key string) error) error {
+
+	//1.解析表达式
+	expr, err := Parse(query)
+	if err != nil {
+		return err
+	}
+
+	//2.计算表达式，无论成功与否都删除临时key
+	var tmp []string
+	defer func() {
+		if len(tmp) > 0 {
+			ix.c.rdb.Del(context.WithoutCancel(ctx), tmp...)
+		}
+	}()
+	key, err := ix.eval(ctx, expr, &tmp)
+	if err != nil {
+		return err
+	}
+
+	//3.处理结果
+	return fn(key)
+}
+
+// eval 计算表达式，返回结果集合的key，新建的临时key追加到tmp中
+func (ix *Index) eval(ctx context.Context, e *Expr, tmp *[]string) (string, error) {
+	switch e.op {
+	case opTag:
+		return ix.tagKey(e.tag), nil
+
+	case opOr:
+		//1.并集：SUNIONSTORE
+		keys, err := ix.evalAll(ctx, e.children, tmp)
+		if err != nil {
+			return "", err
+		}
+		return ix.store(ctx, tmp, func(pipe redis.Pipeliner, dest string) {
+			pipe.SUnionStore(ctx, dest, keys...)
+		})
+
+	case opNot:
+		//2.单独的取反：全集与其差集
+		key, err := ix.eval(ctx, e.children[0], tmp)
+		if err != nil {
+			return "", err
+		}
+		return ix.store(ctx, tmp, func(pipe redis.Pipeliner, dest string) {
+			pipe.SDiffStore(ctx, dest, ix.key("all"), key)
+		})
+	}
+
+	//3.交集：先对肯定条件求交集，再减去否定条件，全部为否定条件时以全集为起点
+	var positive, negative []*Expr
+	for _, c := range e.children {
+		if c.op == opNot {
+			negative = append(negative, c.children[0])
+		} else {
+			positive = append(positive, c)
+		}
+	}
+	include, err := ix.evalAll(ctx, positive, tmp)
+	if err != nil {
+		return "", err
+	}
+	if len(include) == 0 {
+		include = []string{ix.key("all")}
+	}
+	exclude, err := ix.evalAll(ctx, negative, tmp)
+	if err != nil {
+		return "", err
+	}
+	return ix.store(ctx, tmp, func(pipe redis.Pipeliner, dest string) {
+		pipe.SInterStore(ctx, dest, include...)
+		if len(exclude) > 0 {
+			pipe.SDiffStore(ctx, dest, append([]string{dest}, exclude...)...)
+		}
+	})
+}
+
+// evalAll 依次计算多个表达式
+func (ix *Index) evalAll(ctx context.Context, exprs []*Expr, tmp *[]string) ([]string, error) {
+	keys := make([]string, len(exprs))
+	for i, e := range exprs {
+		key, err := ix.eval(ctx, e, tmp)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// store 新建临时key，在同一个事务中执行fn写入结果并设置过期时间
+func (ix *Index) store(ctx context.Context, tmp *[]string, fn func(pipe redis.Pipeliner, dest string)) (string, error) {
+	dest := ix.tmpKey()
+	*tmp = append(*tmp, dest)
+	_, err := ix.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		fn(pipe, dest)
+		pipe.Expire(ctx, dest, tmpTTL)
+		return nil
+	})
+	return dest, err
+}
+
+// tagKey 返回标签集合的key
+func (ix *Index) tagKey(tag string) string {
+	return ix.key("tag:" + tag)
+}
+
+// docKey 返回对象标签集合的key
+func (ix *Index) docKey(id string) string {
+	return ix.key("doc:" + id)
+}
+
+// tmpKey 返回随机的临时key
+func (ix *Index) tmpKey() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return ix.key("tmp:" + hex.EncodeToString(b))
+}
+
+// key 返回索引下的子key
+func (ix *Index) key(suffix string) string {
+	return ix.name + ":" + suffix
+}
@@ -0,0 +1,126 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-09-01 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	activitypkg "go-redis-demo/redis/activity"
+)
+
+func Test_activityClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 用户活跃客户端测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "activity_test"
+		defer cleanupKeysWithPrefix(t, ctx, name)
+
+		//1.使用固定日期，保留时长足够长以免测试数据立即过期
+		a := redis.Client.Activity.Tracker(name, &activitypkg.Options{
+			Location: time.UTC,
+			UserTTL:  10 * 365 * 24 * time.Hour,
+			DayTTL:   10 * 365 * 24 * time.Hour,
+		})
+		date := func(month time.Month, day int) time.Time {
+			return time.Date(2025, month, day, 12, 0, 0, 0, time.UTC)
+		}
+
+		//2.用户1跨月连续签到6天，用户2在1月31日首次签到，用户3在2月1日首次签到
+		marks := []struct {
+			user  int64
+			month time.Month
+			day   int
+		}{
+			{1, 1, 28}, {1, 1, 29}, {1, 1, 30}, {1, 1, 31}, {1, 2, 1}, {1, 2, 2}, {1, 2, 5},
+			{2, 1, 31}, {2, 2, 1},
+			{3, 2, 1}, {3, 2, 2},
+		}
+		for _, m := range marks {
+			first, err := a.Mark(ctx, m.user, date(m.month, m.day))
+			if err != nil || !first {
+				t.Fatal("Mark结果不符合预期", m, first, err)
+			}
+		}
+		first, err := a.Mark(ctx, 1, date(2, 5))
+		if err != nil || first {
+			t.Error("重复Mark结果不符合预期", first, err)
+		}
+		if _, err = a.Mark(ctx, -1, date(2, 5)); !errors.Is(err, activitypkg.ErrInvalidUser) {
+			t.Error("非法用户Mark结果不符合预期", err)
+		}
+
+		//3.单个用户的签到统计
+		if ok, err := a.IsActive(ctx, 1, date(1, 30)); err != nil || !ok {
+			t.Error("IsActive结果不符合预期", ok, err)
+		}
+		if ok, err := a.IsActive(ctx, 1, date(2, 3)); err != nil || ok {
+			t.Error("IsActive结果不符合预期", ok, err)
+		}
+		if n, err := a.ActiveDays(ctx, 1, date(1, 1)); err != nil || n != 4 {
+			t.Error("ActiveDays结果不符合预期", n, err)
+		}
+		if n, err := a.ActiveDaysBetween(ctx, 1, date(1, 30), date(2, 2)); err != nil || n != 4 {
+			t.Error("ActiveDaysBetween结果不符合预期", n, err)
+		}
+
+		//4.连续签到：当天未签到时从前一天算起
+		for _, c := range []struct {
+			day    int
+			streak int
+		}{{2, 6}, {3, 6}, {4, 0}, {5, 1}} {
+			if n, err := a.CurrentStreak(ctx, 1, date(2, c.day)); err != nil || n != c.streak {
+				t.Error("CurrentStreak结果不符合预期", c.day, n, err)
+			}
+		}
+		if n, err := a.LongestStreak(ctx, 1, date(1, 1), date(2, 28)); err != nil || n != 6 {
+			t.Error("LongestStreak结果不符合预期", n, err)
+		}
+		if n, err := a.LongestStreak(ctx, 1, date(1, 30), date(2, 28)); err != nil || n != 4 {
+			t.Error("截取范围后LongestStreak结果不符合预期", n, err)
+		}
+
+		//5.活跃用户数
+		if n, err := a.DAU(ctx, date(2, 1)); err != nil || n != 3 {
+			t.Error("DAU结果不符合预期", n, err)
+		}
+		if n, err := a.WAU(ctx, date(2, 2)); err != nil || n != 3 {
+			t.Error("WAU结果不符合预期", n, err)
+		}
+		if n, err := a.ActiveUsers(ctx, date(1, 28), date(1, 30)); err != nil || n != 1 {
+			t.Error("ActiveUsers结果不符合预期", n, err)
+		}
+		if n, err := a.MAU(ctx, date(2, 5)); err != nil || n != 3 {
+			t.Error("MAU结果不符合预期", n, err)
+		}
+		if n, err := a.NewUsers(ctx, date(1, 31)); err != nil || n != 1 {
+			t.Error("NewUsers结果不符合预期", n, err)
+		}
+
+		//6.留存：用户2在1月31日新增，次日仍活跃，第2天未活跃
+		r, err := a.Retention(ctx, date(1, 31), 1, 2)
+		if err != nil || r.Size != 1 || r.Retained[0] != 1 || r.Retained[1] != 0 || r.Rate(0) != 1 {
+			t.Errorf("Retention结果不符合预期: %+v %v", r, err)
+		}
+		cohorts, err := a.Cohorts(ctx, date(1, 28), date(2, 1), 1)
+		if err != nil || len(cohorts) != 5 {
+			t.Fatal("Cohorts结果不符合预期", cohorts, err)
+		}
+		if cohorts[0].Size != 1 || cohorts[0].Retained[0] != 1 || cohorts[1].Size != 0 || cohorts[1].Rate(0) != 0 {
+			t.Errorf("Cohorts结果不符合预期: %+v %+v", cohorts[0], cohorts[1])
+		}
+
+		//7.统计使用的临时key已删除
+		if n, err := redis.Client.String.Exists(ctx, name+":tmp"); err != nil || n != 0 {
+			t.Error("临时key未删除", n, err)
+		}
+	})
+}
@@ -3,6 +3,9 @@ package redis_test
 
 import (
 	"context"
+	"errors"
+	"math"
+	"reflect"
 	"testing"
 
 	redisv9 "github.com/redis/go-redis/v9"
@@ -243,6 +246,167 @@ func Test_bitMapClient(t *testing.T) {
 			"sparse:bitmap", "online:users:20250105",
 		})
 	})
+
+	t.Run("redis bitmap BitFields构造器测试", func(t *testing.T) {
+		b := redis.Client.Bitmap
+		ctx := context.Background()
+		key := "bitfield:counters"
+		defer cleanupBitmapKeys(t, ctx, []string{key})
+
+		//1.SET返回旧值，GET按声明的类型解码
+		res, err := b.BitFields(key).
+			Set(bitmappkg.Uint(8), bitmappkg.Index(0), 200).
+			Set(bitmappkg.Int(8), bitmappkg.Index(1), -5).
+			Get(bitmappkg.Uint(8), bitmappkg.Index(0)).
+			Get(bitmappkg.Int(8), bitmappkg.Bit(8)).
+			Get(bitmappkg.Uint(8), bitmappkg.Bit(8)).
+			Exec(ctx)
+		if err != nil || len(res) != 5 {
+			t.Fatal("Exec结果不符合预期", res, err)
+		}
+		if res[0].Int() != 0 || res[1].Int() != 0 || res[2].Uint() != 200 || res[3].Int() != -5 || res[4].Uint() != 251 {
+			t.Errorf("Set/Get结果不符合预期: %+v", res)
+		}
+		if res[3].Uint() != 251 {
+			t.Error("有符号结果的Uint不符合预期", res[3].Uint())
+		}
+
+		//2.溢出策略：默认回绕，SAT饱和，FAIL不执行
+		res, err = b.BitFields(key).
+			IncrBy(bitmappkg.Uint(8), bitmappkg.Index(0), 100).
+			Overflow(bitmappkg.Sat).
+			IncrBy(bitmappkg.Uint(8), bitmappkg.Index(0), 1000).
+			Overflow(bitmappkg.Fail).
+			IncrBy(bitmappkg.Uint(8), bitmappkg.Index(0), 1).
+			IncrBy(bitmappkg.Int(8), bitmappkg.Index(1), -1).
+			Exec(ctx)
+		if err != nil || len(res) != 4 {
+			t.Fatal("Exec结果不符合预期", res, err)
+		}
+		if res[0].Uint() != 44 || res[1].Uint() != 255 || !res[2].Overflowed || res[3].Overflowed || res[3].Int() != -6 {
+			t.Errorf("IncrBy结果不符合预期: %+v", res)
+		}
+
+		//3.只读执行
+		res, err = b.BitFields(key).Get(bitmappkg.Uint(8), bitmappkg.Index(0)).Get(bitmappkg.Uint(1), bitmappkg.Bit(0)).ExecRO(ctx)
+		if err != nil || res[0].Uint() != 255 || !res[1].Bool() {
+			t.Error("ExecRO结果不符合预期", res, err)
+		}
+		if _, err = b.BitFields(key).Set(bitmappkg.Uint(8), bitmappkg.Index(0), 1).ExecRO(ctx); !errors.Is(err, bitmappkg.ErrReadOnly) {
+			t.Error("只读执行SET结果不符合预期", err)
+		}
+
+		//4.参数错误在执行时返回
+		if _, err = b.BitFields(key).Get(bitmappkg.Uint(64), bitmappkg.Index(0)).Exec(ctx); err == nil {
+			t.Error("u64应返回错误")
+		}
+		if _, err = b.BitFields(key).Set(bitmappkg.Uint(8), bitmappkg.Index(0), 256).Exec(ctx); err == nil {
+			t.Error("SET超出范围应返回错误")
+		}
+		if _, err = b.BitFields(key).Overflow("IGNORE").Get(bitmappkg.Uint(8), bitmappkg.Index(0)).Exec(ctx); err == nil {
+			t.Error("不支持的溢出策略应返回错误")
+		}
+		if bitmappkg.Int(64).Min() != math.MinInt64 || bitmappkg.Uint(63).Max() != math.MaxInt64 || bitmappkg.Int(4).Max() != 7 {
+			t.Error("类型范围不符合预期")
+		}
+	})
+
+	t.Run("redis bitmap 范围统计与分析测试", func(t *testing.T) {
+		b := redis.Client.Bitmap
+		ctx := context.Background()
+		keyA, keyB := "bitmap:analytics:a", "bitmap:analytics:b"
+		defer cleanupBitmapKeys(t, ctx, []string{keyA, keyB})
+
+		//1.批量设置与清除
+		old, err := b.SetBits(ctx, keyA, 1, 3, 8, 15, 100)
+		if err != nil || !reflect.DeepEqual(old, []int64{0, 0, 0, 0, 0}) {
+			t.Error("SetBits结果不符合预期", old, err)
+		}
+		old, err = b.ClearBits(ctx, keyA, 15, 16)
+		if err != nil || !reflect.DeepEqual(old, []int64{1, 0}) {
+			t.Error("ClearBits结果不符合预期", old, err)
+		}
+
+		//2.BYTE与BIT模式的范围
+		for _, c := range []struct {
+			r     bitmappkg.Range
+			count int64
+		}{
+			{bitmappkg.ByteRange(0, 0), 2},
+			{bitmappkg.BitRange(0, 8), 3},
+			{bitmappkg.ByteRange(0, -1), 4},
+			{bitmappkg.BitRange(-8, -1), 1},
+		} {
+			if n, err := b.BitCountRange(ctx, keyA, c.r); err != nil || n != c.count {
+				t.Error("BitCountRange结果不符合预期", c.r, n, err)
+			}
+		}
+		if pos, err := b.BitPosRange(ctx, keyA, 1, bitmappkg.BitRange(4, -1)); err != nil || pos != 8 {
+			t.Error("BitPosRange结果不符合预期", pos, err)
+		}
+		if pos, err := b.BitPosRange(ctx, keyA, 1, bitmappkg.ByteRange(2, -1)); err != nil || pos != 100 {
+			t.Error("BitPosRange结果不符合预期", pos, err)
+		}
+		if _, err = b.BitPosRange(ctx, keyA, 2, bitmappkg.ByteRange(0, -1)); err == nil {
+			t.Error("bit不为0或1时应返回错误")
+		}
+
+		//3.遍历值为1的位
+		collect := func(r bitmappkg.Range, chunk int64) []int64 {
+			var offsets []int64
+			it := b.Iterate(keyA, r, chunk)
+			for it.Next(ctx) {
+				offsets = append(offsets, it.Val())
+			}
+			if err := it.Err(); err != nil {
+				t.Error(err)
+			}
+			return offsets
+		}
+		if got := collect(bitmappkg.ByteRange(0, -1), 1); !reflect.DeepEqual(got, []int64{1, 3, 8, 100}) {
+			t.Error("Iterate结果不符合预期", got)
+		}
+		if got := collect(bitmappkg.BitRange(2, 99), 4); !reflect.DeepEqual(got, []int64{3, 8}) {
+			t.Error("Iterate结果不符合预期", got)
+		}
+		if got := collect(bitmappkg.BitRange(-30, -1), 1024); !reflect.DeepEqual(got, []int64{100}) {
+			t.Error("Iterate结果不符合预期", got)
+		}
+
+		//4.导出
+		bools, err := b.Bools(ctx, keyA)
+		if err != nil || len(bools) != 104 || !bools[1] || bools[2] || !bools[100] {
+			t.Error("Bools结果不符合预期", len(bools), err)
+		}
+		offsets, err := b.Offsets(ctx, keyA)
+		if err != nil || !reflect.DeepEqual(offsets, []uint32{1, 3, 8, 100}) {
+			t.Error("Offsets结果不符合预期", offsets, err)
+		}
+		if offsets, err = b.Offsets(ctx, "nonexistent:bitmap"); err != nil || offsets != nil {
+			t.Error("不存在的key Offsets结果不符合预期", offsets, err)
+		}
+		rb, err := b.Roaring(ctx, keyA)
+		if err != nil || !reflect.DeepEqual(rb.ToArray(), []uint32{1, 3, 8, 100}) {
+			t.Error("Roaring结果不符合预期", rb, err)
+		}
+		if rb, err = b.Roaring(ctx, "nonexistent:bitmap"); err != nil || !rb.IsEmpty() {
+			t.Error("不存在的key Roaring结果不符合预期", rb, err)
+		}
+
+		//5.不写入目标key的AND/OR基数
+		if _, err = b.SetBits(ctx, keyB, 3, 8, 200); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := b.AndCount(ctx, keyA, keyB); err != nil || n != 2 {
+			t.Error("AndCount结果不符合预期", n, err)
+		}
+		if n, err := b.OrCount(ctx, keyA, keyB); err != nil || n != 5 {
+			t.Error("OrCount结果不符合预期", n, err)
+		}
+		if n, err := b.AndCount(ctx, keyA, "nonexistent:bitmap"); err != nil || n != 0 {
+			t.Error("与不存在的key AndCount结果不符合预期", n, err)
+		}
+	})
 }
 
 // 测试在线用户统计场景
@@ -0,0 +1,349 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-30 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-redis-demo/redis"
+	bloompkg "go-redis-demo/redis/bloom"
+)
+
+func Test_bloomClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 布隆过滤器容量计算测试", func(t *testing.T) {
+		bits, hashes := bloompkg.Size(1000, 0.01)
+		if bits != 9586 || hashes != 7 {
+			t.Error("Size结果不符合预期", bits, hashes)
+		}
+		bits, hashes = bloompkg.Size(1, 0.9)
+		if bits != 1 || hashes != 1 {
+			t.Error("极小过滤器Size结果不符合预期", bits, hashes)
+		}
+	})
+
+	t.Run("redis 位图布隆过滤器测试", func(t *testing.T) {
+		testBloomFilter(t, context.Background(), func(opts *bloompkg.Options) bloompkg.Filter { return redis.Client.Bloom.Bitmap(opts) }, "bloom_key")
+	})
+
+	t.Run("redis RedisBloom模块布隆过滤器测试", func(t *testing.T) {
+		ctx := context.Background()
+		skipWithoutBloomModule(t, ctx)
+		testBloomFilter(t, ctx, func(opts *bloompkg.Options) bloompkg.Filter { return redis.Client.Bloom.Module(opts) }, "bloom_module_key")
+	})
+
+	t.Run("redis BITFIELD布谷鸟过滤器测试", func(t *testing.T) {
+		ctx := context.Background()
+		newCuckoo := func(opts *bloompkg.CuckooOptions) bloompkg.Cuckoo { return redis.Client.Bloom.BitFieldCuckoo(opts) }
+		testCuckoo(t, ctx, newCuckoo, "cuckoo_key")
+		testCuckooFull(t, ctx, newCuckoo, "cuckoo_key:full")
+
+		//1.桶数超过2^25时最后的槽位超出位偏移量上限
+		if _, err := newCuckoo(&bloompkg.CuckooOptions{Capacity: 1<<27 + 1, MaxKicks: 500}).Add(ctx, "cuckoo_key:huge", "a"); !errors.Is(err, bloompkg.ErrInvalidOptions) {
+			t.Error("容量过大时Add结果不符合预期", err)
+		}
+	})
+
+	t.Run("redis RedisBloom模块布谷鸟过滤器测试", func(t *testing.T) {
+		ctx := context.Background()
+		skipWithoutBloomModule(t, ctx)
+		newCuckoo := func(opts *bloompkg.CuckooOptions) bloompkg.Cuckoo { return redis.Client.Bloom.ModuleCuckoo(opts) }
+		testCuckoo(t, ctx, newCuckoo, "cuckoo_module_key")
+		testCuckooFull(t, ctx, newCuckoo, "cuckoo_module_key:full")
+	})
+
+	t.Run("redis BITFIELD Count-Min Sketch测试", func(t *testing.T) {
+		testSketch(t, context.Background(), func(opts *bloompkg.SketchOptions) bloompkg.Sketch { return redis.Client.Bloom.BitFieldSketch(opts) }, "cms_key")
+	})
+
+	t.Run("redis RedisBloom模块Count-Min Sketch测试", func(t *testing.T) {
+		ctx := context.Background()
+		skipWithoutBloomModule(t, ctx)
+		testSketch(t, ctx, func(opts *bloompkg.SketchOptions) bloompkg.Sketch { return redis.Client.Bloom.ModuleSketch(opts) }, "cms_module_key")
+	})
+
+	t.Run("redis BITFIELD Top-K测试", func(t *testing.T) {
+		testTopK(t, context.Background(), func(opts *bloompkg.TopKOptions) bloompkg.TopK { return redis.Client.Bloom.BitFieldTopK(opts) }, "topk_key")
+	})
+
+	t.Run("redis RedisBloom模块Top-K测试", func(t *testing.T) {
+		ctx := context.Background()
+		skipWithoutBloomModule(t, ctx)
+		testTopK(t, ctx, func(opts *bloompkg.TopKOptions) bloompkg.TopK { return redis.Client.Bloom.ModuleTopK(opts) }, "topk_module_key")
+	})
+}
+
+// 服务端未加载RedisBloom模块时跳过测试
+func skipWithoutBloomModule(t *testing.T, ctx context.Context) {
+	ok, err := redis.Client.Bloom.HasModule(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Skip("服务端未加载RedisBloom模块")
+	}
+}
+
+// 测试布隆过滤器，两种实现的行为应当一致
+func testBloomFilter(t *testing.T, ctx context.Context, newFilter func(*bloompkg.Options) bloompkg.Filter, key string) {
+
+	//1.非法配置
+	if _, err := newFilter(&bloompkg.Options{Capacity: 0, ErrorRate: 0.01}).Add(ctx, key, "a"); !errors.Is(err, bloompkg.ErrInvalidOptions) {
+		t.Error("非法配置Add结果不符合预期", err)
+	}
+
+	//2.可扩容过滤器：第一层容量100，写入1000个元素后扩容为多层
+	f := newFilter(&bloompkg.Options{Capacity: 100, ErrorRate: 0.01, Expansion: 2})
+	defer f.Delete(ctx, key)
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf("user:%d", i)
+	}
+	for i := 0; i < len(items); i += 100 {
+		if _, err := f.Add(ctx, key, items[i:i+100]...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	added, err := f.Add(ctx, key, "user:0", "user:new")
+	if err != nil || added[0] || !added[1] {
+		t.Error("重复Add结果不符合预期", added, err)
+	}
+
+	//3.已添加的元素一定存在，未添加的元素误判率接近配置值
+	exists, err := f.Exists(ctx, key, items...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range exists {
+		if !ok {
+			t.Fatalf("已添加的元素%s不存在", items[i])
+		}
+	}
+	others := make([]string, 10000)
+	for i := range others {
+		others[i] = fmt.Sprintf("other:%d", i)
+	}
+	exists, err = f.Exists(ctx, key, others...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := 0
+	for _, ok := range exists {
+		if ok {
+			fp++
+		}
+	}
+	if fp > 300 {
+		t.Errorf("误判率过高: %d/%d", fp, len(others))
+	}
+	info, err := f.Info(ctx, key)
+	if err != nil || info.Layers < 4 || info.Items < 1000 || info.Capacity < info.Items {
+		t.Errorf("Info结果不符合预期: %+v %v", info, err)
+	}
+
+	//4.不扩容的过滤器写满后返回ErrFilterFull
+	fixedKey := key + ":fixed"
+	fixed := newFilter(&bloompkg.Options{Capacity: 10, ErrorRate: 0.01})
+	defer fixed.Delete(ctx, fixedKey)
+	if _, err = fixed.Add(ctx, fixedKey, items[:10]...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fixed.Add(ctx, fixedKey, items[10:20]...); !errors.Is(err, bloompkg.ErrFilterFull) {
+		t.Error("写满后Add结果不符合预期", err)
+	}
+
+	//5.删除后元素不存在
+	if err = f.Delete(ctx, key); err != nil {
+		t.Error(err)
+	}
+	exists, err = f.Exists(ctx, key, "user:0")
+	if err != nil || exists[0] {
+		t.Error("Delete后Exists结果不符合预期", exists, err)
+	}
+}
+
+// 测试布谷鸟过滤器，两种实现的行为应当一致
+func testCuckoo(t *testing.T, ctx context.Context, newCuckoo func(*bloompkg.CuckooOptions) bloompkg.Cuckoo, key string) {
+
+	//1.非法配置
+	if _, err := newCuckoo(&bloompkg.CuckooOptions{Capacity: 0, MaxKicks: 500}).Add(ctx, key, "a"); !errors.Is(err, bloompkg.ErrInvalidOptions) {
+		t.Error("非法配置Add结果不符合预期", err)
+	}
+
+	//2.添加900个元素，装载率约88%
+	f := newCuckoo(&bloompkg.CuckooOptions{Capacity: 1000, MaxKicks: 500})
+	defer f.Delete(ctx, key)
+	items := make([]string, 900)
+	for i := range items {
+		items[i] = fmt.Sprintf("user:%d", i)
+	}
+	for i := 0; i < len(items); i += 100 {
+		if _, err := f.Add(ctx, key, items[i:i+100]...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	added, err := f.Add(ctx, key, "user:0", "user:new")
+	if err != nil || added[0] || !added[1] {
+		t.Error("重复Add结果不符合预期", added, err)
+	}
+	exists, err := f.Exists(ctx, key, items...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range exists {
+		if !ok {
+			t.Fatalf("已添加的元素%s不存在", items[i])
+		}
+	}
+
+	//3.删除前一半元素
+	removed, err := f.Remove(ctx, key, items[:450]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range removed {
+		if !ok {
+			t.Fatalf("元素%s删除失败", items[i])
+		}
+	}
+	removed, err = f.Remove(ctx, key, "user:never")
+	if err != nil || removed[0] {
+		t.Error("删除不存在的元素结果不符合预期", removed, err)
+	}
+	exists, err = f.Exists(ctx, key, items...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := 0
+	for i, ok := range exists {
+		if i >= 450 && !ok {
+			t.Fatalf("未删除的元素%s不存在", items[i])
+		}
+		if i < 450 && ok {
+			fp++
+		}
+	}
+	if fp > 5 {
+		t.Errorf("删除后误判过多: %d", fp)
+	}
+	info, err := f.Info(ctx, key)
+	if err != nil || info.Items != 451 || info.Capacity < 1000 {
+		t.Errorf("Info结果不符合预期: %+v %v", info, err)
+	}
+
+	//4.删除后过滤器不存在
+	if err = f.Delete(ctx, key); err != nil {
+		t.Error(err)
+	}
+	info, err = f.Info(ctx, key)
+	if err != nil || info.Items != 0 || info.Capacity != 0 {
+		t.Errorf("Delete后Info结果不符合预期: %+v %v", info, err)
+	}
+}
+
+// 测试布谷鸟过滤器不扩容，写满后返回ErrFilterFull
+func testCuckooFull(t *testing.T, ctx context.Context, newCuckoo func(*bloompkg.CuckooOptions) bloompkg.Cuckoo, key string) {
+	f := newCuckoo(&bloompkg.CuckooOptions{Capacity: 100, MaxKicks: 100})
+	defer f.Delete(ctx, key)
+	var err error
+	for i := 0; i < 1000 && err == nil; i++ {
+		_, err = f.Add(ctx, key, fmt.Sprintf("item:%d", i))
+	}
+	if !errors.Is(err, bloompkg.ErrFilterFull) {
+		t.Error("写满后Add结果不符合预期", err)
+	}
+	info, err := f.Info(ctx, key)
+	if err != nil || info.Capacity != 128 || info.Items < 100 || info.Items > 128 {
+		t.Errorf("写满后Info结果不符合预期: %+v %v", info, err)
+	}
+}
+
+// 测试Count-Min Sketch，两种实现的行为应当一致
+func testSketch(t *testing.T, ctx context.Context, newSketch func(*bloompkg.SketchOptions) bloompkg.Sketch, key string) {
+
+	//1.非法配置与非法增量
+	if _, err := newSketch(&bloompkg.SketchOptions{Width: 0, Depth: 5}).Incr(ctx, key, "a"); !errors.Is(err, bloompkg.ErrInvalidOptions) {
+		t.Error("非法配置Incr结果不符合预期", err)
+	}
+	s := newSketch(&bloompkg.SketchOptions{Width: 1000, Depth: 5})
+	defer s.Delete(ctx, key)
+	if _, err := s.IncrBy(ctx, key, "a", 0); err == nil {
+		t.Error("增量为0时应返回错误")
+	}
+
+	//2.不存在的Sketch查询结果为0
+	counts, err := s.Query(ctx, key, "a")
+	if err != nil || counts[0] != 0 {
+		t.Error("Sketch不存在时Query结果不符合预期", counts, err)
+	}
+
+	//3.增加计数
+	if n, err := s.IncrBy(ctx, key, "a", 100); err != nil || n < 100 {
+		t.Error("IncrBy结果不符合预期", n, err)
+	}
+	if n, err := s.IncrBy(ctx, key, "b", 10); err != nil || n < 10 {
+		t.Error("IncrBy结果不符合预期", n, err)
+	}
+	counts, err = s.Incr(ctx, key, "c", "c", "a")
+	if err != nil || counts[0] < 1 || counts[1] < 2 || counts[2] < 101 {
+		t.Error("Incr结果不符合预期", counts, err)
+	}
+
+	//4.估计值不低于真实值，数据量很小时应当精确
+	counts, err = s.Query(ctx, key, "a", "b", "c", "d")
+	if err != nil || counts[0] != 101 || counts[1] != 10 || counts[2] != 2 || counts[3] != 0 {
+		t.Error("Query结果不符合预期", counts, err)
+	}
+	info, err := s.Info(ctx, key)
+	if err != nil || info.Width != 1000 || info.Depth != 5 || info.Count != 113 {
+		t.Errorf("Info结果不符合预期: %+v %v", info, err)
+	}
+}
+
+// 测试Top-K，两种实现的行为应当一致
+func testTopK(t *testing.T, ctx context.Context, newTopK func(*bloompkg.TopKOptions) bloompkg.TopK, key string) {
+
+	//1.非法配置
+	if _, err := newTopK(&bloompkg.TopKOptions{K: 0, Width: 1000, Depth: 5}).Add(ctx, key, "a"); !errors.Is(err, bloompkg.ErrInvalidOptions) {
+		t.Error("非法配置Add结果不符合预期", err)
+	}
+
+	//2.交替添加出现次数不同的元素
+	tk := newTopK(&bloompkg.TopKOptions{K: 3, Width: 1000, Depth: 5})
+	defer tk.Delete(ctx, key)
+	freq := map[string]int{"a": 50, "b": 40, "c": 30, "d": 5, "e": 1}
+	for round := 0; round < 50; round++ {
+		var items []string
+		for _, item := range []string{"e", "d", "c", "b", "a"} {
+			if round < freq[item] {
+				items = append(items, item)
+			}
+		}
+		if _, err := tk.Add(ctx, key, items...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//3.出现次数最多的3个元素
+	list, err := tk.List(ctx, key)
+	if err != nil || len(list) != 3 {
+		t.Fatal("List结果不符合预期", list, err)
+	}
+	for i, item := range []string{"a", "b", "c"} {
+		if list[i].Item != item || list[i].Count != int64(freq[item]) {
+			t.Error("List结果不符合预期", list)
+		}
+	}
+	in, err := tk.Query(ctx, key, "a", "c", "d", "x")
+	if err != nil || !in[0] || !in[1] || in[2] || in[3] {
+		t.Error("Query结果不符合预期", in, err)
+	}
+}
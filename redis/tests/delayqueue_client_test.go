@@ -0,0 +1,108 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-19 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	delayqueuepkg "go-redis-demo/redis/delayqueue"
+)
+
+func Test_delayQueueClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 延时队列测试", func(t *testing.T) {
+		d := redis.Client.DelayQueue
+		ctx := context.Background()
+		queue := "delayqueue_key"
+		defer redis.Client.String.Del(ctx, queue+":delayed", queue+":jobs", queue+":ready", queue+":processing", queue+":reserved")
+
+		//1.提交两个延时任务
+		id1, err := d.ScheduleAfter(ctx, queue, "job1", 200*time.Millisecond)
+		if id1 == "" || err != nil {
+			t.Fatal("ScheduleAfter结果不符合预期", err)
+		}
+		id2, err := d.ScheduleAfter(ctx, queue, "job2", time.Hour)
+		if id2 == "" || err != nil {
+			t.Fatal("ScheduleAfter结果不符合预期", err)
+		}
+		pending, err := d.Pending(ctx, queue)
+		if pending != 2 || err != nil {
+			t.Error("Pending结果不符合预期")
+		}
+
+		//2.未到期时不会移动到就绪列表
+		n, err := d.Poll(ctx, queue, 10, time.Minute)
+		if n != 0 || err != nil {
+			t.Error("未到期Poll结果不符合预期")
+		}
+
+		//3.到期后移动到就绪列表并被取出
+		time.Sleep(250 * time.Millisecond)
+		n, err = d.Poll(ctx, queue, 10, time.Minute)
+		if n != 1 || err != nil {
+			t.Error("到期Poll结果不符合预期")
+		}
+		job, err := d.Reserve(ctx, queue, time.Second)
+		if job == nil || err != nil || job.ID != id1 || job.Payload != "job1" {
+			t.Fatal("Reserve结果不符合预期", job, err)
+		}
+
+		//4.已取出的任务无法取消
+		if err = d.Cancel(ctx, queue, id1); !errors.Is(err, delayqueuepkg.ErrJobNotFound) {
+			t.Error("取消已取出任务结果不符合预期", err)
+		}
+
+		//5.未确认的任务超时后重新投递
+		time.Sleep(100 * time.Millisecond)
+		n, err = d.Poll(ctx, queue, 10, 50*time.Millisecond)
+		if n != 1 || err != nil {
+			t.Error("超时回收Poll结果不符合预期")
+		}
+		job, err = d.Reserve(ctx, queue, time.Second)
+		if job == nil || err != nil || job.ID != id1 {
+			t.Fatal("重新投递Reserve结果不符合预期", job, err)
+		}
+
+		//6.确认任务，重复确认返回ErrJobNotFound
+		if err = d.Ack(ctx, job); err != nil {
+			t.Error(err)
+		}
+		if err = d.Ack(ctx, job); !errors.Is(err, delayqueuepkg.ErrJobNotFound) {
+			t.Error("重复Ack结果不符合预期", err)
+		}
+
+		//7.改期任务
+		runAt := time.Now().Add(100 * time.Millisecond)
+		if err = d.Reschedule(ctx, queue, id2, runAt); err != nil {
+			t.Error(err)
+		}
+		at, err := d.RunAt(ctx, queue, id2)
+		if at.UnixMilli() != runAt.UnixMilli() || err != nil {
+			t.Error("RunAt结果不符合预期")
+		}
+
+		//8.取消任务
+		if err = d.Cancel(ctx, queue, id2); err != nil {
+			t.Error(err)
+		}
+		pending, err = d.Pending(ctx, queue)
+		if pending != 0 || err != nil {
+			t.Error("取消后Pending结果不符合预期")
+		}
+
+		//9.无任务时Reserve超时返回nil
+		job, err = d.Reserve(ctx, queue, 100*time.Millisecond)
+		if job != nil || err != nil {
+			t.Error("空队列Reserve结果不符合预期")
+		}
+	})
+}
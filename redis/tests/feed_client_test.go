@@ -0,0 +1,100 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-21 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	feedpkg "go-redis-demo/redis/feed"
+)
+
+func Test_feedClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 动态流测试", func(t *testing.T) {
+		ctx := context.Background()
+		keys := []string{"feed_key", "feed_key:expiry", "feed_key:seq", "feed_follower1", "feed_follower1:seq",
+			"feed_follower2", "feed_follower2:seq", "feed_follower3", "feed_follower3:seq"}
+		defer redis.Client.String.Del(ctx, keys...)
+
+		opts := feedpkg.DefaultOptions()
+		opts.Cap = 5
+		f := redis.Client.Feed.Feed("feed_key", opts)
+
+		//1.非法容量
+		_, err := redis.Client.Feed.Feed("feed_key", &feedpkg.Options{}).Push(ctx, "x")
+		if !errors.Is(err, feedpkg.ErrInvalidCap) {
+			t.Error("非法容量Push结果不符合预期", err)
+		}
+
+		//2.推入超过容量的动态，只保留最新的5条
+		for i := 1; i <= 7; i++ {
+			seq, err := f.Push(ctx, "event"+strconv.Itoa(i))
+			if seq != int64(i) || err != nil {
+				t.Fatal("Push结果不符合预期", seq, err)
+			}
+		}
+		n, err := f.Len(ctx)
+		if n != 5 || err != nil {
+			t.Error("Len结果不符合预期", n, err)
+		}
+
+		//3.按游标分页，两页之间推入新动态不影响下一页
+		page, err := f.Page(ctx, 0, 3)
+		if err != nil || len(page.Items) != 3 || page.Items[0].Payload != "event7" || page.Next != 5 {
+			t.Fatalf("Page结果不符合预期: %+v %v", page, err)
+		}
+		if _, err = f.Push(ctx, "event8"); err != nil {
+			t.Error(err)
+		}
+		page, err = f.Page(ctx, page.Next, 3)
+		if err != nil || len(page.Items) != 1 || page.Items[0].Payload != "event4" || page.Next != 0 {
+			t.Fatalf("第二页Page结果不符合预期: %+v %v", page, err)
+		}
+
+		//4.单条动态过期后不再返回
+		if _, err = f.PushWithTTL(ctx, 100*time.Millisecond, "short:lived"); err != nil {
+			t.Error(err)
+		}
+		page, err = f.Page(ctx, 0, 1)
+		if err != nil || len(page.Items) != 1 || page.Items[0].Payload != "short:lived" {
+			t.Fatalf("带过期时间Page结果不符合预期: %+v %v", page, err)
+		}
+		time.Sleep(200 * time.Millisecond)
+		page, err = f.Page(ctx, 0, 1)
+		if err != nil || len(page.Items) != 1 || page.Items[0].Payload != "event8" {
+			t.Fatalf("过期后Page结果不符合预期: %+v %v", page, err)
+		}
+
+		//5.清空后序号保留
+		if err = f.Clear(ctx); err != nil {
+			t.Error(err)
+		}
+		seq, err := f.Push(ctx, "event10")
+		if seq != 10 || err != nil {
+			t.Error("清空后Push结果不符合预期", seq, err)
+		}
+
+		//6.写扩散到多个动态流
+		followers := []string{"feed_follower1", "feed_follower2", "feed_follower3"}
+		opts.Parallelism = 2
+		if err = redis.Client.Feed.FanOut(ctx, followers, opts, "post1", "post2"); err != nil {
+			t.Error(err)
+		}
+		for _, key := range followers {
+			page, err = redis.Client.Feed.Feed(key, opts).Page(ctx, 0, 10)
+			if err != nil || len(page.Items) != 2 || page.Items[0].Payload != "post2" {
+				t.Errorf("FanOut结果不符合预期: %s %+v %v", key, page, err)
+			}
+		}
+	})
+}
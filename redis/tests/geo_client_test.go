@@ -2,8 +2,13 @@
 package redis_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	redisv9 "github.com/redis/go-redis/v9"
 	"go-redis-demo/redis"
@@ -46,8 +51,31 @@ func Test_geoClient(t *testing.T) {
 			{Longitude: 114.085947, Latitude: 22.547, Name: "深圳"},
 			{Longitude: 104.065735, Latitude: 30.659462, Name: "成都"},
 		}
-		count, err = g.GeoBatchAdd(ctx, "geo_key", locations...)
-		if count != 3 || err != nil {
+		count, rejected, err := g.GeoBatchAdd(ctx, "geo_key", locations...)
+		if count != 3 || len(rejected) != 0 || err != nil {
+			t.Error(err)
+		}
+
+		//4.1.超出范围的经纬度被拒绝，批量添加时报告被拒绝的位置，其余位置正常添加
+		if _, err = g.GeoAdd(ctx, "geo_key", 116.397128, 86, "北极附近"); !errors.Is(err, geopkg.ErrInvalidCoordinate) {
+			t.Error("纬度超出范围未返回预期错误", err)
+		}
+		locations = []*redisv9.GeoLocation{
+			{Longitude: 181, Latitude: 0, Name: "经度越界"},
+			{Longitude: 120.153576, Latitude: 30.287459, Name: "杭州"},
+			{Longitude: 0, Latitude: -geopkg.MaxLatitude - 0.001, Name: "纬度越界"},
+			nil,
+		}
+		count, rejected, err = g.GeoBatchAdd(ctx, "geo_key", locations...)
+		if count != 1 || err != nil || len(rejected) != 3 {
+			t.Fatal("部分无效的批量添加结果不符合预期", count, rejected, err)
+		}
+		for i, r := range rejected {
+			if r.Index != []int{0, 2, 3}[i] || r.Location != locations[r.Index] || !errors.Is(r.Err, geopkg.ErrInvalidCoordinate) {
+				t.Errorf("被拒绝的位置不符合预期: %+v", r)
+			}
+		}
+		if _, err = redis.Client.ZSet.ZRem(ctx, "geo_key", "杭州"); err != nil {
 			t.Error(err)
 		}
 
@@ -65,7 +93,7 @@ func Test_geoClient(t *testing.T) {
 		}
 
 		//6.计算两个位置之间的距离（单位：千米）
-		distance, err := g.GeoDist(ctx, "geo_key", "北京", "上海", "km")
+		distance, err := g.GeoDist(ctx, "geo_key", "北京", "上海", geopkg.KM)
 		if err != nil {
 			t.Error(err)
 		}
@@ -76,9 +104,13 @@ func Test_geoClient(t *testing.T) {
 
 		//7.测试无效的距离单位
 		_, err = g.GeoDist(ctx, "geo_key", "北京", "上海", "invalid")
-		if err == nil || err.Error() != "无效的距离单位，必须是m、km、mi或ft之一" {
+		if !errors.Is(err, geopkg.ErrInvalidUnit) {
 			t.Error("无效距离单位未返回预期错误")
 		}
+		_, err = g.GeoDist(ctx, "geo_key", "北京", "不存在的城市", geopkg.KM)
+		if !errors.Is(err, geopkg.ErrMemberNotFound) {
+			t.Error("不存在的位置未返回预期错误", err)
+		}
 
 		//8.获取地理位置的Geohash表示
 		hashes, err := g.GeoHash(ctx, "geo_key", "北京", "上海", "不存在的城市")
@@ -89,52 +121,77 @@ func Test_geoClient(t *testing.T) {
 			t.Error("GeoHash结果不符合预期")
 		}
 
-		//9.以坐标为中心进行半径搜索
-		radius, err := g.GeoRadius(ctx, "geo_key", 116.397128, 39.916527, 1500, "km", true, true, true, 0)
+		//9.以坐标为中心进行半径搜索，按距离从近到远返回详细信息
+		radius, err := g.Query("geo_key").FromLonLat(116.397128, 39.916527).ByRadius(1500, geopkg.KM).
+			Asc().WithCoord().WithDist().WithHash().Locations(ctx)
 		if err != nil {
 			t.Error(err)
 		}
-		// 应该返回所有添加的城市（北京和上海）
-		if len(radius) != 2 {
-			t.Errorf("GeoRadius结果不符合预期，应返回2个城市，实际返回%d个", len(radius))
+		// 应该返回北京和上海，北京距离最近
+		if len(radius) != 2 || radius[0].Name != "北京" || radius[1].Dist < 1000 || radius[1].GeoHash == 0 {
+			t.Errorf("半径搜索结果不符合预期: %+v", radius)
 		}
 
-		//10.测试无效的距离单位进行半径搜索
-		_, err = g.GeoRadius(ctx, "geo_key", 116.397128, 39.916527, 1500, "invalid", true, true, true, 0)
-		if err == nil || err.Error() != "无效的距离单位，必须是m、km、mi或ft之一" {
+		//10.测试无效的距离单位与缺少参数的搜索
+		_, err = g.Query("geo_key").FromLonLat(116.397128, 39.916527).ByRadius(1500, "invalid").Members(ctx)
+		if !errors.Is(err, geopkg.ErrInvalidUnit) {
 			t.Error("无效距离单位未返回预期错误")
 		}
-
-		//11.以成员为中心进行半径搜索
-		radius, err = g.GeoRadiusByMember(ctx, "geo_key", "北京", 1500, "km", true, true, true, 0)
-		if err != nil {
-			t.Error(err)
+		_, err = g.Query("geo_key").FromLonLat(200, 0).ByRadius(1500, geopkg.KM).Members(ctx)
+		if !errors.Is(err, geopkg.ErrInvalidCoordinate) {
+			t.Error("无效搜索中心未返回预期错误")
+		}
+		if _, err = g.Query("geo_key").ByRadius(1500, geopkg.KM).Members(ctx); !errors.Is(err, geopkg.ErrNoCenter) {
+			t.Error("未指定搜索中心未返回预期错误", err)
+		}
+		if _, err = g.Query("geo_key").FromMember("").ByRadius(1500, geopkg.KM).Members(ctx); !errors.Is(err, geopkg.ErrNoCenter) {
+			t.Error("空的搜索中心未返回预期错误", err)
 		}
-		// 应该返回所有添加的城市（北京和上海）
-		if len(radius) != 2 {
-			t.Errorf("GeoRadiusByMember结果不符合预期，应返回2个城市，实际返回%d个", len(radius))
+		if _, err = g.Query("geo_key").FromMember("北京").Members(ctx); !errors.Is(err, geopkg.ErrNoShape) {
+			t.Error("未指定搜索范围未返回预期错误", err)
+		}
+		if _, err = g.Query("geo_key").FromMember("北京").ByRadius(0, geopkg.KM).Members(ctx); !errors.Is(err, geopkg.ErrInvalidShape) {
+			t.Error("搜索半径为0未返回预期错误", err)
 		}
 
-		//12.测试无效的距离单位进行成员半径搜索
-		_, err = g.GeoRadiusByMember(ctx, "geo_key", "北京", 1500, "invalid", true, true, true, 0)
-		if err == nil || err.Error() != "无效的距离单位，必须是m、km、mi或ft之一" {
-			t.Error("无效距离单位未返回预期错误")
+		//11.以成员为中心进行矩形搜索，广州与深圳相距约100公里
+		members, err := g.Query("geo_key").FromMember("广州").ByBox(400, 400, geopkg.KM).Asc().Members(ctx)
+		if err != nil || len(members) != 2 || members[0] != "广州" || members[1] != "深圳" {
+			t.Error("矩形搜索结果不符合预期", members, err)
 		}
 
-		//13.限制返回数量的半径搜索
-		radius, err = g.GeoRadius(ctx, "geo_key", 116.397128, 39.916527, 1500, "km", true, true, true, 2)
-		if err != nil {
-			t.Error(err)
+		//12.限制返回数量，倒序时返回最远的城市
+		members, err = g.Query("geo_key").FromMember("北京").ByRadius(3000, geopkg.KM).Desc().Count(1).Members(ctx)
+		if err != nil || len(members) != 1 || members[0] != "深圳" {
+			t.Error("限制数量的搜索结果不符合预期", members, err)
 		}
-		// 应该只返回2个城市
-		if len(radius) != 2 {
-			t.Errorf("限制数量的GeoRadius结果不符合预期，应返回2个城市，实际返回%d个", len(radius))
+		members, err = g.Query("geo_key").FromMember("北京").ByRadius(3000, geopkg.KM).CountAny(2).Members(ctx)
+		if err != nil || len(members) != 2 {
+			t.Error("ANY搜索结果不符合预期", members, err)
+		}
+		if _, err = g.Query("geo_key").FromMember("北京").ByRadius(3000, geopkg.KM).CountAny(0).Members(ctx); !errors.Is(err, geopkg.ErrInvalidCount) {
+			t.Error("ANY未指定数量未返回预期错误", err)
+		}
+
+		//13.搜索结果存储为地理位置集合与距离有序集合
+		defer redis.Client.String.Del(ctx, "geo_store_key", "geo_dist_key")
+		count, err = g.Query("geo_key").FromMember("上海").ByRadius(1100, geopkg.KM).Store(ctx, "geo_store_key")
+		if count != 2 || err != nil {
+			t.Error("Store结果不符合预期", count, err)
+		}
+		count, err = g.Query("geo_key").FromMember("上海").ByRadius(1100, geopkg.KM).StoreDist(ctx, "geo_dist_key")
+		if count != 2 || err != nil {
+			t.Error("StoreDist结果不符合预期", count, err)
+		}
+		dist, err := redis.Client.ZSet.ZScore(ctx, "geo_dist_key", "北京")
+		if err != nil || dist < 1000 || dist > 1100 {
+			t.Error("StoreDist存储的距离不符合预期", dist, err)
 		}
 
 		//14.测试不存在的成员进行半径搜索
-		radius, err = g.GeoRadiusByMember(ctx, "geo_key", "不存在的城市", 1500, "km", true, true, true, 0)
-		if err == nil || len(radius) != 0 {
-			t.Error("不存在的成员进行半径搜索应返回错误或空结果")
+		radius, err = g.Query("geo_key").FromMember("不存在的城市").ByRadius(1500, geopkg.KM).Locations(ctx)
+		if !errors.Is(err, geopkg.ErrMemberNotFound) || len(radius) != 0 {
+			t.Error("不存在的成员进行半径搜索应返回ErrMemberNotFound", err)
 		}
 
 		//15.清理测试数据
@@ -146,6 +203,97 @@ func Test_geoClient(t *testing.T) {
 		//16.测试空geo的操作
 		testEmptyGeoOperations(t, g, ctx, "empty_geo")
 	})
+
+	t.Run("redis geo移动对象跟踪测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "geo_tracker"
+		defer func() {
+			keys, _ := redis.Client.String.Keys(ctx, name+":*")
+			if len(keys) > 0 {
+				redis.Client.String.Del(ctx, keys...)
+			}
+		}()
+		testGeoTracker(t, ctx, redis.Client.Geo.Tracker(name, &geopkg.TrackerOptions{TTL: time.Second, HistorySize: 3}))
+	})
+
+	t.Run("redis geo导入导出测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "geo_io"
+		defer func() {
+			keys, _ := redis.Client.String.Keys(ctx, name+"*")
+			if len(keys) > 0 {
+				redis.Client.String.Del(ctx, keys...)
+			}
+		}()
+		testGeoImportExport(t, ctx, redis.Client.Geo, name)
+	})
+}
+
+// 测试移动对象跟踪
+func testGeoTracker(t *testing.T, ctx context.Context, tr *geopkg.Tracker) {
+
+	//1.上报位置，courier1连续移动4次，只保留最近3个历史位置
+	for i := 0; i < 4; i++ {
+		if _, err := tr.Update(ctx, "courier1", 116.40+float64(i)*0.01, 39.90); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := tr.Update(ctx, "courier2", 116.50, 39.90); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Update(ctx, "courier3", 116.40, 86); !errors.Is(err, geopkg.ErrInvalidCoordinate) {
+		t.Error("超出范围的位置Update结果不符合预期", err)
+	}
+	history, err := tr.History(ctx, "courier1", 0)
+	if err != nil || len(history) != 3 || !floatEquals(history[0].Longitude, 116.43, 0.0001) || history[2].Time.After(history[0].Time) {
+		t.Errorf("History结果不符合预期: %+v %v", history, err)
+	}
+	if history, err = tr.History(ctx, "courier1", -1); err != nil || len(history) != 3 {
+		t.Errorf("n为负数时History结果不符合预期: %+v %v", history, err)
+	}
+	if history, err = tr.History(ctx, "courier1", 2); err != nil || len(history) != 2 {
+		t.Errorf("限制数量History结果不符合预期: %+v %v", history, err)
+	}
+	pos, err := tr.Position(ctx, "courier1")
+	if err != nil || !floatEquals(pos.Longitude, 116.43, 0.001) {
+		t.Errorf("Position结果不符合预期: %+v %v", pos, err)
+	}
+
+	//2.最近的活跃对象
+	nearest, err := tr.Nearest(ctx, 116.40, 39.90, 50, geopkg.KM, 1)
+	if err != nil || len(nearest) != 1 || nearest[0].Name != "courier1" || nearest[0].Dist <= 0 {
+		t.Errorf("Nearest结果不符合预期: %+v %v", nearest, err)
+	}
+
+	//3.超过TTL未上报的对象被淘汰，历史位置一并删除
+	time.Sleep(1200 * time.Millisecond)
+	if _, err = tr.Update(ctx, "courier2", 116.51, 39.90); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tr.Position(ctx, "courier1"); !errors.Is(err, geopkg.ErrMemberNotFound) {
+		t.Error("不活跃对象Position结果不符合预期", err)
+	}
+	evicted, err := tr.Evict(ctx)
+	if err != nil || len(evicted) != 1 || evicted[0] != "courier1" {
+		t.Error("Evict结果不符合预期", evicted, err)
+	}
+	nearest, err = tr.Nearest(ctx, 116.40, 39.90, 50, geopkg.KM, 10)
+	if err != nil || len(nearest) != 1 || nearest[0].Name != "courier2" {
+		t.Errorf("淘汰后Nearest结果不符合预期: %+v %v", nearest, err)
+	}
+	history, err = tr.History(ctx, "courier1", 0)
+	if err != nil || len(history) != 0 {
+		t.Error("淘汰后History结果不符合预期", history, err)
+	}
+
+	//4.删除对象
+	if err = tr.Remove(ctx, "courier2"); err != nil {
+		t.Error(err)
+	}
+	n, err := tr.Active(ctx)
+	if n != 0 || err != nil {
+		t.Error("Remove后Active结果不符合预期", n, err)
+	}
 }
 
 // 测试空geo的各种操作
@@ -158,8 +306,8 @@ func testEmptyGeoOperations(t *testing.T, g *geopkg.Client, ctx context.Context,
 	}
 
 	//2.计算不存在的位置之间的距离
-	_, err = g.GeoDist(ctx, key, "pos1", "pos2", "km")
-	if err == nil || err.Error() != "redis: nil" {
+	_, err = g.GeoDist(ctx, key, "pos1", "pos2", geopkg.KM)
+	if !errors.Is(err, geopkg.ErrMemberNotFound) {
 		t.Error("空geo的GeoDist结果不符合预期")
 	}
 
@@ -170,15 +318,88 @@ func testEmptyGeoOperations(t *testing.T, g *geopkg.Client, ctx context.Context,
 	}
 
 	//4.以坐标为中心进行半径搜索
-	locations, err := g.GeoRadius(ctx, key, 0, 0, 100, "km", true, true, true, 0)
+	locations, err := g.Query(key).FromLonLat(0, 0).ByRadius(100, geopkg.KM).WithDist().Locations(ctx)
 	if err != nil || len(locations) != 0 {
-		t.Error("空geo的GeoRadius结果不符合预期")
+		t.Error("空geo的半径搜索结果不符合预期")
 	}
 
 	//5.以成员为中心进行半径搜索
-	locations, err = g.GeoRadiusByMember(ctx, key, "nonexistent", 100, "km", true, true, true, 0)
+	locations, err = g.Query(key).FromMember("nonexistent").ByRadius(100, geopkg.KM).Locations(ctx)
 	if err != nil || len(locations) != 0 {
-		t.Error("空geo的GeoRadiusByMember结果不符合预期")
+		t.Error("空geo的成员半径搜索结果不符合预期")
+	}
+}
+
+// 测试GeoJSON与CSV导入、GeoJSON导出
+func testGeoImportExport(t *testing.T, ctx context.Context, g *geopkg.Client, key string) {
+	opts := &geopkg.ImportOptions{ChunkSize: 2, PropsPrefix: key + ":props:"}
+
+	//1.导入GeoJSON，非Point要素、缺少名称与纬度越界的要素被拒绝
+	geojson := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","id":"北京","geometry":{"type":"Point","coordinates":[116.397128,39.916527]},"properties":{"level":1,"city":"北京市"}},
+		{"type":"Feature","id":"区域","geometry":{"type":"Polygon","coordinates":[]},"properties":{}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[121.47,31.23]},"properties":{}},
+		{"type":"Feature","id":"北极","geometry":{"type":"Point","coordinates":[0,89]},"properties":{}},
+		{"type":"Feature","id":"上海","geometry":{"type":"Point","coordinates":[121.473701,31.230416]},"properties":{"level":1}}]}`
+	res, err := g.ImportGeoJSON(ctx, key, strings.NewReader(geojson), opts)
+	if err != nil || res.Added != 2 || len(res.Rejected) != 3 {
+		t.Fatalf("ImportGeoJSON结果不符合预期: %+v %v", res, err)
+	}
+	for i, expected := range []error{geopkg.ErrInvalidFeature, geopkg.ErrInvalidFeature, geopkg.ErrInvalidCoordinate} {
+		if r := res.Rejected[i]; r.Index != i+1 || !errors.Is(r.Err, expected) {
+			t.Errorf("ImportGeoJSON被拒绝的要素不符合预期: %+v", r)
+		}
+	}
+
+	//2.导入CSV，支持经纬度列与wkt列
+	csvData := "name,lon,lat,level\n广州,113.264434,23.129162,2\n深圳,114.085947,22.547,\n坏数据,abc,22.5,3\n"
+	res, err = g.ImportCSV(ctx, key, strings.NewReader(csvData), opts)
+	if err != nil || res.Added != 2 || len(res.Rejected) != 1 || res.Rejected[0].Index != 2 || !errors.Is(res.Rejected[0].Err, geopkg.ErrInvalidFeature) {
+		t.Fatalf("ImportCSV结果不符合预期: %+v %v", res, err)
+	}
+	res, err = g.ImportCSV(ctx, key, strings.NewReader("id,wkt\n成都,POINT (104.065735 30.659462)\n"), opts)
+	if err != nil || res.Added != 1 || len(res.Rejected) != 0 {
+		t.Fatalf("ImportCSV wkt结果不符合预期: %+v %v", res, err)
+	}
+	if _, err = g.ImportCSV(ctx, key, strings.NewReader("name,level\n杭州,1\n"), opts); err == nil {
+		t.Error("缺少坐标列的CSV未返回错误")
+	}
+
+	//3.导出为GeoJSON，属性来自属性哈希
+	var buf bytes.Buffer
+	n, err := g.ExportGeoJSON(ctx, key, &buf, &geopkg.ExportOptions{ScanCount: 2, PropsPrefix: opts.PropsPrefix})
+	if err != nil || n != 5 {
+		t.Fatal("ExportGeoJSON结果不符合预期", n, err)
+	}
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			ID         string `json:"id"`
+			Geometry   struct{ Coordinates []float64 }
+			Properties map[string]string `json:"properties"`
+		} `json:"features"`
+	}
+	if err = json.Unmarshal(buf.Bytes(), &fc); err != nil || fc.Type != "FeatureCollection" || len(fc.Features) != 5 {
+		t.Fatal("导出的GeoJSON不符合预期", buf.String(), err)
+	}
+	for _, f := range fc.Features {
+		switch f.ID {
+		case "北京":
+			if f.Properties["level"] != "1" || f.Properties["city"] != "北京市" || !floatEquals(f.Geometry.Coordinates[0], 116.397128, 0.001) {
+				t.Errorf("导出的北京不符合预期: %+v", f)
+			}
+		case "深圳":
+			if len(f.Properties) != 0 {
+				t.Errorf("导出的深圳不符合预期: %+v", f)
+			}
+		}
+	}
+
+	//4.导出空key
+	buf.Reset()
+	n, err = g.ExportGeoJSON(ctx, key+":empty", &buf, nil)
+	if err != nil || n != 0 || buf.String() != `{"type":"FeatureCollection","features":[]}` {
+		t.Error("导出空key结果不符合预期", buf.String(), err)
 	}
 }
 
@@ -0,0 +1,143 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-27 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	geopkg "go-redis-demo/redis/geo"
+	geofencepkg "go-redis-demo/redis/geofence"
+)
+
+// zoneA 带洞的矩形区域，zoneB 与zoneA北部重叠的矩形区域
+const (
+	zoneA = `{"type":"Feature","properties":{"name":"A"},"geometry":{"type":"Polygon","coordinates":[
+		[[116.0,39.8],[116.2,39.8],[116.2,40.0],[116.0,40.0],[116.0,39.8]],
+		[[116.05,39.85],[116.1,39.85],[116.1,39.9],[116.05,39.9],[116.05,39.85]]]}}`
+	zoneB = `{"type":"Polygon","coordinates":[[[115.9,39.95],[116.15,39.95],[116.15,40.1],[115.9,40.1],[115.9,39.95]]]}`
+)
+
+func Test_geofenceClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 电子围栏测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "geofence_key"
+		defer cleanupKeysWithPrefix(t, ctx, name)
+		f := redis.Client.Geofence.Fence(name)
+
+		//1.解析GeoJSON
+		invalid := map[string]error{
+			`{"type":"Point","coordinates":[116,39]}`:                                  geofencepkg.ErrInvalidGeoJSON,
+			`{"type":"Polygon","coordinates":[[[116,39],[117,39],[117,40],[116,40]]]}`: geofencepkg.ErrInvalidGeoJSON,
+			`{"type":"Polygon","coordinates":[[[116,39],[117,39],[117,40]]]}`:          geofencepkg.ErrInvalidGeoJSON,
+			`{"type":"Feature"}`: geofencepkg.ErrInvalidGeoJSON,
+			`not json`:           geofencepkg.ErrInvalidGeoJSON,
+			`{"type":"Polygon","coordinates":[[[116,80],[117,80],[117,89],[116,80]]]}`: geopkg.ErrInvalidCoordinate,
+		}
+		for data, expected := range invalid {
+			if _, err := geofencepkg.ParseGeoJSON([]byte(data)); !errors.Is(err, expected) {
+				t.Errorf("ParseGeoJSON %s结果不符合预期: %v", data, err)
+			}
+		}
+		shape, err := geofencepkg.ParseGeoJSON([]byte(zoneA))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !shape.Contains(116.15, 39.82) || shape.Contains(116.07, 39.87) || shape.Contains(116.3, 39.9) {
+			t.Error("Contains结果不符合预期")
+		}
+		if b := shape.Bounds(); b.MinLon != 116.0 || b.MaxLat != 40.0 {
+			t.Errorf("Bounds结果不符合预期: %+v", b)
+		}
+
+		//2.添加区域并订阅事件
+		if err = f.AddZone(ctx, "A", []byte(zoneA)); err != nil {
+			t.Fatal(err)
+		}
+		if err = f.AddZone(ctx, "B", []byte(zoneB)); err != nil {
+			t.Fatal(err)
+		}
+		sub, err := f.Subscribe(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+
+		//3.对象移动产生进出事件：进入A，再进入B，最后移动到A的洞中同时离开A和B
+		moves := []struct {
+			lon, lat float64
+			expected []geofencepkg.Event
+		}{
+			{116.15, 39.82, []geofencepkg.Event{{Type: geofencepkg.Enter, Zone: "A"}}},
+			{116.12, 39.97, []geofencepkg.Event{{Type: geofencepkg.Enter, Zone: "B"}}},
+			{116.12, 39.98, nil},
+			{116.07, 39.87, []geofencepkg.Event{{Type: geofencepkg.Exit, Zone: "A"}, {Type: geofencepkg.Exit, Zone: "B"}}},
+		}
+		var published []geofencepkg.Event
+		for i, m := range moves {
+			events, err := f.Update(ctx, "courier1", m.lon, m.lat)
+			if err != nil || len(events) != len(m.expected) {
+				t.Fatalf("第%d次Update结果不符合预期: %+v %v", i, events, err)
+			}
+			for j, e := range events {
+				if e.Object != "courier1" || e.Type != m.expected[j].Type || (len(events) == 1 && e.Zone != m.expected[j].Zone) {
+					t.Errorf("第%d次Update的事件不符合预期: %+v", i, e)
+				}
+			}
+			published = append(published, events...)
+		}
+
+		//4.订阅者按发布顺序收到事件
+		for i, expected := range published {
+			select {
+			case e := <-sub.Events():
+				if e.Type != expected.Type || e.Zone != expected.Zone || e.Object != expected.Object {
+					t.Errorf("第%d个订阅事件不符合预期: %+v", i, e)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatalf("第%d个订阅事件超时", i)
+			}
+		}
+
+		//5.区域内的对象与对象是否在区域内
+		if _, err = f.Update(ctx, "courier2", 116.15, 39.82); err != nil {
+			t.Fatal(err)
+		}
+		objects, err := f.Inside(ctx, "A")
+		if err != nil || len(objects) != 1 || objects[0] != "courier2" {
+			t.Error("Inside结果不符合预期", objects, err)
+		}
+		ok, err := f.Contains(ctx, "A", "courier1")
+		if ok || err != nil {
+			t.Error("洞中的对象Contains结果不符合预期", ok, err)
+		}
+		if _, err = f.Contains(ctx, "A", "nobody"); !errors.Is(err, geopkg.ErrMemberNotFound) {
+			t.Error("不存在的对象Contains结果不符合预期", err)
+		}
+		if _, err = f.Inside(ctx, "none"); !errors.Is(err, geofencepkg.ErrZoneNotFound) {
+			t.Error("不存在的区域Inside结果不符合预期", err)
+		}
+
+		//6.删除对象产生离开事件，删除区域后不再匹配
+		events, err := f.RemoveObject(ctx, "courier2")
+		if err != nil || len(events) != 1 || events[0].Type != geofencepkg.Exit || events[0].Zone != "A" {
+			t.Errorf("RemoveObject结果不符合预期: %+v %v", events, err)
+		}
+		if err = f.RemoveZone(ctx, "B"); err != nil {
+			t.Error(err)
+		}
+		zones, err := f.ZonesAt(ctx, 116.12, 39.97)
+		if err != nil || len(zones) != 1 || zones[0] != "A" {
+			t.Error("RemoveZone后ZonesAt结果不符合预期", zones, err)
+		}
+	})
+}
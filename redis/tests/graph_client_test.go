@@ -0,0 +1,98 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-25 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	graphpkg "go-redis-demo/redis/graph"
+)
+
+func Test_graphClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 社交图谱测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "graph_key"
+		defer cleanupKeysWithPrefix(t, ctx, name)
+		g := redis.Client.Graph.Graph(name)
+
+		//1.不能关注自己
+		if _, err := g.Follow(ctx, "alice", "alice"); !errors.Is(err, graphpkg.ErrSelfFollow) {
+			t.Error("关注自己结果不符合预期", err)
+		}
+
+		//2.建立关注关系：alice与bob、carol互相关注，alice单向关注dave
+		follows := [][2]string{
+			{"alice", "bob"}, {"bob", "alice"}, {"alice", "carol"}, {"carol", "alice"}, {"alice", "dave"},
+			{"bob", "erin"}, {"carol", "erin"}, {"dave", "frank"}, {"bob", "dave"},
+		}
+		for _, f := range follows {
+			ok, err := g.Follow(ctx, f[0], f[1])
+			if !ok || err != nil {
+				t.Fatal("Follow结果不符合预期", f, err)
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+		ok, err := g.Follow(ctx, "alice", "bob")
+		if ok || err != nil {
+			t.Error("重复Follow结果不符合预期", ok, err)
+		}
+
+		//3.关注数、粉丝数与关注列表按时间从新到旧排列
+		counts, err := g.Counts(ctx, "alice")
+		if err != nil || counts.Following != 3 || counts.Followers != 2 {
+			t.Errorf("Counts结果不符合预期: %+v %v", counts, err)
+		}
+		following, err := g.Following(ctx, "alice", 0, 2)
+		if err != nil || len(following) != 2 || following[0].User != "dave" || following[1].User != "carol" {
+			t.Errorf("Following结果不符合预期: %+v %v", following, err)
+		}
+		followers, err := g.Followers(ctx, "dave", 0, 10)
+		if err != nil || len(followers) != 2 || followers[0].User != "bob" || !followers[1].Since.Before(followers[0].Since) {
+			t.Errorf("Followers结果不符合预期: %+v %v", followers, err)
+		}
+
+		//4.互相关注的好友与共同关注
+		friends, err := g.Friends(ctx, "alice")
+		sort.Strings(friends)
+		if err != nil || len(friends) != 2 || friends[0] != "bob" || friends[1] != "carol" {
+			t.Error("Friends结果不符合预期", friends, err)
+		}
+		mutual, err := g.Mutual(ctx, "alice", "bob")
+		if err != nil || len(mutual) != 1 || mutual[0] != "dave" {
+			t.Error("Mutual结果不符合预期", mutual, err)
+		}
+
+		//5.推荐：erin被两个好友关注得4分，frank被单向关注的dave关注得1分，已关注的dave被排除
+		suggestions, err := g.Suggest(ctx, "alice", 100, 10)
+		if err != nil || len(suggestions) != 2 ||
+			suggestions[0] != (graphpkg.Suggestion{User: "erin", Score: 4}) ||
+			suggestions[1] != (graphpkg.Suggestion{User: "frank", Score: 1}) {
+			t.Errorf("Suggest结果不符合预期: %+v %v", suggestions, err)
+		}
+
+		//6.取消关注
+		ok, err = g.Unfollow(ctx, "alice", "dave")
+		if !ok || err != nil {
+			t.Error("Unfollow结果不符合预期", ok, err)
+		}
+		ok, err = g.IsFollowing(ctx, "alice", "dave")
+		if ok || err != nil {
+			t.Error("Unfollow后IsFollowing结果不符合预期", ok, err)
+		}
+		followers, err = g.Followers(ctx, "dave", 0, 10)
+		if err != nil || len(followers) != 1 {
+			t.Errorf("Unfollow后Followers结果不符合预期: %+v %v", followers, err)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-18 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	leaderboardpkg "go-redis-demo/redis/leaderboard"
+)
+
+func Test_leaderboardClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 排行榜测试", func(t *testing.T) {
+		l := redis.Client.Leaderboard
+		ctx := context.Background()
+
+		//1.保留最高分，同分时先提交者在前
+		board := l.Board("leaderboard_key", nil)
+		defer redis.Client.String.Del(ctx, board.Key())
+		submits := []struct {
+			member string
+			score  float64
+		}{{"a", 100}, {"b", 90}, {"c", 100}, {"d", 80}, {"b", 70}, {"e", 90}}
+		for _, s := range submits {
+			if _, err := board.Submit(ctx, s.member, s.score); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(1100 * time.Millisecond) // 同分按秒级时间排序
+		}
+
+		//2.较低分数不覆盖最高分
+		score, err := board.Score(ctx, "b")
+		if score != 90 || err != nil {
+			t.Error("Score结果不符合预期")
+		}
+
+		//3.非整数分数
+		_, err = board.Submit(ctx, "f", 1.5)
+		if !errors.Is(err, leaderboardpkg.ErrNonIntegerScore) {
+			t.Error("非整数分数Submit结果不符合预期", err)
+		}
+
+		//4.三种排名方式：顺序 a c b e d，标准 1 1 3 3 5，密集 1 1 2 2 3
+		expected := map[string][3]int64{
+			"a": {1, 1, 1},
+			"c": {2, 1, 1},
+			"b": {3, 3, 2},
+			"e": {4, 3, 2},
+			"d": {5, 5, 3},
+		}
+		for member, ranks := range expected {
+			for i, mode := range []leaderboardpkg.RankMode{leaderboardpkg.Ordinal, leaderboardpkg.Standard, leaderboardpkg.Dense} {
+				rank, err := board.Rank(ctx, member, mode)
+				if rank != ranks[i] || err != nil {
+					t.Errorf("%s 排名方式%d 结果不符合预期: %d", member, mode, rank)
+				}
+			}
+		}
+
+		//5.分页查询
+		entries, err := board.Top(ctx, 2, 2)
+		if len(entries) != 2 || err != nil {
+			t.Fatal("Top结果不符合预期")
+		}
+		if entries[0].Member != "b" || entries[0].Score != 90 || entries[0].Rank != 3 || entries[1].Member != "e" {
+			t.Errorf("Top结果不符合预期: %+v", entries)
+		}
+
+		//6.查询附近排名
+		entries, err = board.Around(ctx, "b", 1)
+		if len(entries) != 3 || err != nil {
+			t.Fatal("Around结果不符合预期")
+		}
+		if entries[0].Member != "c" || entries[1].Member != "b" || entries[2].Member != "e" {
+			t.Errorf("Around结果不符合预期: %+v", entries)
+		}
+
+		//7.不存在的成员
+		_, err = board.Rank(ctx, "nonexistent", leaderboardpkg.Ordinal)
+		if !errors.Is(err, leaderboardpkg.ErrMemberNotFound) {
+			t.Error("不存在成员的Rank结果不符合预期", err)
+		}
+	})
+
+	//3.运行测试
+	t.Run("redis 周期排行榜测试", func(t *testing.T) {
+		l := redis.Client.Leaderboard
+		ctx := context.Background()
+
+		//1.累加日榜，保留1小时
+		opts := leaderboardpkg.DefaultOptions()
+		opts.Mode = leaderboardpkg.Accumulate
+		opts.Period = leaderboardpkg.Daily
+		opts.Retention = time.Hour
+		daily := l.Board("leaderboard_daily", opts)
+		today := daily.Key()
+		yesterday := daily.Previous().Key()
+		defer redis.Client.String.Del(ctx, today, "leaderboard_merged")
+
+		if today != "leaderboard_daily:"+time.Now().Format("20060102") {
+			t.Error("日榜Key结果不符合预期", today)
+		}
+
+		//2.累加分数
+		for i := 0; i < 3; i++ {
+			if _, err := daily.Submit(ctx, "a", 10); err != nil {
+				t.Fatal(err)
+			}
+		}
+		score, err := daily.Score(ctx, "a")
+		if score != 30 || err != nil {
+			t.Error("累加Score结果不符合预期")
+		}
+
+		//3.周期榜设置了过期时间
+		ttl, err := redis.Client.String.TTL(ctx, today)
+		if ttl <= time.Hour || err != nil {
+			t.Error("日榜TTL结果不符合预期", ttl)
+		}
+
+		//4.上一周期的榜单与当前周期相互独立
+		count, err := daily.Previous().Count(ctx)
+		if count != 0 || err != nil || yesterday == today {
+			t.Error("上一周期日榜结果不符合预期")
+		}
+
+		//5.合并当前周期与上一周期
+		n, err := daily.Merge(ctx, "leaderboard_merged", time.Minute, daily, daily.Previous())
+		if n != 1 || err != nil {
+			t.Error("Merge结果不符合预期")
+		}
+		merged, err := redis.Client.ZSet.ZRevRangeWithScores(ctx, "leaderboard_merged", 0, -1)
+		if len(merged) != 1 || err != nil || int64(merged[0].Score) != 30 {
+			t.Errorf("合并结果不符合预期: %+v", merged)
+		}
+
+		//6.没有来源或保留最近分数的排行榜无法合并
+		if _, err = daily.Merge(ctx, "leaderboard_merged", time.Minute); !errors.Is(err, leaderboardpkg.ErrNoSource) {
+			t.Error("无来源Merge结果不符合预期", err)
+		}
+		latestOpts := leaderboardpkg.DefaultOptions()
+		latestOpts.Mode = leaderboardpkg.KeepLatest
+		latest := l.Board("leaderboard_latest", latestOpts)
+		if _, err = latest.Merge(ctx, "leaderboard_merged", time.Minute, latest); !errors.Is(err, leaderboardpkg.ErrMergeUnsupported) {
+			t.Error("保留最近分数Merge结果不符合预期", err)
+		}
+	})
+}
@@ -3,9 +3,11 @@ package redis_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	redisv9 "github.com/redis/go-redis/v9"
 	"go-redis-demo/redis"
 	listpkg "go-redis-demo/redis/list"
 )
@@ -131,13 +133,13 @@ func Test_listClient(t *testing.T) {
 		}
 
 		//21.在目标元素前插入元素
-		count, err = l.LInsert(ctx, "list_key", "BEFORE", "value2", "value2.5")
+		count, err = l.LInsert(ctx, "list_key", listpkg.Before, "value2", "value2.5")
 		if count != 4 || err != nil {
 			t.Error("LInsert BEFORE结果不符合预期")
 		}
 
 		//22.在目标元素后插入元素
-		count, err = l.LInsert(ctx, "list_key", "AFTER", "value2", "value1.5")
+		count, err = l.LInsert(ctx, "list_key", listpkg.After, "value2", "value1.5")
 		if count != 5 || err != nil {
 			t.Error("LInsert AFTER结果不符合预期")
 		}
@@ -146,7 +148,7 @@ func Test_listClient(t *testing.T) {
 		testListElements(t, l, ctx, "list_key", []string{"value3", "value2.5", "value2", "value1.5", "value1"})
 
 		//24.在不存在的目标元素前插入元素
-		count, err = l.LInsert(ctx, "list_key", "BEFORE", "nonexistent", "value")
+		count, err = l.LInsert(ctx, "list_key", listpkg.Before, "nonexistent", "value")
 		if count != -1 || err != nil {
 			t.Error("LInsert不存在元素结果不符合预期")
 		}
@@ -269,10 +271,88 @@ func Test_listClient(t *testing.T) {
 			t.Error("BRPop空list应该超时")
 		}
 
-		//44.清理测试数据
-		cleanupLists(t, l, ctx, []string{"list_key", "list_key2", "source_list", "dest_list", "empty_source", "brpop_test"})
+		//44.LMove/BLMove 指定方向移动元素
+		_, err = l.RPush(ctx, "move_src", "m1", "m2", "m3")
+		if err != nil {
+			t.Error(err)
+		}
+		value, err = l.LMove(ctx, "move_src", "move_dest", listpkg.Left, listpkg.Right)
+		if value != "m1" || err != nil {
+			t.Error("LMove结果不符合预期")
+		}
+		value, err = l.BLMove(ctx, "move_src", "move_dest", listpkg.Right, listpkg.Left, timeout)
+		if value != "m3" || err != nil {
+			t.Error("BLMove结果不符合预期")
+		}
+		testListElements(t, l, ctx, "move_dest", []string{"m3", "m1"})
+		_, err = l.BLMove(ctx, "nonexistent_key", "move_dest", listpkg.Left, listpkg.Left, timeout)
+		if !errors.Is(err, redisv9.Nil) {
+			t.Error("BLMove空list应该超时")
+		}
+
+		//45.LPopCount/RPopCount 一次弹出多个元素
+		_, err = l.RPush(ctx, "count_list", "c1", "c2", "c3", "c4", "c5")
+		if err != nil {
+			t.Error(err)
+		}
+		values, err = l.LPopCount(ctx, "count_list", 2)
+		if len(values) != 2 || values[0] != "c1" || values[1] != "c2" || err != nil {
+			t.Error("LPopCount结果不符合预期")
+		}
+		values, err = l.RPopCount(ctx, "count_list", 10)
+		if len(values) != 3 || values[0] != "c5" || values[2] != "c3" || err != nil {
+			t.Error("RPopCount结果不符合预期")
+		}
+
+		//46.LMPop/BLMPop 从第一个非空列表弹出
+		_, err = l.RPush(ctx, "mpop_list", "p1", "p2", "p3")
+		if err != nil {
+			t.Error(err)
+		}
+		key, values, err := l.LMPop(ctx, listpkg.Left, 2, "nonexistent_key", "mpop_list")
+		if key != "mpop_list" || len(values) != 2 || values[0] != "p1" || err != nil {
+			t.Error("LMPop结果不符合预期")
+		}
+		key, values, err = l.BLMPop(ctx, timeout, listpkg.Right, 5, "mpop_list")
+		if key != "mpop_list" || len(values) != 1 || values[0] != "p3" || err != nil {
+			t.Error("BLMPop结果不符合预期")
+		}
+		_, _, err = l.LMPop(ctx, listpkg.Left, 1, "nonexistent_key")
+		if !errors.Is(err, redisv9.Nil) {
+			t.Error("LMPop空list应该返回redis.Nil")
+		}
+
+		//47.LPos/LPosCount 查找元素下标
+		_, err = l.RPush(ctx, "pos_list", "a", "b", "a", "c", "a")
+		if err != nil {
+			t.Error(err)
+		}
+		pos, err := l.LPos(ctx, "pos_list", "a", redisv9.LPosArgs{})
+		if pos != 0 || err != nil {
+			t.Error("LPos结果不符合预期")
+		}
+		pos, err = l.LPos(ctx, "pos_list", "a", redisv9.LPosArgs{Rank: -1})
+		if pos != 4 || err != nil {
+			t.Error("LPos倒数第一个匹配结果不符合预期")
+		}
+		positions, err := l.LPosCount(ctx, "pos_list", "a", 0, redisv9.LPosArgs{})
+		if len(positions) != 3 || positions[1] != 2 || err != nil {
+			t.Error("LPosCount结果不符合预期")
+		}
+		positions, err = l.LPosCount(ctx, "pos_list", "a", 0, redisv9.LPosArgs{MaxLen: 3})
+		if len(positions) != 2 || err != nil {
+			t.Error("LPosCount限制比较长度结果不符合预期")
+		}
+		_, err = l.LPos(ctx, "pos_list", "z", redisv9.LPosArgs{})
+		if !errors.Is(err, redisv9.Nil) {
+			t.Error("LPos不存在的元素应该返回redis.Nil")
+		}
+
+		//48.清理测试数据
+		cleanupLists(t, l, ctx, []string{"list_key", "list_key2", "source_list", "dest_list", "empty_source", "brpop_test",
+			"move_src", "move_dest", "count_list", "mpop_list", "pos_list"})
 
-		//45.测试空list的各种操作
+		//49.测试空list的各种操作
 		testEmptyListOperations(t, l, ctx, "empty_list")
 	})
 }
@@ -0,0 +1,291 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-12 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	lockpkg "go-redis-demo/redis/lock"
+)
+
+func Test_lockClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 单实例锁测试", func(t *testing.T) {
+		testLock(t, redis.Client.Lock, "lock_key")
+	})
+
+	//3.运行测试
+	t.Run("redis 可重入锁测试", func(t *testing.T) {
+		testReentrantLock(t, redis.Client.Lock, "reentrant_lock_key")
+	})
+
+	//4.运行测试
+	t.Run("redis 读写锁测试", func(t *testing.T) {
+		testRWLock(t, redis.Client.Lock, "rw_lock_key")
+	})
+
+	//5.使用同一Redis的不同DB模拟相互独立的实例
+	configs := make([]*redis.Config, 3)
+	for i := range configs {
+		configs[i] = redis.DefaultConfig()
+		configs[i].DB = i
+	}
+	rl, err := redis.NewRedlock(configs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	//6.运行测试
+	t.Run("redis Redlock测试", func(t *testing.T) {
+		testLock(t, rl, "redlock_key")
+
+		//1.验证多数派数量
+		if rl.Quorum() != 2 {
+			t.Error("Quorum结果不符合预期")
+		}
+		if _, err := redis.NewRedlock(); !errors.Is(err, redis.ErrNoInstances) {
+			t.Error("未传入配置时NewRedlock结果不符合预期", err)
+		}
+
+		//2.获取锁后剩余有效期应大于0且不超过TTL
+		opts := lockpkg.DefaultOptions()
+		m := rl.NewMutex("redlock_validity_key", opts)
+		ctx := context.Background()
+		if err := m.Lock(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if v := m.Validity(); v <= 0 || v > opts.TTL {
+			t.Errorf("Validity结果不符合预期: %v", v)
+		}
+
+		//3.释放后剩余有效期应为0
+		if err := m.Unlock(ctx); err != nil {
+			t.Error(err)
+		}
+		if m.Validity() != 0 {
+			t.Error("释放后Validity结果不符合预期")
+		}
+
+		//4.TTL不大于时钟漂移补偿时无法加锁
+		short := lockpkg.DefaultOptions()
+		short.TTL = time.Millisecond
+		if _, err := rl.NewMutex("redlock_short_key", short).TryLock(ctx); !errors.Is(err, lockpkg.ErrInvalidTTL) {
+			t.Error("TTL过小时TryLock结果不符合预期", err)
+		}
+	})
+}
+
+// 测试锁的通用行为，单实例锁与Redlock共用
+func testLock(t *testing.T, f lockpkg.Factory, key string) {
+	ctx := context.Background()
+	opts := lockpkg.DefaultOptions()
+	opts.TTL = time.Second
+	opts.RetryCount = 2
+	opts.RetryDelay = 10 * time.Millisecond
+
+	//1.第一个持有者获取锁
+	l1 := f.NewLock(key, opts)
+	ok, err := l1.TryLock(ctx)
+	if !ok || err != nil {
+		t.Fatal("TryLock结果不符合预期", err)
+	}
+
+	//2.第二个持有者无法获取锁
+	l2 := f.NewLock(key, opts)
+	ok, err = l2.TryLock(ctx)
+	if ok || err != nil {
+		t.Error("重复TryLock结果不符合预期")
+	}
+	if err = l2.Lock(ctx); !errors.Is(err, lockpkg.ErrNotAcquired) {
+		t.Error("重复Lock结果不符合预期", err)
+	}
+
+	//3.非持有者无法释放或续期
+	if err = l2.Unlock(ctx); !errors.Is(err, lockpkg.ErrLockNotHeld) {
+		t.Error("非持有者Unlock结果不符合预期", err)
+	}
+	if err = l2.Refresh(ctx); !errors.Is(err, lockpkg.ErrLockNotHeld) {
+		t.Error("非持有者Refresh结果不符合预期", err)
+	}
+
+	//4.持有者续期
+	if err = l1.Refresh(ctx); err != nil {
+		t.Error(err)
+	}
+
+	//5.持有者释放锁
+	if err = l1.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+
+	//6.释放后其他持有者可以获取锁
+	if err = l2.Lock(ctx); err != nil {
+		t.Error(err)
+	}
+
+	//7.锁过期后自动释放
+	time.Sleep(opts.TTL + 100*time.Millisecond)
+	if err = l2.Unlock(ctx); !errors.Is(err, lockpkg.ErrLockNotHeld) {
+		t.Error("过期后Unlock结果不符合预期", err)
+	}
+}
+
+// 测试可重入锁
+func testReentrantLock(t *testing.T, c *lockpkg.Client, key string) {
+	ctx := context.Background()
+	opts := lockpkg.DefaultOptions()
+	opts.RetryCount = 2
+	opts.RetryDelay = 10 * time.Millisecond
+
+	//1.同一持有者重复获取
+	l1 := c.NewReentrantLock(key, "owner1", opts)
+	for i := 0; i < 3; i++ {
+		if err := l1.Lock(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count, err := l1.HoldCount(ctx)
+	if count != 3 || err != nil {
+		t.Error("HoldCount结果不符合预期")
+	}
+
+	//2.其他持有者无法获取
+	l2 := c.NewReentrantLock(key, "owner2", opts)
+	if err = l2.Lock(ctx); !errors.Is(err, lockpkg.ErrNotAcquired) {
+		t.Error("其他持有者Lock结果不符合预期", err)
+	}
+
+	//3.释放两次后仍持有
+	for i := 0; i < 2; i++ {
+		if err = l1.Unlock(ctx); err != nil {
+			t.Error(err)
+		}
+	}
+	ok, err := l2.TryLock(ctx)
+	if ok || err != nil {
+		t.Error("部分释放后TryLock结果不符合预期")
+	}
+
+	//4.释放最后一次后锁被删除
+	if err = l1.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+	count, err = l1.HoldCount(ctx)
+	if count != 0 || err != nil {
+		t.Error("完全释放后HoldCount结果不符合预期")
+	}
+	if err = l1.Unlock(ctx); !errors.Is(err, lockpkg.ErrLockNotHeld) {
+		t.Error("重复Unlock结果不符合预期", err)
+	}
+
+	//5.其他持有者可以获取
+	ok, err = l2.TryLock(ctx)
+	if !ok || err != nil {
+		t.Error("释放后TryLock结果不符合预期")
+	}
+	if err = l2.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+
+	//6.持有者标识为"mode"时不影响锁的模式
+	l3 := c.NewReentrantLock(key, "mode", opts)
+	for i := 0; i < 2; i++ {
+		if err = l3.Lock(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count, err = l3.HoldCount(ctx)
+	if count != 2 || err != nil {
+		t.Error("持有者为mode时HoldCount结果不符合预期")
+	}
+	for i := 0; i < 2; i++ {
+		if err = l3.Unlock(ctx); err != nil {
+			t.Error(err)
+		}
+	}
+	ok, err = l2.TryLock(ctx)
+	if !ok || err != nil {
+		t.Error("持有者为mode释放后TryLock结果不符合预期")
+	}
+	if err = l2.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+// 测试读写锁
+func testRWLock(t *testing.T, c *lockpkg.Client, key string) {
+	ctx := context.Background()
+	opts := lockpkg.DefaultOptions()
+	opts.RetryCount = 2
+	opts.RetryDelay = 10 * time.Millisecond
+
+	//1.多个读者同时持有读锁
+	r1 := c.NewRWLock(key, "reader1", opts).ReadLock()
+	r2 := c.NewRWLock(key, "reader2", opts).ReadLock()
+	if err := r1.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := r2.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	//2.有读者时写者无法获取
+	w := c.NewRWLock(key, "writer", opts).WriteLock()
+	ok, err := w.TryLock(ctx)
+	if ok || err != nil {
+		t.Error("有读者时写锁TryLock结果不符合预期")
+	}
+
+	//3.写者在等待时，新读者不能插队
+	done := make(chan error, 1)
+	go func() {
+		waitOpts := *opts
+		waitOpts.RetryCount = 100
+		done <- c.NewRWLock(key, "writer", &waitOpts).WriteLock().Lock(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	r3 := c.NewRWLock(key, "reader3", opts).ReadLock()
+	ok, err = r3.TryLock(ctx)
+	if ok || err != nil {
+		t.Error("写者等待时读锁TryLock结果不符合预期")
+	}
+
+	//4.读者全部释放后写者获取到锁
+	if err = r1.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+	if err = r2.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	//5.写者持有时读者无法获取
+	ok, err = r3.TryLock(ctx)
+	if ok || err != nil {
+		t.Error("写者持有时读锁TryLock结果不符合预期")
+	}
+
+	//6.写者释放后读者可以获取
+	if err = w.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+	ok, err = r3.TryLock(ctx)
+	if !ok || err != nil {
+		t.Error("写者释放后读锁TryLock结果不符合预期")
+	}
+	if err = r3.Unlock(ctx); err != nil {
+		t.Error(err)
+	}
+}
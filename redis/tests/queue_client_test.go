@@ -0,0 +1,137 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-20 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	queuepkg "go-redis-demo/redis/queue"
+)
+
+func Test_queueClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 工作队列测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "queue_key"
+		defer cleanupKeysWithPrefix(t, ctx, name)
+
+		opts := queuepkg.DefaultOptions()
+		opts.Priorities = 2
+		opts.MaxRetries = 1
+		opts.Visibility = 200 * time.Millisecond
+		q := redis.Client.Queue.Queue(name, opts)
+
+		//1.非法优先级
+		_, err := q.Produce(ctx, "msg", 2)
+		if !errors.Is(err, queuepkg.ErrInvalidPriority) {
+			t.Error("非法优先级Produce结果不符合预期", err)
+		}
+
+		//2.先生产低优先级消息，再生产高优先级消息
+		if _, err = q.Produce(ctx, "low", 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = q.Produce(ctx, "high", 0); err != nil {
+			t.Fatal(err)
+		}
+
+		//3.高优先级消息先被取出
+		c1 := q.Consumer("consumer1")
+		m, err := c1.Reserve(ctx, time.Second)
+		if m == nil || err != nil || m.Body != "high" || m.Priority != 0 {
+			t.Fatal("Reserve结果不符合预期", m, err)
+		}
+		if err = c1.Ack(ctx, m); err != nil {
+			t.Error(err)
+		}
+		if err = c1.Ack(ctx, m); !errors.Is(err, queuepkg.ErrMessageNotHeld) {
+			t.Error("重复Ack结果不符合预期", err)
+		}
+
+		//4.处理失败后重新入队，失败次数加一
+		m, err = c1.Reserve(ctx, time.Second)
+		if m == nil || err != nil || m.Body != "low" {
+			t.Fatal("Reserve结果不符合预期", m, err)
+		}
+		if err = c1.Nack(ctx, m); err != nil {
+			t.Error(err)
+		}
+
+		//5.消费者死亡后消息被回收，超过最大重试次数移入死信列表
+		m, err = c1.Reserve(ctx, time.Second)
+		if m == nil || err != nil || m.Attempts != 1 {
+			t.Fatal("重试Reserve结果不符合预期", m, err)
+		}
+		stats, err := q.Stats(ctx)
+		if err != nil || stats.Processing != 1 || stats.Consumers != 1 {
+			t.Errorf("Stats结果不符合预期: %+v", stats)
+		}
+		time.Sleep(300 * time.Millisecond)
+		n, err := q.Recover(ctx)
+		if n != 1 || err != nil {
+			t.Error("Recover结果不符合预期")
+		}
+		stats, err = q.Stats(ctx)
+		if err != nil || stats.Dead != 1 || stats.Processing != 0 || stats.Consumers != 0 {
+			t.Errorf("回收后Stats结果不符合预期: %+v", stats)
+		}
+		dead, err := q.DeadLetters(ctx, 0, -1)
+		if len(dead) != 1 || err != nil || dead[0].Body != "low" || dead[0].Attempts != 2 {
+			t.Error("DeadLetters结果不符合预期")
+		}
+
+		//6.死信重新入队
+		n, err = q.RequeueDead(ctx)
+		if n != 1 || err != nil {
+			t.Error("RequeueDead结果不符合预期")
+		}
+
+		//7.阻塞等待新消息
+		c2 := q.Consumer("consumer2")
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_, _ = q.Produce(ctx, "later", 0)
+		}()
+		for _, expected := range []string{"low", "later"} {
+			m, err = c2.Reserve(ctx, time.Second)
+			if m == nil || err != nil || m.Body != expected {
+				t.Fatal("阻塞Reserve结果不符合预期", m, err)
+			}
+			if err = c2.Ack(ctx, m); err != nil {
+				t.Error(err)
+			}
+		}
+
+		//8.注销消费者
+		if err = c2.Close(ctx); err != nil {
+			t.Error(err)
+		}
+		stats, err = q.Stats(ctx)
+		if err != nil || stats.Pending[0] != 0 || stats.Pending[1] != 0 || stats.Consumers != 0 {
+			t.Errorf("注销后Stats结果不符合预期: %+v", stats)
+		}
+	})
+}
+
+// 清理以name:为前缀的测试数据，供各组件的测试共用
+func cleanupKeysWithPrefix(t *testing.T, ctx context.Context, name string) {
+	keys, err := redis.Client.String.Keys(ctx, name+":*")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(keys) > 0 {
+		if _, err = redis.Client.String.Del(ctx, keys...); err != nil {
+			t.Error("清理测试数据失败")
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-15 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	ratelimitpkg "go-redis-demo/redis/ratelimit"
+)
+
+func Test_rateLimitClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试，每种算法都应在配额内放行、超出配额后拒绝
+	algorithms := map[string]ratelimitpkg.Algorithm{
+		"固定窗口":   ratelimitpkg.FixedWindow,
+		"滑动日志":   ratelimitpkg.SlidingLog,
+		"滑动窗口计数": ratelimitpkg.SlidingWindow,
+		"令牌桶":    ratelimitpkg.TokenBucket,
+		"GCRA":   ratelimitpkg.GCRA,
+	}
+	for name, alg := range algorithms {
+		t.Run("redis 限流测试-"+name, func(t *testing.T) {
+			testRateLimit(t, redis.Client.RateLimit, "ratelimit_key:"+name, alg)
+		})
+	}
+
+	//3.运行测试
+	t.Run("redis 限流中间件测试", func(t *testing.T) {
+		testRateLimitMiddleware(t, redis.Client.RateLimit)
+	})
+}
+
+// 测试限流算法的通用行为
+func testRateLimit(t *testing.T, r *ratelimitpkg.Client, key string, alg ratelimitpkg.Algorithm) {
+	ctx := context.Background()
+	limit := ratelimitpkg.Limit{Rate: 5, Period: time.Second}
+	defer r.Reset(ctx, key)
+
+	//1.配额内的请求全部放行，剩余配额递减
+	for i := int64(0); i < limit.Rate; i++ {
+		res, err := r.Allow(ctx, key, alg, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != limit.Rate-i-1 {
+			t.Errorf("第%d次请求结果不符合预期: %+v", i+1, res)
+		}
+	}
+
+	//2.超出配额后拒绝，并给出重试等待时间（滑动窗口计数可能需要等到下一窗口）
+	res, err := r.Allow(ctx, key, alg, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter <= 0 || res.RetryAfter > 2*limit.Period {
+		t.Errorf("超出配额后结果不符合预期: %+v", res)
+	}
+
+	//3.等待重试时间后再次放行
+	time.Sleep(res.RetryAfter + 50*time.Millisecond)
+	res, err = r.Allow(ctx, key, alg, limit)
+	if err != nil || !res.Allowed {
+		t.Errorf("等待后结果不符合预期: %+v", res)
+	}
+
+	//4.非法限流规则
+	_, err = r.Allow(ctx, key, alg, ratelimitpkg.Limit{})
+	if !errors.Is(err, ratelimitpkg.ErrInvalidLimit) {
+		t.Error("非法限流规则结果不符合预期", err)
+	}
+	_, err = r.Allow(ctx, key, alg, ratelimitpkg.Limit{Rate: 5, Period: time.Microsecond})
+	if !errors.Is(err, ratelimitpkg.ErrInvalidLimit) {
+		t.Error("周期小于1毫秒的限流规则结果不符合预期", err)
+	}
+
+	//5.非法请求次数
+	_, err = r.AllowN(ctx, key, alg, limit, 0)
+	if !errors.Is(err, ratelimitpkg.ErrInvalidCount) {
+		t.Error("非法请求次数结果不符合预期", err)
+	}
+}
+
+// 测试限流中间件
+func testRateLimitMiddleware(t *testing.T, r *ratelimitpkg.Client) {
+	key := "ratelimit_middleware_key"
+	defer r.Reset(context.Background(), key)
+
+	//1.创建限流处理器，每分钟最多2次请求
+	handler := r.Middleware(ratelimitpkg.GCRA, ratelimitpkg.PerMinute(2), func(*http.Request) string {
+		return key
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	//2.配额内的请求正常响应并携带限流响应头
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("第%d次请求结果不符合预期: %d %v", i+1, rec.Code, rec.Header())
+		}
+	}
+
+	//3.超出配额返回429并携带Retry-After
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("超出配额结果不符合预期: %d %v", rec.Code, rec.Header())
+	}
+}
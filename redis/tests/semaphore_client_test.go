@@ -0,0 +1,119 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-14 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	semaphorepkg "go-redis-demo/redis/semaphore"
+)
+
+func Test_semaphoreClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 信号量测试", func(t *testing.T) {
+		s := redis.Client.Semaphore
+		ctx := context.Background()
+		key := "semaphore_key"
+
+		//1.许可数量不合法
+		_, err := s.TryAcquire(ctx, key, 0, time.Second)
+		if !errors.Is(err, semaphorepkg.ErrInvalidLimit) {
+			t.Error("非法许可数量TryAcquire结果不符合预期", err)
+		}
+
+		//2.获取全部许可
+		p1, err := s.TryAcquire(ctx, key, 2, 10*time.Second)
+		if p1 == nil || err != nil {
+			t.Fatal("TryAcquire结果不符合预期", err)
+		}
+		p2, err := s.TryAcquire(ctx, key, 2, 10*time.Second)
+		if p2 == nil || err != nil {
+			t.Fatal("TryAcquire结果不符合预期", err)
+		}
+
+		//3.许可不足时获取失败
+		p3, err := s.TryAcquire(ctx, key, 2, 10*time.Second)
+		if p3 != nil || err != nil {
+			t.Error("许可不足时TryAcquire结果不符合预期")
+		}
+		count, err := s.Count(ctx, key)
+		if count != 2 || err != nil {
+			t.Error("Count结果不符合预期")
+		}
+
+		//4.排名与续期，两个许可的过期时间可能相同，只校验排名在范围内且互不相同
+		rank1, err := p1.Rank(ctx)
+		if (rank1 != 0 && rank1 != 1) || err != nil {
+			t.Error("Rank结果不符合预期")
+		}
+		rank2, err := p2.Rank(ctx)
+		if (rank2 != 0 && rank2 != 1) || rank2 == rank1 || err != nil {
+			t.Error("Rank结果不符合预期")
+		}
+		if err = p1.Refresh(ctx); err != nil {
+			t.Error(err)
+		}
+
+		//5.阻塞获取，释放许可后立即被唤醒
+		done := make(chan *semaphorepkg.Permit, 1)
+		go func() {
+			p, err := s.Acquire(ctx, key, 2, 10*time.Second)
+			if err != nil {
+				t.Error(err)
+			}
+			done <- p
+		}()
+		time.Sleep(100 * time.Millisecond)
+		start := time.Now()
+		if err = p1.Release(ctx); err != nil {
+			t.Error(err)
+		}
+		p3 = <-done
+		if p3 == nil || time.Since(start) > time.Second {
+			t.Error("Acquire未被释放通知唤醒")
+		}
+
+		//6.重复释放
+		if err = p1.Release(ctx); !errors.Is(err, semaphorepkg.ErrPermitNotHeld) {
+			t.Error("重复Release结果不符合预期", err)
+		}
+
+		//7.上下文超时后停止等待
+		timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		_, err = s.Acquire(timeoutCtx, key, 2, 10*time.Second)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Error("超时Acquire结果不符合预期", err)
+		}
+
+		//8.崩溃的持有者在TTL后自动回收
+		if err = p2.Release(ctx); err != nil {
+			t.Error(err)
+		}
+		if err = p3.Release(ctx); err != nil {
+			t.Error(err)
+		}
+		_, err = s.TryAcquire(ctx, key, 1, 200*time.Millisecond)
+		if err != nil {
+			t.Error(err)
+		}
+		p4, err := s.Acquire(ctx, key, 1, time.Second)
+		if p4 == nil || err != nil {
+			t.Error("过期回收后Acquire结果不符合预期", err)
+		}
+
+		//9.清理测试数据
+		if err = p4.Release(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -3,9 +3,12 @@ package redis_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"sort"
 	"testing"
 
+	redisv9 "github.com/redis/go-redis/v9"
 	"go-redis-demo/redis"
 	setpkg "go-redis-demo/redis/set"
 )
@@ -66,24 +69,57 @@ func Test_setClient(t *testing.T) {
 		testSetContainsAll(t, members, []string{"value1", "value2", "value3", "value4", "value5"})
 
 		//8.随机获取一个元素
-		randMembers, err := s.SRandMember(ctx, "set_key")
-		if len(randMembers) != 1 || err != nil {
+		randMember, err := s.SRandMember(ctx, "set_key")
+		if err != nil {
 			t.Error("SRandMember结果不符合预期")
 		}
-		testSetContains(t, []string{"value1", "value2", "value3", "value4", "value5"}, randMembers[0])
+		testSetContains(t, []string{"value1", "value2", "value3", "value4", "value5"}, randMember)
 
 		//9.随机获取多个元素
-		randMembers, err = s.SRandMember(ctx, "set_key", 3)
+		randMembers, err := s.SRandMemberN(ctx, "set_key", 3)
 		if len(randMembers) != 3 || err != nil {
-			t.Error("SRandMember多个元素结果不符合预期")
+			t.Error("SRandMemberN多个元素结果不符合预期")
 		}
 		for _, member := range randMembers {
 			testSetContains(t, []string{"value1", "value2", "value3", "value4", "value5"}, member)
 		}
 
+		//9.1.count大于元素数时返回所有元素，count为负数时允许重复
+		randMembers, err = s.SRandMemberN(ctx, "set_key", 10)
+		if len(randMembers) != 5 || err != nil {
+			t.Error("SRandMemberN count大于元素数结果不符合预期")
+		}
+		randMembers, err = s.SRandMemberN(ctx, "set_key", -10)
+		if len(randMembers) != 10 || err != nil {
+			t.Error("SRandMemberN 负数count结果不符合预期")
+		}
+
+		//9.2.批量判断元素是否存在
+		flags, err := s.SMIsMember(ctx, "set_key", "value1", "nonexistent", "value5")
+		if len(flags) != 3 || !flags[0] || flags[1] || !flags[2] || err != nil {
+			t.Error("SMIsMember结果不符合预期")
+		}
+
+		//9.3.增量遍历
+		scanned, cursor, err := s.SScan(ctx, "set_key", 0, "value*", 100)
+		if len(scanned) != 5 || cursor != 0 || err != nil {
+			t.Error("SScan结果不符合预期")
+		}
+		var iterated []string
+		iter := s.SScanIterator(ctx, "set_key", "", 2)
+		for iter.Next(ctx) {
+			iterated = append(iterated, iter.Val())
+		}
+		if iter.Err() != nil {
+			t.Error(iter.Err())
+		}
+		sort.Strings(iterated)
+		iterated = slices.Compact(iterated)
+		testSetContainsAll(t, iterated, []string{"value1", "value2", "value3", "value4", "value5"})
+
 		//10.随机弹出一个元素
-		popMembers, err := s.SPop(ctx, "set_key")
-		if len(popMembers) != 1 || err != nil {
+		popMember, err := s.SPop(ctx, "set_key")
+		if popMember == "" || err != nil {
 			t.Error("SPop结果不符合预期")
 		}
 
@@ -94,9 +130,9 @@ func Test_setClient(t *testing.T) {
 		}
 
 		//12.随机弹出多个元素
-		popMembers, err = s.SPop(ctx, "set_key", 2)
+		popMembers, err := s.SPopN(ctx, "set_key", 2)
 		if len(popMembers) != 2 || err != nil {
-			t.Error("SPop多个元素结果不符合预期")
+			t.Error("SPopN多个元素结果不符合预期")
 		}
 
 		//13.验证集合元素数量减少
@@ -147,6 +183,16 @@ func Test_setClient(t *testing.T) {
 		sort.Strings(inter)
 		testSliceEquals(t, inter, []string{"c", "d"})
 
+		//19.1.测试集合交集元素数，limit限制计数上限
+		count, err = s.SInterCard(ctx, 0, "set_key1", "set_key2")
+		if count != 2 || err != nil {
+			t.Error("SInterCard结果不符合预期")
+		}
+		count, err = s.SInterCard(ctx, 1, "set_key1", "set_key2")
+		if count != 1 || err != nil {
+			t.Error("SInterCard带limit结果不符合预期")
+		}
+
 		//20.测试集合交集并存储
 		count, err = s.SInterStore(ctx, "set_inter", "set_key1", "set_key2")
 		if count != 2 || err != nil {
@@ -361,16 +407,24 @@ func testEmptySetOperations(t *testing.T, s *setpkg.Client, ctx context.Context,
 	}
 
 	//4.随机获取元素
-	randMembers, err := s.SRandMember(ctx, key)
-	if len(randMembers) != 0 || err == nil || err.Error() != "redis: nil" {
+	_, err = s.SRandMember(ctx, key)
+	if !errors.Is(err, redisv9.Nil) {
 		t.Error("空集合的SRandMember结果不符合预期")
 	}
+	randMembers, err := s.SRandMemberN(ctx, key, 3)
+	if len(randMembers) != 0 || err != nil {
+		t.Error("空集合的SRandMemberN结果不符合预期")
+	}
 
 	//5.随机弹出元素
-	popMembers, err := s.SPop(ctx, key)
-	if len(popMembers) != 0 || err == nil || err.Error() != "redis: nil" {
+	_, err = s.SPop(ctx, key)
+	if !errors.Is(err, redisv9.Nil) {
 		t.Error("空集合的SPop结果不符合预期")
 	}
+	popMembers, err := s.SPopN(ctx, key, 3)
+	if len(popMembers) != 0 || err != nil {
+		t.Error("空集合的SPopN结果不符合预期")
+	}
 
 	//6.移除元素
 	count, err := s.SRem(ctx, key, "nonexistent")
@@ -0,0 +1,127 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-24 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	redisv9 "github.com/redis/go-redis/v9"
+	"go-redis-demo/redis"
+	tagindexpkg "go-redis-demo/redis/tagindex"
+)
+
+func Test_tagIndexClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 标签索引测试", func(t *testing.T) {
+		ctx := context.Background()
+		name := "tagindex_key"
+		defer cleanupKeysWithPrefix(t, ctx, name)
+		ix := redis.Client.TagIndex.Index(name)
+
+		//1.解析表达式
+		expr, err := tagindexpkg.Parse("(red OR blue) size:m AND NOT sold")
+		if err != nil || expr.String() != "((red OR blue) AND size:m AND NOT sold)" {
+			t.Error("Parse结果不符合预期", expr, err)
+		}
+		for _, q := range []string{"", "red AND", "(red OR blue", "red)", "OR red", "NOT"} {
+			if _, err = tagindexpkg.Parse(q); !errors.Is(err, tagindexpkg.ErrInvalidQuery) {
+				t.Errorf("非法表达式%q的Parse结果不符合预期: %v", q, err)
+			}
+		}
+
+		//2.建立索引
+		items := map[string][]string{
+			"shirt1": {"red", "size:m"},
+			"shirt2": {"blue", "size:m", "sold"},
+			"shirt3": {"blue", "size:m"},
+			"shirt4": {"green", "size:m"},
+			"shirt5": {"red", "size:l"},
+		}
+		for id, tags := range items {
+			if err = ix.Add(ctx, id, tags...); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		//3.布尔查询
+		cases := map[string][]string{
+			"(red OR blue) AND size:m AND NOT sold": {"shirt1", "shirt3"},
+			"red OR green":                          {"shirt1", "shirt4", "shirt5"},
+			"NOT size:m":                            {"shirt5"},
+			"NOT red NOT blue":                      {"shirt4"},
+			"size:m AND NOT (red OR sold)":          {"shirt3", "shirt4"},
+			"red AND blue":                          nil,
+		}
+		for q, expected := range cases {
+			ids, err := ix.Query(ctx, q)
+			sort.Strings(ids)
+			if err != nil || len(ids) != len(expected) {
+				t.Errorf("Query %q结果不符合预期: %v %v", q, ids, err)
+				continue
+			}
+			for i := range ids {
+				if ids[i] != expected[i] {
+					t.Errorf("Query %q结果不符合预期: %v", q, ids)
+					break
+				}
+			}
+		}
+		n, err := ix.Count(ctx, "size:m")
+		if n != 4 || err != nil {
+			t.Error("Count结果不符合预期", n, err)
+		}
+
+		//4.查询结束后临时key已删除
+		keys, err := redis.Client.String.Keys(ctx, name+":tmp:*")
+		if len(keys) != 0 || err != nil {
+			t.Error("临时key未清理", keys, err)
+		}
+
+		//5.重建索引与删除
+		if err = ix.Reindex(ctx, "shirt2", "blue", "size:s"); err != nil {
+			t.Error(err)
+		}
+		tags, err := ix.Tags(ctx, "shirt2")
+		sort.Strings(tags)
+		if len(tags) != 2 || tags[0] != "blue" || tags[1] != "size:s" || err != nil {
+			t.Error("Reindex后Tags结果不符合预期", tags, err)
+		}
+		if err = ix.Remove(ctx, "shirt4"); err != nil {
+			t.Error(err)
+		}
+		n, err = ix.Count(ctx, "size:m")
+		if n != 2 || err != nil {
+			t.Error("Reindex、Remove后Count结果不符合预期", n, err)
+		}
+
+		//6.按对象ID分页
+		res, err := ix.Search(ctx, "NOT green", tagindexpkg.SearchOptions{Desc: true, Offset: 1, Count: 2})
+		if err != nil || res.Total != 4 || len(res.IDs) != 2 || res.IDs[0] != "shirt3" || res.IDs[1] != "shirt2" {
+			t.Errorf("Search按对象ID分页结果不符合预期: %+v %v", res, err)
+		}
+		res, err = ix.Search(ctx, "NOT green", tagindexpkg.SearchOptions{Desc: true, Offset: 1})
+		if err != nil || res.Total != 4 || len(res.IDs) != 3 || res.IDs[0] != "shirt3" {
+			t.Errorf("Search只跳过不限制数量结果不符合预期: %+v %v", res, err)
+		}
+
+		//7.按价格分页
+		priceKey := name + ":price"
+		_, err = redis.Client.ZSet.ZAdd(ctx, priceKey,
+			redisv9.Z{Score: 30, Member: "shirt1"}, redisv9.Z{Score: 10, Member: "shirt3"}, redisv9.Z{Score: 20, Member: "shirt5"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err = ix.Search(ctx, "red OR blue", tagindexpkg.SearchOptions{SortBy: priceKey, Count: 2})
+		if err != nil || res.Total != 3 || len(res.IDs) != 2 || res.IDs[0] != "shirt3" || res.IDs[1] != "shirt5" {
+			t.Errorf("Search按分数分页结果不符合预期: %+v %v", res, err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+// @Author:冯铁城 [17615007230@163.com] 2025-08-23 10:00:00
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-redis-demo/redis"
+	timeseriespkg "go-redis-demo/redis/timeseries"
+)
+
+func Test_timeSeriesClient(t *testing.T) {
+
+	//1.初始化链接
+	config := redis.DefaultConfig()
+	redis.InitClient(config)
+	defer redis.CloseClient()
+
+	//2.运行测试
+	t.Run("redis 有序集合时间序列测试", func(t *testing.T) {
+		ctx := context.Background()
+		keys := []string{"ts_key", "ts_key:1m"}
+		defer redis.Client.String.Del(ctx, keys...)
+
+		testTimeSeriesStore(t, ctx, redis.Client.TimeSeries.ZSet(nil), keys[0], keys[1])
+	})
+
+	t.Run("redis RedisTimeSeries模块时间序列测试", func(t *testing.T) {
+		ctx := context.Background()
+		ok, err := redis.Client.TimeSeries.HasModule(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Skip("服务端未加载RedisTimeSeries模块")
+		}
+		keys := []string{"ts_module_key", "ts_module_key:1m"}
+		defer redis.Client.String.Del(ctx, keys...)
+
+		testTimeSeriesStore(t, ctx, redis.Client.TimeSeries.Module(nil), keys[0], keys[1])
+	})
+}
+
+// 测试时间序列存储，两种实现的行为应当一致
+func testTimeSeriesStore(t *testing.T, ctx context.Context, s timeseriespkg.Store, key, dest string) {
+
+	//1.写入最近的样本：从整分钟开始，每10秒一个样本，共3分钟，值依次为0到17
+	base := time.Now().Truncate(time.Minute).Add(-5 * time.Minute)
+	for i := 0; i < 18; i++ {
+		if err := s.Add(ctx, key, base.Add(time.Duration(i)*10*time.Second), float64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//2.同一时间戳重复写入以最后一次为准，相同值写入不同时间不会互相覆盖
+	if err := s.Add(ctx, key, base, 100); err != nil {
+		t.Error(err)
+	}
+	if err := s.Add(ctx, key, base, 0); err != nil {
+		t.Error(err)
+	}
+	samples, err := s.Range(ctx, key, base, base.Add(time.Hour))
+	if len(samples) != 18 || err != nil || samples[0].Value != 0 || !samples[17].Time.Equal(base.Add(170*time.Second)) {
+		t.Fatal("Range结果不符合预期", len(samples), err)
+	}
+
+	//3.按分钟聚合
+	end := base.Add(time.Hour)
+	cases := map[timeseriespkg.Aggregation][]float64{
+		timeseriespkg.Avg:   {2.5, 8.5, 14.5},
+		timeseriespkg.Min:   {0, 6, 12},
+		timeseriespkg.Max:   {5, 11, 17},
+		timeseriespkg.Sum:   {15, 51, 87},
+		timeseriespkg.Count: {6, 6, 6},
+	}
+	for agg, expected := range cases {
+		buckets, err := s.Aggregate(ctx, key, base, end, time.Minute, agg)
+		if err != nil || len(buckets) != 3 {
+			t.Fatalf("Aggregate %d结果不符合预期: %v %v", agg, buckets, err)
+		}
+		for i, b := range buckets {
+			if b.Value != expected[i] || !b.Time.Equal(base.Add(time.Duration(i)*time.Minute)) {
+				t.Errorf("Aggregate %d第%d个时间桶不符合预期: %+v", agg, i, b)
+			}
+		}
+	}
+	if _, err = s.Aggregate(ctx, key, base, end, 0, timeseriespkg.Avg); !errors.Is(err, timeseriespkg.ErrInvalidBucket) {
+		t.Error("非法时间桶Aggregate结果不符合预期", err)
+	}
+
+	//4.降采样到按分钟聚合的序列，重复执行结果不变
+	rule := timeseriespkg.Rule{Dest: dest, Bucket: time.Minute, Aggregation: timeseriespkg.Max}
+	for i := 0; i < 2; i++ {
+		n, err := s.Downsample(ctx, key, rule, base, end)
+		if n != 3 || err != nil {
+			t.Error("Downsample结果不符合预期", n, err)
+		}
+	}
+	samples, err = s.Range(ctx, dest, base, end)
+	if len(samples) != 3 || err != nil || samples[2].Value != 17 {
+		t.Error("降采样序列Range结果不符合预期", samples, err)
+	}
+
+	//5.删除第一分钟的样本
+	n, err := s.Trim(ctx, key, base.Add(time.Minute))
+	if n != 6 || err != nil {
+		t.Error("Trim结果不符合预期", n, err)
+	}
+	samples, err = s.Range(ctx, key, base, end)
+	if len(samples) != 12 || err != nil || samples[0].Value != 6 {
+		t.Error("Trim后Range结果不符合预期", len(samples), err)
+	}
+}
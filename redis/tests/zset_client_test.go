@@ -3,8 +3,10 @@ package redis_test
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
 	redisv9 "github.com/redis/go-redis/v9"
 	"go-redis-demo/redis"
@@ -140,14 +142,14 @@ func Test_zSetClient(t *testing.T) {
 		}
 
 		//18.按分数范围获取元素（从小到大）
-		members, err = z.ZRangeByScore(ctx, "zset_key", "1", "2", 0, 0)
+		members, err = z.ZRangeByScore(ctx, "zset_key", zsetpkg.Scores(1, 2), 0, 0)
 		if len(members) != 2 || err != nil {
 			t.Error("ZRangeByScore结果不符合预期")
 		}
 		testSetContainsAllZSet(t, members, []string{"member3", "member4"})
 
 		//19.按分数范围获取元素及分数（从小到大）
-		membersWithScores, err = z.ZRangeByScoreWithScores(ctx, "zset_key", "1", "2", 0, 0)
+		membersWithScores, err = z.ZRangeByScoreWithScores(ctx, "zset_key", zsetpkg.Scores(1, 2), 0, 0)
 		if len(membersWithScores) != 2 || err != nil {
 			t.Error("ZRangeByScoreWithScores结果不符合预期")
 		}
@@ -161,14 +163,14 @@ func Test_zSetClient(t *testing.T) {
 		}
 
 		//20.按分数范围获取元素（从大到小）
-		members, err = z.ZRevRangeByScore(ctx, "zset_key", "5", "3", 0, 0)
+		members, err = z.ZRevRangeByScore(ctx, "zset_key", zsetpkg.Scores(3, 5), 0, 0)
 		if len(members) != 2 || err != nil {
 			t.Error("ZRevRangeByScore结果不符合预期")
 		}
 		testSetContainsAllZSet(t, members, []string{"member1", "member2"})
 
 		//21.按分数范围获取元素及分数（从大到小）
-		membersWithScores, err = z.ZRevRangeByScoreWithScores(ctx, "zset_key", "5", "3", 0, 0)
+		membersWithScores, err = z.ZRevRangeByScoreWithScores(ctx, "zset_key", zsetpkg.Scores(3, 5), 0, 0)
 		if len(membersWithScores) != 2 || err != nil {
 			t.Error("ZRevRangeByScoreWithScores结果不符合预期")
 		}
@@ -182,13 +184,18 @@ func Test_zSetClient(t *testing.T) {
 		}
 
 		//22.按分数范围获取元素（带偏移和限制）
-		members, err = z.ZRangeByScore(ctx, "zset_key", "0", "5", 1, 2)
+		members, err = z.ZRangeByScore(ctx, "zset_key", zsetpkg.Scores(0, 5), 1, 2)
 		if len(members) != 2 || err != nil {
 			t.Error("ZRangeByScore带偏移和限制结果不符合预期")
 		}
+		members, err = z.ZRangeByScore(ctx, "zset_key", zsetpkg.Scores(0, 5), 1, 0)
+		if err != nil {
+			t.Error(err)
+		}
+		testSliceEqualsZSet(t, members, []string{"member3", "member1", "member2"})
 
 		//23.获取指定分数范围内的元素数量
-		count, err = z.ZCount(ctx, "zset_key", "1", "2")
+		count, err = z.ZCount(ctx, "zset_key", zsetpkg.Scores(1, 2))
 		if count != 2 || err != nil {
 			t.Error("ZCount结果不符合预期")
 		}
@@ -230,7 +237,7 @@ func Test_zSetClient(t *testing.T) {
 		}
 
 		//30.删除有序集合中指定分数范围的元素
-		count, err = z.ZRemRangeByScore(ctx, "zset_score_key", "2", "4")
+		count, err = z.ZRemRangeByScore(ctx, "zset_score_key", zsetpkg.Scores(2, 4))
 		if count != 3 || err != nil {
 			t.Error("ZRemRangeByScore结果不符合预期")
 		}
@@ -256,6 +263,12 @@ func Test_zSetClient(t *testing.T) {
 
 		//35.测试大批量数据
 		testBulkZSetOperations(t, z, ctx, "bulk_zset", 100)
+
+		//36.测试扩展命令
+		testExtendedZSetOperations(t, z, ctx)
+
+		//37.测试区间校验与分批迭代
+		testZSetRangeAndIterator(t, z, ctx, "zset_iter")
 	})
 }
 
@@ -384,7 +397,7 @@ func testEmptyZSetOperations(t *testing.T, z *zsetpkg.Client, ctx context.Contex
 	}
 
 	//6.按分数范围获取元素数量
-	count, err = z.ZCount(ctx, key, "-inf", "+inf")
+	count, err = z.ZCount(ctx, key, zsetpkg.All)
 	if count != 0 || err != nil {
 		t.Error("空有序集合的ZCount结果不符合预期")
 	}
@@ -421,7 +434,7 @@ func testBulkZSetOperations(t *testing.T, z *zsetpkg.Client, ctx context.Context
 	}
 
 	//5.按分数范围获取元素数量
-	rangeCount, err := z.ZCount(ctx, key, "10", "20")
+	rangeCount, err := z.ZCount(ctx, key, zsetpkg.Scores(10, 20))
 	if rangeCount != 11 || err != nil {
 		t.Error("批量数据ZCount结果不符合预期")
 	}
@@ -429,3 +442,210 @@ func testBulkZSetOperations(t *testing.T, z *zsetpkg.Client, ctx context.Context
 	//6.清理测试数据
 	cleanupZSets(t, z, ctx, []string{key})
 }
+
+// 测试有序集合扩展命令
+func testExtendedZSetOperations(t *testing.T, z *zsetpkg.Client, ctx context.Context) {
+	keys := []string{"zset_ext1", "zset_ext2", "zset_lex", "zset_ext_dest"}
+	defer cleanupZSets(t, z, ctx, keys)
+
+	//1.ZADD选项：NX不更新已存在成员，GT只在分数变大时更新，CH返回被更新的成员数
+	_, err := z.ZAdd(ctx, "zset_ext1", redisv9.Z{Score: 1, Member: "a"}, redisv9.Z{Score: 2, Member: "b"}, redisv9.Z{Score: 3, Member: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := z.ZAddWithOptions(ctx, "zset_ext1", zsetpkg.AddOptions{NX: true}, redisv9.Z{Score: 10, Member: "a"}, redisv9.Z{Score: 0, Member: "z"})
+	if count != 1 || err != nil {
+		t.Error("ZAdd NX结果不符合预期", count, err)
+	}
+	count, err = z.ZAddWithOptions(ctx, "zset_ext1", zsetpkg.AddOptions{GT: true, CH: true}, redisv9.Z{Score: 0.5, Member: "a"}, redisv9.Z{Score: 5, Member: "b"})
+	if count != 1 || err != nil {
+		t.Error("ZAdd GT CH结果不符合预期", count, err)
+	}
+
+	//2.ZSCORE/ZMSCORE，不存在的成员与分数为0的成员可以区分
+	score, err := z.ZScore(ctx, "zset_ext1", "b")
+	if score != 5 || err != nil {
+		t.Error("ZScore结果不符合预期", score, err)
+	}
+	if _, err = z.ZScore(ctx, "zset_ext1", "nonexistent"); !errors.Is(err, redisv9.Nil) {
+		t.Error("ZScore不存在的成员应该返回redis.Nil")
+	}
+	scores, err := z.ZMScore(ctx, "zset_ext1", "a", "z", "nonexistent")
+	if len(scores) != 2 || scores["a"] != 1 || scores["z"] != 0 || err != nil {
+		t.Error("ZMScore结果不符合预期", scores, err)
+	}
+
+	//3.统一ZRANGE：按分数开区间、倒序与分页
+	members, err := z.ZRangeArgs(ctx, zsetpkg.RangeArgs{Key: "zset_ext1", By: zsetpkg.ByScore, Range: zsetpkg.Between(zsetpkg.Excl(0), zsetpkg.PosInf)})
+	if len(members) != 3 || members[0] != "a" || err != nil {
+		t.Error("ZRangeArgs按分数结果不符合预期", members, err)
+	}
+	withScores, err := z.ZRangeArgsWithScores(ctx, zsetpkg.RangeArgs{Key: "zset_ext1", By: zsetpkg.ByScore, Range: zsetpkg.Between(zsetpkg.NegInf, zsetpkg.Incl(5)), Rev: true, Offset: 1, Count: 2})
+	if err != nil {
+		t.Error(err)
+	}
+	testZSetElementsWithScores(t, withScores, []redisv9.Z{{Score: 3, Member: "c"}, {Score: 1, Member: "a"}})
+	count, err = z.ZRangeStore(ctx, "zset_ext_dest", zsetpkg.RangeArgs{Key: "zset_ext1", Start: 0, Stop: 1})
+	if count != 2 || err != nil {
+		t.Error("ZRangeStore结果不符合预期", count, err)
+	}
+	count, err = z.ZRangeStore(ctx, "zset_ext_dest", zsetpkg.RangeArgs{Key: "zset_ext1", By: zsetpkg.ByScore, Range: zsetpkg.All, Rev: true, Offset: 1})
+	if count != 3 || err != nil {
+		t.Error("只有偏移量时ZRangeStore结果不符合预期", count, err)
+	}
+
+	//4.字典序区间
+	_, err = z.ZAdd(ctx, "zset_lex", redisv9.Z{Member: "apple"}, redisv9.Z{Member: "banana"}, redisv9.Z{Member: "cherry"}, redisv9.Z{Member: "date"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, err = z.ZRangeByLex(ctx, "zset_lex", zsetpkg.Between(zsetpkg.LexIncl("b"), zsetpkg.LexExcl("date")), 0, 0)
+	if len(members) != 2 || members[0] != "banana" || members[1] != "cherry" || err != nil {
+		t.Error("ZRangeByLex结果不符合预期", members, err)
+	}
+	members, err = z.ZRevRangeByLex(ctx, "zset_lex", zsetpkg.All, 0, 1)
+	if len(members) != 1 || members[0] != "date" || err != nil {
+		t.Error("ZRevRangeByLex结果不符合预期", members, err)
+	}
+	members, err = z.ZRevRangeByLex(ctx, "zset_lex", zsetpkg.All, 1, 0)
+	if len(members) != 3 || members[0] != "cherry" || members[2] != "apple" || err != nil {
+		t.Error("只有偏移量时ZRevRangeByLex结果不符合预期", members, err)
+	}
+	count, err = z.ZLexCount(ctx, "zset_lex", zsetpkg.Between(zsetpkg.NegInf, zsetpkg.LexIncl("banana")))
+	if count != 2 || err != nil {
+		t.Error("ZLexCount结果不符合预期", count, err)
+	}
+	count, err = z.ZRemRangeByLex(ctx, "zset_lex", zsetpkg.Between(zsetpkg.LexExcl("apple"), zsetpkg.LexIncl("cherry")))
+	if count != 2 || err != nil {
+		t.Error("ZRemRangeByLex结果不符合预期", count, err)
+	}
+
+	//5.并集、交集、差集
+	_, err = z.ZAdd(ctx, "zset_ext2", redisv9.Z{Score: 10, Member: "a"}, redisv9.Z{Score: 20, Member: "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	union, err := z.ZUnion(ctx, zsetpkg.Combine{Keys: []string{"zset_ext1", "zset_ext2"}, Weights: []float64{1, 2}, Aggregate: zsetpkg.MaxAgg})
+	if len(union) != 5 || union[4].Member != "y" || union[4].Score != 40 || err != nil {
+		t.Error("ZUnion结果不符合预期", union, err)
+	}
+	inter, err := z.ZInter(ctx, zsetpkg.Combine{Keys: []string{"zset_ext1", "zset_ext2"}})
+	if len(inter) != 1 || inter[0].Score != 11 || err != nil {
+		t.Error("ZInter结果不符合预期", inter, err)
+	}
+	count, err = z.ZInterCard(ctx, 0, "zset_ext1", "zset_ext2")
+	if count != 1 || err != nil {
+		t.Error("ZInterCard结果不符合预期", count, err)
+	}
+	diff, err := z.ZDiff(ctx, "zset_ext1", "zset_ext2")
+	if len(diff) != 3 || err != nil {
+		t.Error("ZDiff结果不符合预期", diff, err)
+	}
+	count, err = z.ZUnionStore(ctx, "zset_ext_dest", zsetpkg.Combine{Keys: []string{"zset_ext1", "zset_ext2"}, Aggregate: zsetpkg.MinAgg})
+	if count != 5 || err != nil {
+		t.Error("ZUnionStore结果不符合预期", count, err)
+	}
+
+	//6.随机成员
+	members, err = z.ZRandMember(ctx, "zset_ext1", 10)
+	if len(members) != 4 || err != nil {
+		t.Error("ZRandMember结果不符合预期", members, err)
+	}
+	members, err = z.ZRandMember(ctx, "zset_ext1", -10)
+	if len(members) != 10 || err != nil {
+		t.Error("ZRandMember负数count结果不符合预期", members, err)
+	}
+
+	//7.弹出最小、最大成员
+	popped, err := z.ZPopMin(ctx, "zset_ext1", 1)
+	if len(popped) != 1 || popped[0].Member != "z" || err != nil {
+		t.Error("ZPopMin结果不符合预期", popped, err)
+	}
+	popped, err = z.ZPopMax(ctx, "zset_ext1", 1)
+	if len(popped) != 1 || popped[0].Member != "b" || err != nil {
+		t.Error("ZPopMax结果不符合预期", popped, err)
+	}
+	key, popped, err := z.ZMPop(ctx, zsetpkg.Max, 5, "nonexistent_zset", "zset_ext2")
+	if key != "zset_ext2" || len(popped) != 2 || popped[0].Member != "y" || err != nil {
+		t.Error("ZMPop结果不符合预期", key, popped, err)
+	}
+	withKey, err := z.BZPopMin(ctx, time.Second, "zset_ext2", "zset_ext1")
+	if withKey == nil || withKey.Key != "zset_ext1" || withKey.Member != "a" || err != nil {
+		t.Error("BZPopMin结果不符合预期", withKey, err)
+	}
+	key, popped, err = z.BZMPop(ctx, time.Second, zsetpkg.Min, 1, "zset_ext1")
+	if key != "zset_ext1" || len(popped) != 1 || popped[0].Member != "c" || err != nil {
+		t.Error("BZMPop结果不符合预期", key, popped, err)
+	}
+	if _, err = z.BZPopMax(ctx, time.Second, "zset_ext1"); !errors.Is(err, redisv9.Nil) {
+		t.Error("BZPopMax空有序集合应该超时")
+	}
+}
+
+// 测试区间校验与分批迭代
+func testZSetRangeAndIterator(t *testing.T, z *zsetpkg.Client, ctx context.Context, key string) {
+	defer cleanupZSets(t, z, ctx, []string{key})
+
+	//1.非法区间：下界大于上界、端点类型不一致、分数命令使用字典序端点、未初始化的端点
+	invalid := []zsetpkg.Range{
+		zsetpkg.Scores(5, 3),
+		zsetpkg.Between(zsetpkg.PosInf, zsetpkg.Incl(1)),
+		zsetpkg.Between(zsetpkg.Incl(1), zsetpkg.LexIncl("a")),
+		zsetpkg.Between(zsetpkg.LexIncl("a"), zsetpkg.PosInf),
+		{Max: zsetpkg.Incl(1)},
+	}
+	for i, r := range invalid {
+		if _, err := z.ZCount(ctx, key, r); !errors.Is(err, zsetpkg.ErrInvalidRange) {
+			t.Errorf("非法区间%d的ZCount结果不符合预期: %v", i, err)
+		}
+	}
+	if _, err := z.ZRevRangeByScore(ctx, key, zsetpkg.Scores(5, 3), 0, 0); !errors.Is(err, zsetpkg.ErrInvalidRange) {
+		t.Error("倒序查询交换端点应该返回ErrInvalidRange", err)
+	}
+	if _, err := z.ZRangeByScore(ctx, key, zsetpkg.Between(zsetpkg.LexIncl("a"), zsetpkg.PosInf), 0, 0); !errors.Is(err, zsetpkg.ErrInvalidRange) {
+		t.Error("ZRangeByScore使用字典序端点应该返回ErrInvalidRange", err)
+	}
+	if _, err := z.ZRemRangeByScore(ctx, key, zsetpkg.Scores(5, 3)); !errors.Is(err, zsetpkg.ErrInvalidRange) {
+		t.Error("ZRemRangeByScore非法区间应该返回ErrInvalidRange", err)
+	}
+	if _, err := z.ZLexCount(ctx, key, zsetpkg.Scores(1, 2)); !errors.Is(err, zsetpkg.ErrInvalidRange) {
+		t.Error("字典序命令使用分数端点应该返回ErrInvalidRange")
+	}
+
+	//2.准备数据：每3个成员分数相同，共30个成员
+	members := make([]redisv9.Z, 30)
+	for i := range members {
+		members[i] = redisv9.Z{Score: float64(i / 3), Member: "m" + strconv.Itoa(100+i)}
+	}
+	if _, err := z.ZAdd(ctx, key, members...); err != nil {
+		t.Fatal(err)
+	}
+
+	//3.分批大小小于相同分数的成员数时也不重复、不遗漏
+	for _, chunk := range []int64{1, 2, 4, 7, 100} {
+		var got []string
+		it := z.Iterate(key, zsetpkg.Between(zsetpkg.Excl(0), zsetpkg.Incl(8)), chunk)
+		for it.Next(ctx) {
+			got = append(got, it.Val().Member.(string))
+		}
+		if it.Err() != nil || len(got) != 24 || got[0] != "m103" || got[23] != "m126" {
+			t.Errorf("Iterate chunk=%d 结果不符合预期: %v %v", chunk, got, it.Err())
+		}
+	}
+
+	//4.倒序迭代
+	var got []string
+	it := z.IterateRev(key, zsetpkg.All, 2)
+	for it.Next(ctx) {
+		got = append(got, it.Val().Member.(string))
+	}
+	if it.Err() != nil || len(got) != 30 || got[0] != "m129" || got[29] != "m100" {
+		t.Errorf("IterateRev结果不符合预期: %v %v", got, it.Err())
+	}
+
+	//5.非法区间的迭代器直接返回错误
+	it = z.Iterate(key, zsetpkg.Scores(2, 1), 10)
+	if it.Next(ctx) || !errors.Is(it.Err(), zsetpkg.ErrInvalidRange) {
+		t.Error("非法区间Iterate结果不符合预期")
+	}
+}
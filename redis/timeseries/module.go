@@ -0,0 +1,148 @@
+// Package timeseries 提供基于Redis有序集合的时间序列封装，并支持切换到RedisTimeSeries模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-23 10:00:00
+package timeseries
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ModuleStore 基于RedisTimeSeries模块（TS.*命令）的时间序列存储
+type ModuleStore struct {
+	c    *Client
+	opts *Options
+}
+
+// Add 写入样本，key不存在时自动创建，同一时间戳的样本以最后一次写入为准
+func (s *ModuleStore) Add(ctx context.Context, key string, t time.Time, value float64) error {
+	return s.c.rdb.TSAddWithArgs(ctx, key, t.UnixMilli(), value, s.tsOptions()).Err()
+}
+
+// Range 查询[from, to]内的样本
+func (s *ModuleStore) Range(ctx context.Context, key string, from, to time.Time) ([]Sample, error) {
+	values, err := s.c.rdb.TSRange(ctx, key, int(from.UnixMilli()), int(to.UnixMilli())).Result()
+	if err != nil {
+		return nil, err
+	}
+	return toSamples(values), nil
+}
+
+// Aggregate 按时间桶聚合[from, to]内的样本，由服务端完成聚合
+func (s *ModuleStore) Aggregate(ctx context.Context, key string, from, to time.Time, bucket time.Duration, agg Aggregation) ([]Sample, error) {
+
+	//1.校验参数
+	size := bucket.Milliseconds()
+	if size <= 0 {
+		return nil, ErrInvalidBucket
+	}
+	aggregator, err := toAggregator(agg)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.带聚合参数查询
+	values, err := s.c.rdb.TSRangeWithArgs(ctx, key, int(from.UnixMilli()), int(to.UnixMilli()), &redis.TSRangeOptions{
+		Aggregator:     aggregator,
+		BucketDuration: int(size),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return toSamples(values), nil
+}
+
+// Trim 删除before之前的样本
+func (s *ModuleStore) Trim(ctx context.Context, key string, before time.Time) (int64, error) {
+	return s.c.rdb.TSDel(ctx, key, 0, int(before.UnixMilli()-1)).Result()
+}
+
+// Downsample 将[from, to]内的样本按规则聚合后写入目标序列
+func (s *ModuleStore) Downsample(ctx context.Context, src string, rule Rule, from, to time.Time) (int64, error) {
+
+	//1.由服务端聚合源序列
+	buckets, err := s.Aggregate(ctx, src, from, to, rule.Bucket, rule.Aggregation)
+	if err != nil || len(buckets) == 0 {
+		return 0, err
+	}
+
+	//2.通过管道写入目标序列
+	_, err = s.c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, b := range buckets {
+			pipe.TSAddWithArgs(ctx, rule.Dest, b.Time.UnixMilli(), b.Value, s.tsOptions())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(buckets)), nil
+}
+
+// CreateRule 创建服务端降采样规则，之后写入源序列的样本会由服务端自动聚合到目标序列
+// 注意规则只对创建之后写入的样本生效，历史样本需要先调用Downsample补齐
+func (s *ModuleStore) CreateRule(ctx context.Context, src string, rule Rule) error {
+
+	//1.校验参数
+	size := rule.Bucket.Milliseconds()
+	if size <= 0 {
+		return ErrInvalidBucket
+	}
+	aggregator, err := toAggregator(rule.Aggregation)
+	if err != nil {
+		return err
+	}
+
+	//2.规则要求源序列与目标序列都已存在
+	for _, key := range []string{src, rule.Dest} {
+		err = s.c.rdb.TSCreateWithArgs(ctx, key, s.tsOptions()).Err()
+		if err != nil && !strings.Contains(strings.ToLower(err.Error()), "already exists") {
+			return err
+		}
+	}
+
+	//3.创建规则
+	return s.c.rdb.TSCreateRule(ctx, src, rule.Dest, aggregator, int(size)).Err()
+}
+
+// DeleteRule 删除服务端降采样规则
+func (s *ModuleStore) DeleteRule(ctx context.Context, src, dest string) error {
+	return s.c.rdb.TSDeleteRule(ctx, src, dest).Err()
+}
+
+// tsOptions 返回创建序列时使用的选项
+func (s *ModuleStore) tsOptions() *redis.TSOptions {
+	return &redis.TSOptions{
+		Retention:       int(s.opts.Retention.Milliseconds()),
+		DuplicatePolicy: "LAST",
+	}
+}
+
+// toAggregator 将聚合方式转换为RedisTimeSeries的聚合器
+func toAggregator(agg Aggregation) (redis.Aggregator, error) {
+	switch agg {
+	case Avg:
+		return redis.Avg, nil
+	case Min:
+		return redis.Min, nil
+	case Max:
+		return redis.Max, nil
+	case Sum:
+		return redis.Sum, nil
+	case Count:
+		return redis.Count, nil
+	default:
+		return redis.Invalid, ErrUnknownAggregation
+	}
+}
+
+// toSamples 将RedisTimeSeries的返回值转换为样本
+func toSamples(values []redis.TSTimestampValue) []Sample {
+	samples := make([]Sample, len(values))
+	for i, v := range values {
+		samples[i] = Sample{Time: time.UnixMilli(v.Timestamp), Value: v.Value}
+	}
+	return samples
+}
@@ -0,0 +1,170 @@
+// Package timeseries 提供基于Redis有序集合的时间序列封装，并支持切换到RedisTimeSeries模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-23 10:00:00
+package timeseries
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// Aggregation 按时间桶聚合的方式
+type Aggregation int
+
+const (
+	Avg   Aggregation = iota + 1 // 平均值
+	Min                          // 最小值
+	Max                          // 最大值
+	Sum                          // 求和
+	Count                        // 样本数
+)
+
+var (
+	// ErrInvalidBucket 时间桶大小不合法
+	ErrInvalidBucket = errors.New("timeseries: 时间桶大小必须为正整数毫秒")
+
+	// ErrUnknownAggregation 不支持的聚合方式
+	ErrUnknownAggregation = errors.New("timeseries: 不支持的聚合方式")
+)
+
+// Sample 样本，聚合结果中Time为时间桶的起始时间
+type Sample struct {
+	Time  time.Time // 时间，精确到毫秒
+	Value float64   // 值
+}
+
+// Rule 降采样规则：将源序列按Bucket聚合后写入Dest
+type Rule struct {
+	Dest        string        // 目标序列的key
+	Bucket      time.Duration // 时间桶大小
+	Aggregation Aggregation   // 聚合方式
+}
+
+// Store 时间序列存储，有序集合与RedisTimeSeries两种实现的统一接口
+type Store interface {
+	// Add 写入样本，同一时间戳的样本以最后一次写入为准
+	Add(ctx context.Context, key string, t time.Time, value float64) error
+
+	// Range 查询[from, to]内的样本，按时间从早到晚排列
+	Range(ctx context.Context, key string, from, to time.Time) ([]Sample, error)
+
+	// Aggregate 按时间桶聚合[from, to]内的样本，时间桶从Unix纪元开始对齐，不返回空桶
+	Aggregate(ctx context.Context, key string, from, to time.Time, bucket time.Duration, agg Aggregation) ([]Sample, error)
+
+	// Trim 删除before之前的样本，返回删除的样本数
+	Trim(ctx context.Context, key string, before time.Time) (int64, error)
+
+	// Downsample 将[from, to]内的样本按规则聚合后写入目标序列，返回写入的样本数
+	Downsample(ctx context.Context, src string, rule Rule, from, to time.Time) (int64, error)
+}
+
+// Options 定义了时间序列的配置选项
+type Options struct {
+	Retention time.Duration // 样本保留时长，为0时永久保留
+}
+
+// DefaultOptions 返回一个包含推荐默认值的时间序列配置实例
+func DefaultOptions() *Options {
+	return &Options{
+		Retention: 7 * 24 * time.Hour, // 默认保留7天
+	}
+}
+
+// Client 时间序列客户端
+type Client struct {
+	rdb  *redis.Client
+	zset *zsetpkg.Client
+}
+
+// New 创建时间序列客户端
+func New(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, zset: zsetpkg.New(rdb)}
+}
+
+// ZSet 返回基于有序集合的存储，opts为nil时使用默认配置
+func (c *Client) ZSet(opts *Options) *ZSetStore {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &ZSetStore{c: c, opts: opts}
+}
+
+// Module 返回基于RedisTimeSeries模块的存储，opts为nil时使用默认配置
+func (c *Client) Module(opts *Options) *ModuleStore {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &ModuleStore{c: c, opts: opts}
+}
+
+// Store 检测服务端是否加载了RedisTimeSeries模块，有则使用模块存储，否则使用有序集合存储
+func (c *Client) Store(ctx context.Context, opts *Options) (Store, error) {
+	ok, err := c.HasModule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return c.Module(opts), nil
+	}
+	return c.ZSet(opts), nil
+}
+
+// HasModule 检测服务端是否支持RedisTimeSeries命令
+func (c *Client) HasModule(ctx context.Context) (bool, error) {
+
+	//1.查询一个不存在的key，模块存在时返回key不存在的错误，模块不存在时返回未知命令
+	err := c.rdb.Do(ctx, "TS.INFO", "timeseries:probe:nonexistent").Err()
+	if err == nil {
+		return true, nil
+	}
+
+	//2.根据错误信息判断
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "unknown command") {
+		return false, nil
+	}
+	if strings.Contains(msg, "key does not exist") {
+		return true, nil
+	}
+	return false, err
+}
+
+// RunDownsampler 按interval周期性地将最近已结束的时间桶降采样到目标序列，直到上下文结束
+// 每轮回看interval加一个时间桶的范围，同一时间桶重复写入以最后一次为准，因此可以安全地重复执行
+func RunDownsampler(ctx context.Context, store Store, src string, rule Rule, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		//1.只处理已结束的时间桶，避免写入不完整的聚合值
+		now := time.Now()
+		to := alignBucket(now.UnixMilli(), rule.Bucket.Milliseconds())
+		from := alignBucket(now.Add(-interval-rule.Bucket).UnixMilli(), rule.Bucket.Milliseconds())
+		if _, err := store.Downsample(ctx, src, rule, time.UnixMilli(from), time.UnixMilli(to-1)); err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		//2.等待下一轮
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// alignBucket 返回ms所在时间桶的起始时间（毫秒），bucket不大于0时原样返回
+func alignBucket(ms, bucket int64) int64 {
+	if bucket <= 0 {
+		return ms
+	}
+	start := ms - ms%bucket
+	if ms < 0 && ms%bucket != 0 {
+		start -= bucket
+	}
+	return start
+}
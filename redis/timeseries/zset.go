@@ -0,0 +1,178 @@
+// Package timeseries 提供基于Redis有序集合的时间序列封装，并支持切换到RedisTimeSeries模块
+// @Author:冯铁城 [17615007230@163.com] 2025-08-23 10:00:00
+package timeseries
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	zsetpkg "go-redis-demo/redis/zset"
+)
+
+// 有序集合中每个样本的成员格式为 "时间(毫秒):值"，分数为时间（毫秒），
+// 成员中带上时间保证不同时间的相同值不会互相覆盖
+
+// ZSetStore 基于有序集合的时间序列存储
+type ZSetStore struct {
+	c    *Client
+	opts *Options
+}
+
+// Add 写入样本，同一时间戳的旧样本会被替换，并删除超过保留时长的样本
+func (s *ZSetStore) Add(ctx context.Context, key string, t time.Time, value float64) error {
+	_, err := s.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		s.add(ctx, pipe, key, t.UnixMilli(), value)
+		return nil
+	})
+	return err
+}
+
+// Range 查询[from, to]内的样本
+func (s *ZSetStore) Range(ctx context.Context, key string, from, to time.Time) ([]Sample, error) {
+	members, err := s.c.zset.ZRangeByScoreWithScores(ctx, key, timeRange(from, to), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, 0, len(members))
+	for _, m := range members {
+		samples = append(samples, parseSample(m))
+	}
+	return samples, nil
+}
+
+// Aggregate 按时间桶聚合[from, to]内的样本，分批读取以避免一次性加载大量样本
+func (s *ZSetStore) Aggregate(ctx context.Context, key string, from, to time.Time, bucket time.Duration, agg Aggregation) ([]Sample, error) {
+
+	//1.校验参数
+	size := bucket.Milliseconds()
+	if size <= 0 {
+		return nil, ErrInvalidBucket
+	}
+	if agg < Avg || agg > Count {
+		return nil, ErrUnknownAggregation
+	}
+
+	//2.按时间顺序遍历样本，时间桶变化时输出上一个时间桶的聚合值
+	var (
+		result []Sample
+		acc    accumulator
+		start  int64
+	)
+	it := s.c.zset.Iterate(key, timeRange(from, to), 1000)
+	for it.Next(ctx) {
+		sample := parseSample(it.Val())
+		b := alignBucket(sample.Time.UnixMilli(), size)
+		if acc.n > 0 && b != start {
+			result = append(result, Sample{Time: time.UnixMilli(start), Value: acc.value(agg)})
+			acc = accumulator{}
+		}
+		start = b
+		acc.add(sample.Value)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	//3.输出最后一个时间桶
+	if acc.n > 0 {
+		result = append(result, Sample{Time: time.UnixMilli(start), Value: acc.value(agg)})
+	}
+	return result, nil
+}
+
+// Trim 删除before之前的样本
+func (s *ZSetStore) Trim(ctx context.Context, key string, before time.Time) (int64, error) {
+	return s.c.zset.ZRemRangeByScore(ctx, key, zsetpkg.Between(zsetpkg.NegInf, zsetpkg.Excl(float64(before.UnixMilli()))))
+}
+
+// Downsample 将[from, to]内的样本按规则聚合后写入目标序列
+func (s *ZSetStore) Downsample(ctx context.Context, src string, rule Rule, from, to time.Time) (int64, error) {
+
+	//1.聚合源序列
+	buckets, err := s.Aggregate(ctx, src, from, to, rule.Bucket, rule.Aggregation)
+	if err != nil || len(buckets) == 0 {
+		return 0, err
+	}
+
+	//2.在同一个事务中写入目标序列
+	_, err = s.c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, b := range buckets {
+			s.add(ctx, pipe, rule.Dest, b.Time.UnixMilli(), b.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(buckets)), nil
+}
+
+// add 在管道中写入样本：删除同一时间戳的旧样本、写入新样本、删除超过保留时长的样本
+func (s *ZSetStore) add(ctx context.Context, pipe redis.Pipeliner, key string, ms int64, value float64) {
+	score := strconv.FormatInt(ms, 10)
+	pipe.ZRemRangeByScore(ctx, key, score, score)
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(ms), Member: score + ":" + formatValue(value)})
+	if s.opts.Retention > 0 {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(time.Now().Add(-s.opts.Retention).UnixMilli(), 10))
+	}
+}
+
+// accumulator 单个时间桶的聚合状态
+type accumulator struct {
+	n        int64
+	sum      float64
+	min, max float64
+}
+
+// add 累加一个样本
+func (a *accumulator) add(v float64) {
+	if a.n == 0 || v < a.min {
+		a.min = v
+	}
+	if a.n == 0 || v > a.max {
+		a.max = v
+	}
+	a.n++
+	a.sum += v
+}
+
+// value 返回聚合值
+func (a *accumulator) value(agg Aggregation) float64 {
+	switch agg {
+	case Min:
+		return a.min
+	case Max:
+		return a.max
+	case Sum:
+		return a.sum
+	case Count:
+		return float64(a.n)
+	default:
+		return a.sum / float64(a.n)
+	}
+}
+
+// timeRange 返回[from, to]对应的分数区间
+func timeRange(from, to time.Time) zsetpkg.Range {
+	return zsetpkg.Scores(float64(from.UnixMilli()), float64(to.UnixMilli()))
+}
+
+// parseSample 解析有序集合成员为样本
+func parseSample(z redis.Z) Sample {
+	sample := Sample{Time: time.UnixMilli(int64(z.Score))}
+	if member, ok := z.Member.(string); ok {
+		if i := strings.IndexByte(member, ':'); i >= 0 {
+			sample.Value, _ = strconv.ParseFloat(member[i+1:], 64)
+		}
+	}
+	return sample
+}
+
+// formatValue 将值格式化为最短的十进制表示
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
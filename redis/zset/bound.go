@@ -0,0 +1,159 @@
+// Package zset 提供Redis有序集合操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-22 10:00:00
+package zset
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// ErrInvalidRange 区间不合法：端点类型不一致、下界大于上界、分数为NaN等
+var ErrInvalidRange = errors.New("zset: 区间不合法")
+
+// Bound 有序集合区间的端点，可以是分数端点或字典序端点
+// 分数端点通过 Incl、Excl 创建，字典序端点通过 LexIncl、LexExcl 创建，
+// NegInf、PosInf 表示无界，同时适用于分数区间（-inf/+inf）与字典序区间（-/+）
+type Bound struct {
+	value     string  // 端点值，分数端点为格式化后的分数
+	score     float64 // 分数端点的分数
+	exclusive bool    // 是否为开区间
+	lex       bool    // 是否为字典序端点
+	inf       int8    // -1为负无穷，1为正无穷，0为有界
+}
+
+var (
+	// NegInf 负无穷
+	NegInf = Bound{inf: -1}
+
+	// PosInf 正无穷
+	PosInf = Bound{inf: 1}
+)
+
+// Incl 包含score的分数端点
+func Incl(score float64) Bound {
+	return Bound{value: formatScore(score), score: score}
+}
+
+// Excl 不包含score的分数端点
+func Excl(score float64) Bound {
+	return Bound{value: formatScore(score), score: score, exclusive: true}
+}
+
+// LexIncl 包含member的字典序端点
+func LexIncl(member string) Bound {
+	return Bound{value: member, lex: true}
+}
+
+// LexExcl 不包含member的字典序端点
+func LexExcl(member string) Bound {
+	return Bound{value: member, exclusive: true, lex: true}
+}
+
+// ScoreArg 返回分数区间命令使用的端点格式，如 3、(5、-inf、+inf，可直接作为脚本参数
+func (b Bound) ScoreArg() string {
+	switch {
+	case b.inf < 0:
+		return "-inf"
+	case b.inf > 0:
+		return "+inf"
+	case b.exclusive:
+		return "(" + b.value
+	default:
+		return b.value
+	}
+}
+
+// lexArg 返回字典序区间命令使用的端点格式，如 [a、(b、-、+
+func (b Bound) lexArg() string {
+	switch {
+	case b.inf < 0:
+		return "-"
+	case b.inf > 0:
+		return "+"
+	case b.exclusive:
+		return "(" + b.value
+	default:
+		return "[" + b.value
+	}
+}
+
+// Range 有序集合的区间，下界在前、上界在后，倒序查询时也无需交换
+type Range struct {
+	Min Bound // 下界
+	Max Bound // 上界
+}
+
+// All 包含所有成员的区间，同时适用于分数区间与字典序区间
+var All = Range{Min: NegInf, Max: PosInf}
+
+// Between 创建[min, max]区间，端点可以是Incl、Excl、LexIncl、LexExcl、NegInf、PosInf
+func Between(min, max Bound) Range {
+	return Range{Min: min, Max: max}
+}
+
+// Scores 创建闭区间[min, max]的分数区间
+func Scores(min, max float64) Range {
+	return Range{Min: Incl(min), Max: Incl(max)}
+}
+
+// Validate 校验区间的端点是否合法
+func (r Range) Validate() error {
+
+	//1.下界不能是正无穷，上界不能是负无穷
+	if r.Min.inf > 0 || r.Max.inf < 0 {
+		return ErrInvalidRange
+	}
+
+	//2.分数端点不能为NaN，也不能是未初始化的零值，有界的端点类型必须一致
+	for _, b := range []Bound{r.Min, r.Max} {
+		if b.inf == 0 && !b.lex && (b.value == "" || math.IsNaN(b.score)) {
+			return ErrInvalidRange
+		}
+	}
+	if r.Min.inf != 0 || r.Max.inf != 0 {
+		return nil
+	}
+	if r.Min.lex != r.Max.lex {
+		return ErrInvalidRange
+	}
+
+	//3.下界不能大于上界，下界大于上界通常是倒序查询时交换了端点
+	if r.Min.lex {
+		if r.Min.value > r.Max.value {
+			return ErrInvalidRange
+		}
+		return nil
+	}
+	if r.Min.score > r.Max.score {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// scoreArgs 校验并返回分数区间命令使用的上下界
+func (r Range) scoreArgs() (string, string, error) {
+	if err := r.Validate(); err != nil {
+		return "", "", err
+	}
+	if (r.Min.inf == 0 && r.Min.lex) || (r.Max.inf == 0 && r.Max.lex) {
+		return "", "", ErrInvalidRange
+	}
+	return r.Min.ScoreArg(), r.Max.ScoreArg(), nil
+}
+
+// lexArgs 校验并返回字典序区间命令使用的上下界
+func (r Range) lexArgs() (string, string, error) {
+	if err := r.Validate(); err != nil {
+		return "", "", err
+	}
+	if (r.Min.inf == 0 && !r.Min.lex) || (r.Max.inf == 0 && !r.Max.lex) {
+		return "", "", ErrInvalidRange
+	}
+	return r.Min.lexArg(), r.Max.lexArg(), nil
+}
+
+// formatScore 将分数格式化为最短的十进制表示
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
@@ -0,0 +1,126 @@
+// Package zset 提供Redis有序集合操作的封装
+// @Author:冯铁城 [17615007230@163.com] 2025-08-22 10:00:00
+package zset
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Iterator 按分数区间分批遍历有序集合的迭代器
+// 每批以上一批最后一个成员的分数作为新的端点继续查询，并跳过该分数下已返回的成员，
+// 避免使用递增的偏移量导致大集合后几批查询越来越慢
+type Iterator struct {
+	c     *Client
+	key   string
+	r     Range
+	rev   bool
+	chunk int64
+	skip  int64 // 当前端点分数下已返回的成员数
+	buf   []redis.Z
+	val   redis.Z
+	done  bool
+	err   error
+}
+
+// Iterate 按分数从小到大遍历区间r内的成员，每批最多查询chunk个
+func (c *Client) Iterate(key string, r Range, chunk int64) *Iterator {
+	return c.newIterator(key, r, chunk, false)
+}
+
+// IterateRev 按分数从大到小遍历区间r内的成员，每批最多查询chunk个
+func (c *Client) IterateRev(key string, r Range, chunk int64) *Iterator {
+	return c.newIterator(key, r, chunk, true)
+}
+
+// newIterator 创建迭代器，区间不合法时迭代器的第一次Next即返回false
+func (c *Client) newIterator(key string, r Range, chunk int64, rev bool) *Iterator {
+	it := &Iterator{c: c, key: key, r: r, rev: rev, chunk: max(chunk, 1)}
+	if _, _, err := r.scoreArgs(); err != nil {
+		it.err = err
+	}
+	return it
+}
+
+// Next 移动到下一个成员，没有更多成员或出错时返回false
+func (it *Iterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	//1.缓冲区为空时查询下一批
+	if len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		if it.err = it.fetch(ctx); it.err != nil || len(it.buf) == 0 {
+			return false
+		}
+	}
+
+	//2.取出缓冲区中的第一个成员
+	it.val, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Val 返回当前成员及其分数
+func (it *Iterator) Val() redis.Z {
+	return it.val
+}
+
+// Err 返回迭代过程中的错误
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// fetch 查询下一批成员，并将区间端点推进到本批最后一个成员的分数
+func (it *Iterator) fetch(ctx context.Context) error {
+
+	//1.按方向查询
+	var (
+		batch []redis.Z
+		err   error
+	)
+	if it.rev {
+		batch, err = it.c.ZRevRangeByScoreWithScores(ctx, it.key, it.r, it.skip, it.chunk)
+	} else {
+		batch, err = it.c.ZRangeByScoreWithScores(ctx, it.key, it.r, it.skip, it.chunk)
+	}
+	if err != nil {
+		return err
+	}
+	it.buf = batch
+	if int64(len(batch)) < it.chunk {
+		it.done = true
+		return nil
+	}
+
+	//2.统计本批末尾与最后一个成员分数相同的成员数，分数未变时需累加之前跳过的数量
+	last := batch[len(batch)-1].Score
+	same := int64(0)
+	for i := len(batch) - 1; i >= 0 && batch[i].Score == last; i-- {
+		same++
+	}
+	if same == int64(len(batch)) && it.atBound(last) {
+		same += it.skip
+	}
+
+	//3.以最后一个成员的分数作为新端点
+	if it.rev {
+		it.r.Max = Incl(last)
+	} else {
+		it.r.Min = Incl(last)
+	}
+	it.skip = same
+	return nil
+}
+
+// atBound 判断score是否等于当前推进方向上的闭区间端点
+func (it *Iterator) atBound(score float64) bool {
+	b := it.r.Min
+	if it.rev {
+		b = it.r.Max
+	}
+	return b.inf == 0 && !b.exclusive && b.score == score
+}
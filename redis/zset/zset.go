@@ -4,6 +4,9 @@ package zset
 
 import (
 	"context"
+	"strconv"
+	"time"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -65,60 +68,48 @@ func (c *Client) ZCard(ctx context.Context, key string) (int64, error) {
 	return c.rdb.ZCard(ctx, key).Result()
 }
 
-// ZRangeByScore 获取有序集合中指定分数区间的成员（从小到大排序）
-func (c *Client) ZRangeByScore(ctx context.Context, key string, min, max string, offset, count int64) ([]string, error) {
-	opt := redis.ZRangeBy{
-		Min:    min,
-		Max:    max,
-		Offset: offset,
-		Count:  count,
+// ZRangeByScore 获取有序集合中指定分数区间的成员（从小到大排序），count不大于0时不限制数量
+func (c *Client) ZRangeByScore(ctx context.Context, key string, r Range, offset, count int64) ([]string, error) {
+	opt, err := scoreRangeBy(r, offset, count)
+	if err != nil {
+		return nil, err
 	}
-	return c.rdb.ZRangeByScore(ctx, key, &opt).Result()
+	return c.rdb.ZRangeByScore(ctx, key, opt).Result()
 }
 
-// ZRangeByScoreWithScores 获取有序集合中指定分数区间的成员及其分数（从小到大排序）
-func (c *Client) ZRangeByScoreWithScores(ctx context.Context, key string, min, max string, offset, count int64) ([]redis.Z, error) {
-	opt := redis.ZRangeBy{
-		Min:    min,
-		Max:    max,
-		Offset: offset,
-		Count:  count,
-	}
-	result, err := c.rdb.ZRangeByScoreWithScores(ctx, key, &opt).Result()
+// ZRangeByScoreWithScores 获取有序集合中指定分数区间的成员及其分数（从小到大排序），count不大于0时不限制数量
+func (c *Client) ZRangeByScoreWithScores(ctx context.Context, key string, r Range, offset, count int64) ([]redis.Z, error) {
+	opt, err := scoreRangeBy(r, offset, count)
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
+	return c.rdb.ZRangeByScoreWithScores(ctx, key, opt).Result()
 }
 
-// ZRevRangeByScore 获取有序集合中指定分数区间的成员（从大到小排序）
-func (c *Client) ZRevRangeByScore(ctx context.Context, key string, max, min string, offset, count int64) ([]string, error) {
-	opt := redis.ZRangeBy{
-		Min:    min,
-		Max:    max,
-		Offset: offset,
-		Count:  count,
+// ZRevRangeByScore 获取有序集合中指定分数区间的成员（从大到小排序），count不大于0时不限制数量
+func (c *Client) ZRevRangeByScore(ctx context.Context, key string, r Range, offset, count int64) ([]string, error) {
+	opt, err := scoreRangeBy(r, offset, count)
+	if err != nil {
+		return nil, err
 	}
-	return c.rdb.ZRevRangeByScore(ctx, key, &opt).Result()
+	return c.rdb.ZRevRangeByScore(ctx, key, opt).Result()
 }
 
-// ZRevRangeByScoreWithScores 获取有序集合中指定分数区间的成员及其分数（从大到小排序）
-func (c *Client) ZRevRangeByScoreWithScores(ctx context.Context, key string, max, min string, offset, count int64) ([]redis.Z, error) {
-	opt := redis.ZRangeBy{
-		Min:    min,
-		Max:    max,
-		Offset: offset,
-		Count:  count,
-	}
-	result, err := c.rdb.ZRevRangeByScoreWithScores(ctx, key, &opt).Result()
+// ZRevRangeByScoreWithScores 获取有序集合中指定分数区间的成员及其分数（从大到小排序），count不大于0时不限制数量
+func (c *Client) ZRevRangeByScoreWithScores(ctx context.Context, key string, r Range, offset, count int64) ([]redis.Z, error) {
+	opt, err := scoreRangeBy(r, offset, count)
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
+	return c.rdb.ZRevRangeByScoreWithScores(ctx, key, opt).Result()
 }
 
 // ZCount 获取有序集合中指定分数区间的成员数量
-func (c *Client) ZCount(ctx context.Context, key, min, max string) (int64, error) {
+func (c *Client) ZCount(ctx context.Context, key string, r Range) (int64, error) {
+	min, max, err := r.scoreArgs()
+	if err != nil {
+		return 0, err
+	}
 	return c.rdb.ZCount(ctx, key, min, max).Result()
 }
 
@@ -128,7 +119,11 @@ func (c *Client) ZRemRangeByRank(ctx context.Context, key string, start, stop in
 }
 
 // ZRemRangeByScore 删除有序集合中指定分数区间的成员
-func (c *Client) ZRemRangeByScore(ctx context.Context, key, min, max string) (int64, error) {
+func (c *Client) ZRemRangeByScore(ctx context.Context, key string, r Range) (int64, error) {
+	min, max, err := r.scoreArgs()
+	if err != nil {
+		return 0, err
+	}
 	return c.rdb.ZRemRangeByScore(ctx, key, min, max).Result()
 }
 
@@ -141,3 +136,307 @@ func (c *Client) ZRank(ctx context.Context, key, member string) (int64, error) {
 func (c *Client) ZRevRank(ctx context.Context, key, member string) (int64, error) {
 	return c.rdb.ZRevRank(ctx, key, member).Result()
 }
+
+// Order 弹出元素的顺序
+type Order string
+
+const (
+	Min Order = "MIN" // 分数最小的元素
+	Max Order = "MAX" // 分数最大的元素
+)
+
+// Aggregate 多个有序集合合并时同一成员分数的聚合方式
+type Aggregate string
+
+const (
+	Sum    Aggregate = "SUM" // 求和
+	MinAgg Aggregate = "MIN" // 取最小值
+	MaxAgg Aggregate = "MAX" // 取最大值
+)
+
+// AddOptions ZADD命令的选项
+type AddOptions struct {
+	NX bool // 只添加新成员，不更新已存在的成员
+	XX bool // 只更新已存在的成员，不添加新成员
+	GT bool // 新分数大于当前分数时才更新
+	LT bool // 新分数小于当前分数时才更新
+	CH bool // 返回值包含分数被更新的成员数，而不只是新增的成员数
+}
+
+// Combine 多个有序集合的并集、交集参数
+type Combine struct {
+	Keys      []string  // 参与计算的有序集合
+	Weights   []float64 // 各有序集合分数的权重，为空时均为1
+	Aggregate Aggregate // 聚合方式，为空时为求和
+}
+
+// store 转换为go-redis的参数
+func (c Combine) store() *redis.ZStore {
+	return &redis.ZStore{Keys: c.Keys, Weights: c.Weights, Aggregate: string(c.Aggregate)}
+}
+
+// RangeBy 统一ZRANGE命令的区间类型
+type RangeBy int
+
+const (
+	ByRank  RangeBy = iota // 按排名
+	ByScore                // 按分数
+	ByLex                  // 按字典序，要求所有成员分数相同
+)
+
+// RangeArgs 统一ZRANGE命令的参数
+type RangeArgs struct {
+	Key    string  // 有序集合
+	By     RangeBy // 区间类型
+	Start  int64   // 按排名时的起始排名
+	Stop   int64   // 按排名时的结束排名
+	Range  Range   // 按分数或字典序时的区间，倒序时无需交换上下界
+	Rev    bool    // 是否从大到小排序
+	Offset int64   // 按分数或字典序时跳过的元素数
+	Count  int64   // 按分数或字典序时返回的最大元素数，不大于0时不限制
+}
+
+// args 校验区间并转换为go-redis的参数
+func (a RangeArgs) args() (redis.ZRangeArgs, error) {
+	z := redis.ZRangeArgs{Key: a.Key, Rev: a.Rev}
+	var (
+		min, max string
+		err      error
+	)
+	switch a.By {
+	case ByScore:
+		min, max, err = a.Range.scoreArgs()
+		z.ByScore = true
+	case ByLex:
+		min, max, err = a.Range.lexArgs()
+		z.ByLex = true
+	default:
+		return redis.ZRangeArgs{Key: a.Key, Start: a.Start, Stop: a.Stop, Rev: a.Rev}, nil
+	}
+	if err != nil {
+		return z, err
+	}
+	z.Start, z.Stop = min, max
+	z.Offset, z.Count = limit(a.Offset, a.Count)
+	return z, nil
+}
+
+// ZAddWithOptions 按选项添加或更新有序集合成员（NX/XX/GT/LT/CH）
+func (c *Client) ZAddWithOptions(ctx context.Context, key string, opts AddOptions, members ...redis.Z) (int64, error) {
+	return c.rdb.ZAddArgs(ctx, key, redis.ZAddArgs{
+		NX:      opts.NX,
+		XX:      opts.XX,
+		GT:      opts.GT,
+		LT:      opts.LT,
+		Ch:      opts.CH,
+		Members: members,
+	}).Result()
+}
+
+// ZScore 获取成员的分数，成员不存在时返回redis.Nil
+func (c *Client) ZScore(ctx context.Context, key, member string) (float64, error) {
+	return c.rdb.ZScore(ctx, key, member).Result()
+}
+
+// ZMScore 批量获取成员的分数（Redis 6.2+），返回的map中不包含不存在的成员
+func (c *Client) ZMScore(ctx context.Context, key string, members ...string) (map[string]float64, error) {
+
+	//1.go-redis将不存在的成员解析为0，无法与分数为0的成员区分，这里直接读取原始回复
+	args := make([]interface{}, 0, len(members)+2)
+	args = append(args, "zmscore", key)
+	for _, m := range members {
+		args = append(args, m)
+	}
+	values, err := c.rdb.Do(ctx, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	//2.解析分数，RESP2返回字符串，RESP3返回浮点数
+	scores := make(map[string]float64, len(members))
+	for i, v := range values {
+		switch s := v.(type) {
+		case string:
+			if scores[members[i]], err = strconv.ParseFloat(s, 64); err != nil {
+				return nil, err
+			}
+		case float64:
+			scores[members[i]] = s
+		}
+	}
+	return scores, nil
+}
+
+// ZRangeByLex 获取有序集合中指定字典序区间的成员，count不大于0时不限制数量
+func (c *Client) ZRangeByLex(ctx context.Context, key string, r Range, offset, count int64) ([]string, error) {
+	opt, err := lexRangeBy(r, offset, count)
+	if err != nil {
+		return nil, err
+	}
+	return c.rdb.ZRangeByLex(ctx, key, opt).Result()
+}
+
+// ZRevRangeByLex 获取有序集合中指定字典序区间的成员（从大到小排序），count不大于0时不限制数量
+func (c *Client) ZRevRangeByLex(ctx context.Context, key string, r Range, offset, count int64) ([]string, error) {
+	opt, err := lexRangeBy(r, offset, count)
+	if err != nil {
+		return nil, err
+	}
+	return c.rdb.ZRevRangeByLex(ctx, key, opt).Result()
+}
+
+// ZLexCount 获取有序集合中指定字典序区间的成员数量
+func (c *Client) ZLexCount(ctx context.Context, key string, r Range) (int64, error) {
+	min, max, err := r.lexArgs()
+	if err != nil {
+		return 0, err
+	}
+	return c.rdb.ZLexCount(ctx, key, min, max).Result()
+}
+
+// ZRemRangeByLex 删除有序集合中指定字典序区间的成员
+func (c *Client) ZRemRangeByLex(ctx context.Context, key string, r Range) (int64, error) {
+	min, max, err := r.lexArgs()
+	if err != nil {
+		return 0, err
+	}
+	return c.rdb.ZRemRangeByLex(ctx, key, min, max).Result()
+}
+
+// ZPopMin 弹出分数最小的count个成员
+func (c *Client) ZPopMin(ctx context.Context, key string, count int64) ([]redis.Z, error) {
+	return c.rdb.ZPopMin(ctx, key, count).Result()
+}
+
+// ZPopMax 弹出分数最大的count个成员
+func (c *Client) ZPopMax(ctx context.Context, key string, count int64) ([]redis.Z, error) {
+	return c.rdb.ZPopMax(ctx, key, count).Result()
+}
+
+// BZPopMin 阻塞式弹出第一个非空有序集合中分数最小的成员，超时返回redis.Nil
+func (c *Client) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) (*redis.ZWithKey, error) {
+	return c.rdb.BZPopMin(ctx, timeout, keys...).Result()
+}
+
+// BZPopMax 阻塞式弹出第一个非空有序集合中分数最大的成员，超时返回redis.Nil
+func (c *Client) BZPopMax(ctx context.Context, timeout time.Duration, keys ...string) (*redis.ZWithKey, error) {
+	return c.rdb.BZPopMax(ctx, timeout, keys...).Result()
+}
+
+// ZMPop 从第一个非空有序集合中按order弹出最多count个成员（Redis 7.0+）
+// 返回:
+//   - 弹出成员所在的key
+//   - 弹出的成员
+//   - 错误信息，所有有序集合均为空时返回redis.Nil
+func (c *Client) ZMPop(ctx context.Context, order Order, count int64, keys ...string) (string, []redis.Z, error) {
+	return c.rdb.ZMPop(ctx, string(order), count, keys...).Result()
+}
+
+// BZMPop 阻塞式ZMPop（Redis 7.0+）
+func (c *Client) BZMPop(ctx context.Context, timeout time.Duration, order Order, count int64, keys ...string) (string, []redis.Z, error) {
+	return c.rdb.BZMPop(ctx, timeout, string(order), count, keys...).Result()
+}
+
+// ZRandMember 随机返回count个成员，count为正数时成员不重复，为负数时可能重复
+func (c *Client) ZRandMember(ctx context.Context, key string, count int) ([]string, error) {
+	return c.rdb.ZRandMember(ctx, key, count).Result()
+}
+
+// ZRandMemberWithScores 随机返回count个成员及其分数，count的含义同ZRandMember
+func (c *Client) ZRandMemberWithScores(ctx context.Context, key string, count int) ([]redis.Z, error) {
+	return c.rdb.ZRandMemberWithScores(ctx, key, count).Result()
+}
+
+// ZUnion 计算多个有序集合的并集（Redis 6.2+）
+func (c *Client) ZUnion(ctx context.Context, combine Combine) ([]redis.Z, error) {
+	return c.rdb.ZUnionWithScores(ctx, *combine.store()).Result()
+}
+
+// ZUnionStore 计算多个有序集合的并集并保存到dest，返回结果的成员数
+func (c *Client) ZUnionStore(ctx context.Context, dest string, combine Combine) (int64, error) {
+	return c.rdb.ZUnionStore(ctx, dest, combine.store()).Result()
+}
+
+// ZInter 计算多个有序集合的交集（Redis 6.2+）
+func (c *Client) ZInter(ctx context.Context, combine Combine) ([]redis.Z, error) {
+	return c.rdb.ZInterWithScores(ctx, combine.store()).Result()
+}
+
+// ZInterStore 计算多个有序集合的交集并保存到dest，返回结果的成员数
+func (c *Client) ZInterStore(ctx context.Context, dest string, combine Combine) (int64, error) {
+	return c.rdb.ZInterStore(ctx, dest, combine.store()).Result()
+}
+
+// ZInterCard 计算多个有序集合交集的成员数（Redis 7.0+），limit大于0时数到limit即停止
+func (c *Client) ZInterCard(ctx context.Context, limit int64, keys ...string) (int64, error) {
+	return c.rdb.ZInterCard(ctx, limit, keys...).Result()
+}
+
+// ZDiff 计算第一个有序集合与其余有序集合的差集（Redis 6.2+）
+func (c *Client) ZDiff(ctx context.Context, keys ...string) ([]redis.Z, error) {
+	return c.rdb.ZDiffWithScores(ctx, keys...).Result()
+}
+
+// ZDiffStore 计算差集并保存到dest，返回结果的成员数
+func (c *Client) ZDiffStore(ctx context.Context, dest string, keys ...string) (int64, error) {
+	return c.rdb.ZDiffStore(ctx, dest, keys...).Result()
+}
+
+// ZRangeArgs 统一的ZRANGE命令（Redis 6.2+），支持按排名、分数、字典序查询及倒序与分页
+func (c *Client) ZRangeArgs(ctx context.Context, args RangeArgs) ([]string, error) {
+	z, err := args.args()
+	if err != nil {
+		return nil, err
+	}
+	return c.rdb.ZRangeArgs(ctx, z).Result()
+}
+
+// ZRangeArgsWithScores 统一的ZRANGE命令，同时返回分数，不支持按字典序查询
+func (c *Client) ZRangeArgsWithScores(ctx context.Context, args RangeArgs) ([]redis.Z, error) {
+	z, err := args.args()
+	if err != nil {
+		return nil, err
+	}
+	return c.rdb.ZRangeArgsWithScores(ctx, z).Result()
+}
+
+// ZRangeStore 将统一ZRANGE命令的结果保存到dest（Redis 6.2+），返回结果的成员数
+func (c *Client) ZRangeStore(ctx context.Context, dest string, args RangeArgs) (int64, error) {
+	z, err := args.args()
+	if err != nil {
+		return 0, err
+	}
+	return c.rdb.ZRangeStore(ctx, dest, z).Result()
+}
+
+// scoreRangeBy 校验分数区间并转换为go-redis的参数
+func scoreRangeBy(r Range, offset, count int64) (*redis.ZRangeBy, error) {
+	min, max, err := r.scoreArgs()
+	if err != nil {
+		return nil, err
+	}
+	offset, count = limit(offset, count)
+	return &redis.ZRangeBy{Min: min, Max: max, Offset: offset, Count: count}, nil
+}
+
+// lexRangeBy 校验字典序区间并转换为go-redis的参数
+func lexRangeBy(r Range, offset, count int64) (*redis.ZRangeBy, error) {
+	min, max, err := r.lexArgs()
+	if err != nil {
+		return nil, err
+	}
+	offset, count = limit(offset, count)
+	return &redis.ZRangeBy{Min: min, Max: max, Offset: offset, Count: count}, nil
+}
+
+// limit 返回LIMIT的偏移量与数量，count不大于0表示不限制数量
+// go-redis在offset或count不为0时发送LIMIT offset count，而LIMIT offset 0会返回空结果，因此只有偏移量时数量取-1
+func limit(offset, count int64) (int64, int64) {
+	if count > 0 {
+		return offset, count
+	}
+	if offset > 0 {
+		return offset, -1
+	}
+	return 0, 0
+}